@@ -1,6 +1,11 @@
 package main
 
 import (
+	"os"
+	"time"
+
+	"github.com/davidvader/skelly/db"
+	replaypkg "github.com/davidvader/skelly/replay"
 	"github.com/davidvader/skelly/router"
 	"github.com/davidvader/skelly/skelly"
 	"github.com/davidvader/skelly/util"
@@ -27,6 +32,136 @@ var (
 		},
 	}
 
+	// replayCmd defines the command for replaying captured slack payloads.
+	replayCmd = &cli.Command{
+		Name:        "replay",
+		Category:    "Debug",
+		Description: "Use this command to sign and submit a captured slash command, event or interaction payload to skelly.",
+		Usage:       "Replay a captured Slack payload, printing the resulting Slack API calls",
+		Before:      validateReplay,
+		Action:      replay,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "file",
+				Aliases: []string{"f"},
+				Usage:   "path to the captured payload (json or form encoded), use - for stdin",
+				Value:   "",
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "payload type - options: (command|event|interaction), detected when empty",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "server",
+				Usage: "address of a running skelly server, ex: http://localhost:8080, handled in-process when empty",
+				Value: "",
+			},
+			&cli.StringFlag{
+				EnvVars: []string{"SKELLY_SIGNING_SECRET"},
+				Name:    "secret",
+				Usage:   "signing secret used to sign the payload",
+				Value:   "",
+			},
+			&cli.StringFlag{
+				Name:  "db",
+				Usage: "mongo db written to when replaying in-process, must differ from SKELLY_MONGO_DB",
+				Value: "",
+			},
+			&cli.StringFlag{
+				Name:  "fixtures",
+				Usage: "yaml file of reactions added to the db before replaying in-process, in the format of skelly reaction view",
+				Value: "",
+			},
+			&cli.DurationFlag{
+				Name:  "wait",
+				Usage: "maximum time to wait for asynchronous slack api calls when replaying in-process",
+				Value: 3 * time.Second,
+			},
+		},
+	}
+
+	// migrateCmd defines the command for backfilling the team of documents created before multi-workspace support.
+	migrateCmd = &cli.Command{
+		Name:        "migrate",
+		Category:    "Server",
+		Description: "Use this command to assign reactions, trash and announcements created before multi-workspace support to a team.",
+		Usage:       "Backfill the team of reactions created before multi-workspace support",
+		Action:      migrate,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				EnvVars: []string{"SKELLY_DEFAULT_TEAM"},
+				Name:    "team",
+				Usage:   "which team (workspace) the existing reactions belong to",
+				Value:   "",
+			},
+		},
+	}
+
+	// statsCmd defines the command for showing how reactions fired.
+	statsCmd = &cli.Command{
+		Name:        "stats",
+		Category:    "Stats",
+		Description: "Use this command to show how often reactions fired, the users they reached and why they were skipped.",
+		Usage:       "Show reaction activity for a specified channel",
+		Before:      validateSkellyStats,
+		Action:      stats,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				EnvVars: []string{"SKELLY_CHANNEL"},
+				Name:    "channel",
+				Aliases: []string{"c"},
+				Usage:   "for which channel to show stats, shows every channel when empty",
+				Value:   "",
+			},
+			&cli.StringFlag{
+				EnvVars: []string{"SKELLY_TEAM"},
+				Name:    "team",
+				Usage:   "which team (workspace) the channel belongs to",
+				Value:   "",
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "how far back to show stats, ex: 12h, 7d, 4w",
+				Value: "30d",
+			},
+		},
+	}
+
+	// auditCmd defines the command for showing who changed reactions.
+	auditCmd = &cli.Command{
+		Name:        "audit",
+		Category:    "Audit",
+		Description: "Use this command to show who added, updated, deleted or cleared reactions, when and from where.",
+		Usage:       "Show the changes made to reactions for a specified channel",
+		Action:      audit,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				EnvVars: []string{"SKELLY_CHANNEL"},
+				Name:    "channel",
+				Aliases: []string{"c"},
+				Usage:   "for which channel to show changes, shows every channel when empty",
+				Value:   "",
+			},
+			&cli.StringFlag{
+				EnvVars: []string{"SKELLY_TEAM"},
+				Name:    "team",
+				Usage:   "which team (workspace) the channel belongs to, shows every team when empty",
+				Value:   "",
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "how far back to show changes, ex: 12h, 7d, 4w",
+				Value: "30d",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "the most changes to show, 0 for all of them",
+				Value: 50,
+			},
+		},
+	}
+
 	// reactionCmds defines the main command for controlling reactions.
 	// trigger defines the command for simulating a skelly a slack reaction.
 	reactionCmds = []*cli.Command{
@@ -53,6 +188,17 @@ var (
 							Usage:   "for which channel to retrieve a reaction",
 							Value:   "",
 						},
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_TEAM"},
+							Name:    "team",
+							Usage:   "which team (workspace) the channel belongs to",
+							Value:   "",
+						},
+						&cli.StringFlag{
+							Name:  "id",
+							Usage: "which reaction to view, required when the channel has several",
+							Value: "",
+						},
 					},
 				},
 				{
@@ -71,6 +217,12 @@ var (
 							Usage:   "for which channel to retrieve reactions",
 							Value:   "",
 						},
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_TEAM"},
+							Name:    "team",
+							Usage:   "which team (workspace) the channel belongs to",
+							Value:   "",
+						},
 					},
 				},
 				{
@@ -89,6 +241,12 @@ var (
 							Usage:   "for which channel to clear reactions",
 							Value:   "",
 						},
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_TEAM"},
+							Name:    "team",
+							Usage:   "which team (workspace) the channel belongs to",
+							Value:   "",
+						},
 					},
 				},
 				{
@@ -99,7 +257,7 @@ var (
 					Usage:       "Add a reaction for a specified channel",
 					Before:      validateAdd,
 					Action:      add,
-					Flags: []cli.Flag{
+					Flags: append([]cli.Flag{
 						&cli.StringFlag{
 							EnvVars: []string{"SKELLY_CHANNEL"},
 							Name:    "channel",
@@ -107,13 +265,28 @@ var (
 							Usage:   "for which channel to add",
 							Value:   "",
 						},
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_TEAM"},
+							Name:    "team",
+							Usage:   "which team (workspace) the channel belongs to, required",
+							Value:   "",
+						},
 						&cli.StringFlag{
 							Name:    "response",
 							Aliases: []string{"r"},
 							Usage:   "what message to respond with",
 							Value:   "",
 						},
-					},
+						&cli.StringFlag{
+							Name:  "cooldown",
+							Usage: "how long to wait before responding to the same user again, ex: 30m, 12h, 1d",
+							Value: "",
+						},
+						&cli.StringSliceFlag{
+							Name:  "users",
+							Usage: "which user ids to respond to, responds to all users when empty",
+						},
+					}, append(append(append(scheduleFlags, variantFlags...), triggerFlags...), faqFlags...)...),
 				},
 				{
 					Name:        "update",
@@ -123,7 +296,7 @@ var (
 					Usage:       "Update a reaction for a specified channel",
 					Before:      validateUpdate,
 					Action:      update,
-					Flags: []cli.Flag{
+					Flags: append([]cli.Flag{
 						&cli.StringFlag{
 							EnvVars: []string{"SKELLY_CHANNEL"},
 							Name:    "channel",
@@ -131,12 +304,88 @@ var (
 							Usage:   "for which channel to update",
 							Value:   "",
 						},
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_TEAM"},
+							Name:    "team",
+							Usage:   "which team (workspace) the channel belongs to, required",
+							Value:   "",
+						},
+						&cli.StringFlag{
+							Name:  "id",
+							Usage: "which reaction to update, required when the channel has several",
+							Value: "",
+						},
 						&cli.StringFlag{
 							Name:    "response",
 							Aliases: []string{"r"},
 							Usage:   "what message to respond with",
 							Value:   "",
 						},
+						&cli.StringFlag{
+							Name:  "cooldown",
+							Usage: "how long to wait before responding to the same user again, ex: 30m, 12h, 1d",
+							Value: "",
+						},
+						&cli.StringSliceFlag{
+							Name:  "users",
+							Usage: "which user ids to respond to, responds to all users when empty",
+						},
+					}, append(append(append(scheduleFlags, variantFlags...), triggerFlags...), faqFlags...)...),
+				},
+				{
+					Name:        "pause",
+					Category:    "Reaction",
+					Description: "Use this command to pause the reaction for a specified channel.",
+					Usage:       "Pause the reaction for a specified channel",
+					Before:      validatePause,
+					Action:      pause,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_CHANNEL"},
+							Name:    "channel",
+							Aliases: []string{"c"},
+							Usage:   "for which channel to pause",
+							Value:   "",
+						},
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_TEAM"},
+							Name:    "team",
+							Usage:   "which team (workspace) the channel belongs to",
+							Value:   "",
+						},
+						&cli.StringFlag{
+							Name:  "id",
+							Usage: "which reaction to pause, required when the channel has several",
+							Value: "",
+						},
+					},
+				},
+				{
+					Name:        "resume",
+					Category:    "Reaction",
+					Description: "Use this command to resume the reaction for a specified channel.",
+					Usage:       "Resume the reaction for a specified channel",
+					Before:      validateResume,
+					Action:      resume,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_CHANNEL"},
+							Name:    "channel",
+							Aliases: []string{"c"},
+							Usage:   "for which channel to resume",
+							Value:   "",
+						},
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_TEAM"},
+							Name:    "team",
+							Usage:   "which team (workspace) the channel belongs to",
+							Value:   "",
+						},
+						&cli.StringFlag{
+							Name:  "id",
+							Usage: "which reaction to resume, required when the channel has several",
+							Value: "",
+						},
 					},
 				},
 				{
@@ -155,6 +404,45 @@ var (
 							Usage:   "for which channel to delete",
 							Value:   "",
 						},
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_TEAM"},
+							Name:    "team",
+							Usage:   "which team (workspace) the channel belongs to",
+							Value:   "",
+						},
+						&cli.StringFlag{
+							Name:  "id",
+							Usage: "which reaction to delete, deletes every reaction for the channel when empty",
+							Value: "",
+						},
+					},
+				},
+				{
+					Name:        "restore",
+					Category:    "Reaction",
+					Description: "Use this command to restore deleted reactions for a specified channel.",
+					Usage:       "Restore deleted reactions for a specified channel",
+					Before:      validateRestore,
+					Action:      restore,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_CHANNEL"},
+							Name:    "channel",
+							Aliases: []string{"c"},
+							Usage:   "for which channel to restore",
+							Value:   "",
+						},
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_TEAM"},
+							Name:    "team",
+							Usage:   "which team (workspace) the channel belongs to",
+							Value:   "",
+						},
+						&cli.StringFlag{
+							Name:  "id",
+							Usage: "which trash id to restore, defaults to the most recent delete",
+							Value: "",
+						},
 					},
 				},
 				{
@@ -173,6 +461,12 @@ var (
 							Usage:   "which channel to trigger a reaction in",
 							Value:   "",
 						},
+						&cli.StringFlag{
+							EnvVars: []string{"SKELLY_TEAM"},
+							Name:    "team",
+							Usage:   "which team (workspace) the channel belongs to",
+							Value:   "",
+						},
 						&cli.StringFlag{
 							EnvVars: []string{"SKELLY_USER"},
 							Name:    "user",
@@ -186,6 +480,16 @@ var (
 							Usage:   "which message timestamp to trigger a reaction on",
 							Value:   "none",
 						},
+						&cli.StringFlag{
+							Name:  "trigger",
+							Usage: "what to simulate - options: (message|join|mention|emoji:<name>)",
+							Value: "message",
+						},
+						&cli.StringFlag{
+							Name:  "text",
+							Usage: "the text of the simulated message, for matching faq reactions",
+							Value: "",
+						},
 					},
 				},
 			},
@@ -193,8 +497,204 @@ var (
 	}
 )
 
+// announcementCmds defines the main command for controlling scheduled announcements.
+var announcementCmds = []*cli.Command{
+	{
+		Name:        "announcement",
+		Category:    "Announcement",
+		Aliases:     []string{"an"},
+		Description: "Use this command to control scheduled announcements for a specified channel.",
+		Usage:       "Controls scheduled announcements for a specified channel",
+		Subcommands: []*cli.Command{
+			{
+				Name:        "add",
+				Category:    "Announcement",
+				Aliases:     []string{"a"},
+				Description: "Use this command to post a message to a specified channel on a cron schedule.",
+				Usage:       "Add a scheduled announcement for a specified channel",
+				Before:      validateAnnounce,
+				Action:      announce,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						EnvVars: []string{"SKELLY_CHANNEL"},
+						Name:    "channel",
+						Aliases: []string{"c"},
+						Usage:   "for which channel to add",
+						Value:   "",
+					},
+					&cli.StringFlag{
+						EnvVars: []string{"SKELLY_TEAM"},
+						Name:    "team",
+						Usage:   "which team (workspace) the channel belongs to",
+						Value:   "",
+					},
+					&cli.StringFlag{
+						Name:    "response",
+						Aliases: []string{"r"},
+						Usage:   "what message to post",
+						Value:   "",
+					},
+					&cli.StringFlag{
+						Name:  "cron",
+						Usage: "when to post, ex: \"0 9 * * mon-fri\" or @daily",
+						Value: "",
+					},
+					&cli.StringFlag{
+						Name:  "tz",
+						Usage: "which time zone the cron is in, defaults to UTC, ex: America/Chicago",
+						Value: "",
+					},
+				},
+			},
+			{
+				Name:        "list",
+				Category:    "Announcement",
+				Aliases:     []string{"l"},
+				Description: "Use this command to list scheduled announcements for a specified channel.",
+				Usage:       "List scheduled announcements for a specified channel",
+				Action:      announcements,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						EnvVars: []string{"SKELLY_CHANNEL"},
+						Name:    "channel",
+						Aliases: []string{"c"},
+						Usage:   "for which channel to list, lists every channel when empty",
+						Value:   "",
+					},
+					&cli.StringFlag{
+						EnvVars: []string{"SKELLY_TEAM"},
+						Name:    "team",
+						Usage:   "which team (workspace) the channel belongs to",
+						Value:   "",
+					},
+				},
+			},
+			{
+				Name:        "delete",
+				Category:    "Announcement",
+				Aliases:     []string{"d"},
+				Description: "Use this command to delete a scheduled announcement.",
+				Usage:       "Delete a scheduled announcement",
+				Before:      validateDeleteAnnouncement,
+				Action:      deleteAnnouncement,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "id",
+						Usage: "which announcement to delete",
+						Value: "",
+					},
+					&cli.StringFlag{
+						EnvVars: []string{"SKELLY_TEAM"},
+						Name:    "team",
+						Usage:   "which team (workspace) the announcement belongs to",
+						Value:   "",
+					},
+				},
+			},
+		},
+	},
+}
+
+// scheduleFlags are the flags for limiting when a reaction is active
+var scheduleFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "days",
+		Usage: "which days of the week the reaction is active, ex: mon-fri, weekends, mon,wed,fri",
+		Value: "",
+	},
+	&cli.StringFlag{
+		Name:  "hours",
+		Usage: "which hours of the day the reaction is active, ex: 09:00-17:00",
+		Value: "",
+	},
+	&cli.StringFlag{
+		Name:  "tz",
+		Usage: "which time zone the days and hours are in, defaults to UTC, ex: America/Chicago",
+		Value: "",
+	},
+	&cli.StringFlag{
+		Name:  "from",
+		Usage: "the first date the reaction is active, ex: 2021-01-04",
+		Value: "",
+	},
+	&cli.StringFlag{
+		Name:  "until",
+		Usage: "the last date the reaction is active, ex: 2021-01-08",
+		Value: "",
+	},
+	&cli.BoolFlag{
+		Name:  "always",
+		Usage: "remove the existing schedule before applying the other schedule flags",
+	},
+}
+
+// variantFlags are the flags for responding with one of several responses
+var variantFlags = []cli.Flag{
+	&cli.StringSliceFlag{
+		Name:  "variant",
+		Usage: "other responses to respond with instead of the main response, replaces existing variants",
+	},
+	&cli.StringFlag{
+		Name:  "weights",
+		Usage: "comma separated weights for the main response followed by each variant, ex: 3,1,1",
+		Value: "",
+	},
+	&cli.StringFlag{
+		Name:  "strategy",
+		Usage: "how to select a response - options: (random|weighted|round-robin|sequential)",
+		Value: "",
+	},
+	&cli.StringSliceFlag{
+		Name:  "locale",
+		Usage: "responses for users with a specific slack locale, ex: es=¡Hola!",
+	},
+	&cli.BoolFlag{
+		Name:  "no-variants",
+		Usage: "remove the existing variants, weights, strategy and locales before applying the other variant flags",
+	},
+}
+
+// triggerFlags are the flags for what a reaction responds to and where
+var triggerFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "trigger",
+		Usage: "what the reaction responds to - options: (message|typing|join|mention|emoji)",
+		Value: "",
+	},
+	&cli.StringFlag{
+		Name:  "emoji",
+		Usage: "which emoji added to a message triggers the reaction, ex: ticket",
+		Value: "",
+	},
+	&cli.BoolFlag{
+		Name:  "dm",
+		Usage: "respond to the user in a direct message instead of the channel",
+	},
+	&cli.BoolFlag{
+		Name:  "no-dm",
+		Usage: "respond in the channel instead of a direct message",
+	},
+}
+
+// faqFlags are the flags for answering questions
+var faqFlags = []cli.Flag{
+	&cli.StringSliceFlag{
+		Name:  "faq",
+		Usage: "phrasings of a question the response answers, makes the reaction an faq and replaces existing questions",
+	},
+	&cli.StringFlag{
+		Name:  "threshold",
+		Usage: "how similar a message must be to a question to be answered, from 0 to 1, defaults to 0.5",
+		Value: "",
+	},
+	&cli.BoolFlag{
+		Name:  "no-faq",
+		Usage: "turn an faq reaction back into a typing reaction before applying the other faq flags",
+	},
+}
+
 func cmds() []*cli.Command {
-	return append(reactionCmds, serverCmd)
+	return append(append(reactionCmds, announcementCmds...), serverCmd, migrateCmd, replayCmd, statsCmd, auditCmd)
 }
 
 // validateView is a helper function to load global configuration if set
@@ -240,6 +740,9 @@ func validateAdd(c *cli.Context) error {
 	if len(c.String("channel")) == 0 {
 		return util.InvalidCommand("channel")
 	}
+	if len(c.String("team")) == 0 {
+		return util.InvalidCommand("team")
+	}
 	if len(c.String("response")) == 0 {
 		return util.InvalidCommand("response")
 	}
@@ -255,7 +758,10 @@ func validateUpdate(c *cli.Context) error {
 	if len(c.String("channel")) == 0 {
 		return util.InvalidCommand("channel")
 	}
-	if len(c.String("response")) == 0 {
+	if len(c.String("team")) == 0 {
+		return util.InvalidCommand("team")
+	}
+	if len(c.String("response")) == 0 && len(c.String("cooldown")) == 0 && len(c.StringSlice("users")) == 0 && scheduleOptions(c).Empty() && variantOptions(c).Empty() && triggerOptions(c).Empty() && faqOptions(c).Empty() {
 		return util.InvalidCommand("response")
 	}
 
@@ -274,6 +780,72 @@ func validateDelete(c *cli.Context) error {
 	return nil
 }
 
+// validateRestore is a helper function to load global configuration if set
+// via config or environment and validate the user input in the command
+func validateRestore(c *cli.Context) error {
+
+	// validate the user input in the command
+	if len(c.String("channel")) == 0 {
+		return util.InvalidCommand("channel")
+	}
+
+	return nil
+}
+
+// validatePause is a helper function to load global configuration if set
+// via config or environment and validate the user input in the command
+func validatePause(c *cli.Context) error {
+
+	// validate the user input in the command
+	if len(c.String("channel")) == 0 {
+		return util.InvalidCommand("channel")
+	}
+
+	return nil
+}
+
+// validateResume is a helper function to load global configuration if set
+// via config or environment and validate the user input in the command
+func validateResume(c *cli.Context) error {
+
+	// validate the user input in the command
+	if len(c.String("channel")) == 0 {
+		return util.InvalidCommand("channel")
+	}
+
+	return nil
+}
+
+// validateAnnounce is a helper function to load global configuration if set
+// via config or environment and validate the user input in the command
+func validateAnnounce(c *cli.Context) error {
+
+	// validate the user input in the command
+	if len(c.String("channel")) == 0 {
+		return util.InvalidCommand("channel")
+	}
+	if len(c.String("response")) == 0 {
+		return util.InvalidCommand("response")
+	}
+	if len(c.String("cron")) == 0 {
+		return util.InvalidCommand("cron")
+	}
+
+	return nil
+}
+
+// validateDeleteAnnouncement is a helper function to load global configuration if set
+// via config or environment and validate the user input in the command
+func validateDeleteAnnouncement(c *cli.Context) error {
+
+	// validate the user input in the command
+	if len(c.String("id")) == 0 {
+		return util.InvalidCommand("id")
+	}
+
+	return nil
+}
+
 // validateTrigger is a helper function to load global configuration if set
 // via config or environment and validate the user input in the command
 func validateTrigger(c *cli.Context) error {
@@ -286,49 +858,188 @@ func validateTrigger(c *cli.Context) error {
 	return nil
 }
 
+// validateReplay is a helper function to load global configuration if set
+// via config or environment and validate the user input in the command
+func validateReplay(c *cli.Context) error {
+
+	// validate the user input in the command
+	if len(c.String("file")) == 0 {
+		return util.InvalidCommand("file")
+	}
+
+	switch c.String("type") {
+	case "", replaypkg.CommandPayload, replaypkg.EventPayload, replaypkg.InteractionPayload:
+	default:
+		return util.InvalidFlagValue(c.String("type"), "type")
+	}
+
+	// in-process replays write to the mongo db
+	if len(c.String("server")) == 0 && len(c.String("db")) == 0 {
+		return util.InvalidCommand("db")
+	}
+
+	return nil
+}
+
 // validateSkellyStats is a helper function to load global configuration if set
 // via config or environment and validate the user input in the command
 func validateSkellyStats(c *cli.Context) error {
 
+	// validate the user input in the command
+	if len(c.String("since")) > 0 {
+		_, err := skelly.ParseStatsWindow(c.String("since"))
+		if err != nil {
+			return util.InvalidFlagValue(c.String("since"), "since")
+		}
+	}
+
 	return nil
 }
 
 // server is a wrapper around running router.Run via the CLI
+// reactions without a team are backfilled first when SKELLY_DEFAULT_TEAM is set
 func server(c *cli.Context) error {
+
+	if team := os.Getenv("SKELLY_DEFAULT_TEAM"); len(team) > 0 {
+
+		err := skelly.Migrate(c.Context, team)
+		if err != nil {
+			return err
+		}
+	}
+
+	// expire old activity
+	err := db.EnsureActivityIndex(c.Context)
+	if err != nil {
+		return err
+	}
+
 	return router.Run(c.String("port"))
 }
 
+// migrate is a wrapper around running skelly.Migrate via the CLI
+func migrate(c *cli.Context) error {
+	return skelly.Migrate(c.Context, c.String("team"))
+}
+
 // view is a wrapper around running skelly.View via the CLI
 func view(c *cli.Context) error {
-	return skelly.View(c.String("channel"))
+	return skelly.View(c.Context, c.String("team"), c.String("channel"), c.String("id"))
 }
 
 // list is a wrapper around running skelly.List via the CLI
 func list(c *cli.Context) error {
-	return skelly.List(c.String("token"), c.String("channel"))
+	return skelly.List(c.Context, c.String("token"), c.String("team"), c.String("channel"))
 }
 
 // clear is a wrapper around running skelly.List via the CLI
 func clear(c *cli.Context) error {
-	return skelly.Clear(c.String("channel"))
+	return skelly.Clear(c.Context, c.String("team"), c.String("channel"))
 }
 
 // add is a wrapper around running skelly.Add via the CLI
 func add(c *cli.Context) error {
-	return skelly.Add(c.String("token"), c.String("channel"), c.String("response"))
+	return skelly.Add(c.Context, c.String("token"), c.String("team"), c.String("channel"), c.String("response"), c.String("cooldown"), c.StringSlice("users"), scheduleOptions(c), variantOptions(c), triggerOptions(c), faqOptions(c))
 }
 
 // update is a wrapper around running skelly.Update via the CLI
 func update(c *cli.Context) error {
-	return skelly.Update(c.String("token"), c.String("channel"), c.String("response"))
+	return skelly.Update(c.Context, c.String("token"), c.String("team"), c.String("channel"), c.String("id"), c.String("response"), c.String("cooldown"), c.StringSlice("users"), scheduleOptions(c), variantOptions(c), triggerOptions(c), faqOptions(c))
 }
 
 // delete is a wrapper around running skelly.Delete via the CLI
 func delete(c *cli.Context) error {
-	return skelly.Delete(c.String("token"), c.String("channel"))
+	return skelly.Delete(c.Context, c.String("token"), c.String("team"), c.String("channel"), c.String("id"))
+}
+
+// restore is a wrapper around running skelly.Restore via the CLI
+func restore(c *cli.Context) error {
+	return skelly.Restore(c.Context, c.String("team"), c.String("channel"), c.String("id"))
+}
+
+// pause is a wrapper around running skelly.Pause via the CLI
+func pause(c *cli.Context) error {
+	return skelly.Pause(c.Context, c.String("team"), c.String("channel"), c.String("id"))
+}
+
+// resume is a wrapper around running skelly.Resume via the CLI
+func resume(c *cli.Context) error {
+	return skelly.Resume(c.Context, c.String("team"), c.String("channel"), c.String("id"))
+}
+
+// scheduleOptions is a helper function to read the schedule flags in the command
+func scheduleOptions(c *cli.Context) *skelly.ScheduleOptions {
+	return &skelly.ScheduleOptions{
+		Days:     c.String("days"),
+		Hours:    c.String("hours"),
+		TimeZone: c.String("tz"),
+		From:     c.String("from"),
+		Until:    c.String("until"),
+		Always:   c.Bool("always"),
+	}
+}
+
+// announce is a wrapper around running skelly.Announce via the CLI
+func announce(c *cli.Context) error {
+	return skelly.Announce(c.Context, c.String("team"), c.String("channel"), c.String("response"), c.String("cron"), c.String("tz"))
+}
+
+// announcements is a wrapper around running skelly.Announcements via the CLI
+func announcements(c *cli.Context) error {
+	return skelly.Announcements(c.Context, c.String("team"), c.String("channel"))
+}
+
+// deleteAnnouncement is a wrapper around running skelly.DeleteAnnouncement via the CLI
+func deleteAnnouncement(c *cli.Context) error {
+	return skelly.DeleteAnnouncement(c.Context, c.String("team"), c.String("id"))
+}
+
+// variantOptions is a helper function to read the variant flags in the command
+func variantOptions(c *cli.Context) *skelly.VariantOptions {
+	return &skelly.VariantOptions{
+		Variants: c.StringSlice("variant"),
+		Weights:  c.String("weights"),
+		Strategy: c.String("strategy"),
+		Locales:  c.StringSlice("locale"),
+		Clear:    c.Bool("no-variants"),
+	}
+}
+
+// triggerOptions is a helper function to read the trigger flags in the command
+func triggerOptions(c *cli.Context) *skelly.TriggerOptions {
+	return &skelly.TriggerOptions{
+		Trigger:        c.String("trigger"),
+		Emoji:          c.String("emoji"),
+		DirectMessage:  c.Bool("dm"),
+		ChannelMessage: c.Bool("no-dm"),
+	}
+}
+
+// faqOptions is a helper function to read the faq flags in the command
+func faqOptions(c *cli.Context) *skelly.FAQOptions {
+	return &skelly.FAQOptions{
+		Questions: c.StringSlice("faq"),
+		Threshold: c.String("threshold"),
+		Clear:     c.Bool("no-faq"),
+	}
 }
 
 // trigger is a wrapper around running skelly.Trigger via the CLI
 func trigger(c *cli.Context) error {
-	return skelly.Trigger(c.String("token"), c.String("channel"), c.String("user"), c.String("ts"))
+	return skelly.Trigger(c.Context, c.String("token"), c.String("team"), c.String("channel"), c.String("user"), c.String("ts"), c.String("trigger"), c.String("text"))
+}
+
+// replay is a wrapper around running replay.Replay via the CLI
+func replay(c *cli.Context) error {
+	return replaypkg.Replay(c.Context, c.String("file"), c.String("type"), c.String("server"), c.String("secret"), c.String("db"), c.String("fixtures"), c.Duration("wait"))
+}
+
+// stats is a wrapper around running skelly.Stats via the CLI
+func stats(c *cli.Context) error {
+	return skelly.Stats(c.Context, c.String("team"), c.String("channel"), c.String("since"))
+}
+
+// audit is a wrapper around running skelly.Audit via the CLI
+func audit(c *cli.Context) error {
+	return skelly.Audit(c.Context, c.String("team"), c.String("channel"), c.String("since"), c.Int("limit"))
 }
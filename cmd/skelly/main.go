@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/util"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
@@ -46,6 +49,12 @@ func main() {
 			Usage:   "set log level - options: (trace|debug|info|warn|error|fatal|panic)",
 			Value:   "info",
 		},
+		&cli.StringFlag{
+			EnvVars: []string{"LOG_FORMAT"},
+			Name:    "log-format",
+			Usage:   "set log format - options: (text|json)",
+			Value:   "text",
+		},
 		&cli.StringFlag{
 			EnvVars: []string{"SKELLY_BOT_TOKEN"},
 			Name:    "token",
@@ -71,8 +80,20 @@ func main() {
 		panic(err)
 	}
 
+	// configure tracing
+	shutdown, err := tracing.Setup(context.Background())
+	if err != nil {
+		panic(err)
+	}
+
 	// Run App
 	err = app.Run(os.Args)
+
+	// flush any remaining spans
+	if serr := shutdown(context.Background()); serr != nil {
+		logrus.Error(serr)
+	}
+
 	if err != nil {
 		logrus.Fatal(err)
 	}
@@ -99,6 +120,16 @@ func load(c *cli.Context) error {
 		logrus.SetLevel(logrus.PanicLevel)
 	}
 
+	// set log format based on config
+	switch c.String("log-format") {
+	case "t", "text", "Text", "TEXT":
+		logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	case "j", "json", "Json", "JSON":
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return util.InvalidFlagValue(c.String("log-format"), "log-format")
+	}
+
 	// validate configurations
 	err := validate(c)
 	if err != nil {
@@ -120,7 +151,8 @@ func validate(c *cli.Context) error {
 	}
 
 	// additional validations would go here
-	if len(c.String("token")) == 0 {
+	// multi-workspace deployments resolve bot tokens per team
+	if len(c.String("token")) == 0 && len(os.Getenv("SKELLY_CLIENT_ID")) == 0 {
 		return fmt.Errorf("no bot token provided")
 	}
 
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AddActivity adds a fired or skipped reaction to the db
+func AddActivity(ctx context.Context, activity *types.Activity) error {
+
+	_, span := tracing.Start(ctx, "db.AddActivity",
+		attribute.String("skelly.team", activity.Team),
+		attribute.String("skelly.channel", activity.Channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Tracef("adding activity for channel(%s) fired(%t) reason(%s)", activity.Channel, activity.Fired, activity.Reason)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(activityCollection)
+
+	// insert activity into db
+	err = col.Insert(activity)
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not insert activity into db for channel(%s)", activity.Channel)))
+	}
+
+	return nil
+}
+
+// CountActivity counts a skipped reaction in the db, once per team/channel/reaction/reason
+// and day, instead of adding it, meant for skips that happen for most messages
+func CountActivity(ctx context.Context, activity *types.Activity) error {
+
+	_, span := tracing.Start(ctx, "db.CountActivity",
+		attribute.String("skelly.team", activity.Team),
+		attribute.String("skelly.channel", activity.Channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Tracef("counting activity for channel(%s) reason(%s)", activity.Channel, activity.Reason)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(activityCollection)
+
+	// count by the start of the day
+	day := time.Unix(activity.Created, 0).UTC().Truncate(24 * time.Hour).Unix()
+
+	// upsert the count into db
+	_, err = col.Upsert(activityCountSelector(activity.Team, activity.Channel, activity.Reaction, activity.Reason, day), bson.M{
+		"$inc": bson.M{"count": 1},
+		"$setOnInsert": bson.M{
+			"trigger": activity.Trigger,
+			"fired":   false,
+			"expires": activity.Expires,
+		},
+	})
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not count activity in db for channel(%s)", activity.Channel)))
+	}
+
+	return nil
+}
+
+// EnsureActivityIndex adds the index that removes activity from the db once it expires
+func EnsureActivityIndex(ctx context.Context) error {
+
+	_, span := tracing.Start(ctx, "db.EnsureActivityIndex")
+	defer span.End()
+
+	logging.FromContext(ctx).Debug("ensuring activity index")
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(activityCollection)
+
+	// expire activity at its expires time, mongo requires a non-zero delay
+	err = col.EnsureIndex(mgo.Index{
+		Key:         []string{"expires"},
+		ExpireAfter: time.Second,
+	})
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not ensure activity index in db"))
+	}
+
+	return nil
+}
+
+// GetActivity retrieves the fired and skipped reactions for a channel since a unix time from the db
+// returns the activity for every channel in the team when channel is empty
+func GetActivity(ctx context.Context, team, channel string, since int64) ([]types.Activity, error) {
+
+	_, span := tracing.Start(ctx, "db.GetActivity",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting activity for channel(%s) since(%d)", channel, since)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(activityCollection)
+
+	activity := []types.Activity{}
+
+	// retrieve the activity from the db
+	err = col.Find(activitySelector(team, channel, since)).All(&activity)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get activity from db for channel(%s)", channel)))
+	}
+
+	return activity, nil
+}
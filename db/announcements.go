@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AddAnnouncement adds a scheduled announcement to the db
+func AddAnnouncement(ctx context.Context, announcement *types.Announcement) error {
+
+	_, span := tracing.Start(ctx, "db.AddAnnouncement",
+		attribute.String("skelly.team", announcement.Team),
+		attribute.String("skelly.channel", announcement.Channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("adding announcement for channel(%s)", announcement.Channel)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(announcementCollection)
+
+	if len(announcement.ID) == 0 {
+		announcement.ID = bson.NewObjectId()
+	}
+
+	// insert announcement into db
+	err = col.Insert(announcement)
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not insert announcement into db for channel(%s)", announcement.Channel)))
+	}
+
+	return nil
+}
+
+// GetAnnouncements retrieves the announcements for a channel from the db
+// returns every announcement for the team when channel is empty
+func GetAnnouncements(ctx context.Context, team, channel string) ([]types.Announcement, error) {
+
+	_, span := tracing.Start(ctx, "db.GetAnnouncements",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting announcements for channel(%s)", channel)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(announcementCollection)
+
+	announcements := []types.Announcement{}
+
+	// retrieve the announcements from the db
+	err = col.Find(announcementsSelector(team, channel)).Sort("channel", "nextrun").All(&announcements)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get announcements from db for channel(%s)", channel)))
+	}
+
+	return announcements, nil
+}
+
+// GetAnnouncement retrieves an announcement by id from the db
+// returns nil if the announcement does not exist
+func GetAnnouncement(ctx context.Context, id string) (*types.Announcement, error) {
+
+	_, span := tracing.Start(ctx, "db.GetAnnouncement", attribute.String("skelly.announcement", id))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting announcement(%s)", id)
+
+	if !bson.IsObjectIdHex(id) {
+		return nil, nil
+	}
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(announcementCollection)
+
+	announcements := []types.Announcement{}
+
+	// retrieve the announcement from the db
+	err = col.FindId(bson.ObjectIdHex(id)).All(&announcements)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get announcement(%s) from db", id)))
+	}
+
+	if len(announcements) == 0 {
+		return nil, nil
+	}
+
+	return &announcements[0], nil
+}
+
+// DeleteAnnouncement removes an announcement from the db
+func DeleteAnnouncement(ctx context.Context, announcement *types.Announcement) error {
+
+	_, span := tracing.Start(ctx, "db.DeleteAnnouncement", attribute.String("skelly.announcement", announcement.ID.Hex()))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("deleting announcement(%s)", announcement.ID.Hex())
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(announcementCollection)
+
+	// remove announcement from db
+	err = col.RemoveId(announcement.ID)
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not delete announcement(%s) from db", announcement.ID.Hex())))
+	}
+
+	return nil
+}
+
+// GetDueAnnouncements retrieves the announcements due to run at or before now from the db
+func GetDueAnnouncements(ctx context.Context, now int64) ([]types.Announcement, error) {
+
+	_, span := tracing.Start(ctx, "db.GetDueAnnouncements")
+	defer span.End()
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(announcementCollection)
+
+	announcements := []types.Announcement{}
+
+	// retrieve the due announcements from the db
+	err = col.Find(dueAnnouncementsSelector(now)).All(&announcements)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not get due announcements from db"))
+	}
+
+	return announcements, nil
+}
+
+// ClaimAnnouncement moves an announcement's run from nextRun to next in the db
+// returns false if another replica already claimed the run
+func ClaimAnnouncement(ctx context.Context, announcement *types.Announcement, next, now int64) (bool, error) {
+
+	_, span := tracing.Start(ctx, "db.ClaimAnnouncement", attribute.String("skelly.announcement", announcement.ID.Hex()))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("claiming announcement(%s) run(%d)", announcement.ID.Hex(), announcement.NextRun)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return false, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(announcementCollection)
+
+	// the update only matches while the run is unclaimed, so one replica wins
+	err = col.Update(claimAnnouncementSelector(announcement.ID, announcement.NextRun),
+		bson.M{"$set": bson.M{"nextrun": next, "lastrun": now}})
+
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not claim announcement(%s) in db", announcement.ID.Hex())))
+	}
+
+	return true, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AddAudit adds a change made to a reaction to the db
+func AddAudit(ctx context.Context, audit *types.Audit) error {
+
+	_, span := tracing.Start(ctx, "db.AddAudit",
+		attribute.String("skelly.team", audit.Team),
+		attribute.String("skelly.channel", audit.Channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("adding audit for channel(%s) action(%s) actor(%s)", audit.Channel, audit.Action, audit.Actor)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(auditCollection)
+
+	audit.ID = bson.NewObjectId()
+
+	// insert audit into db
+	err = col.Insert(audit)
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not insert audit into db for channel(%s)", audit.Channel)))
+	}
+
+	return nil
+}
+
+// GetAudits retrieves the changes made to reactions since a unix time from the db, newest first
+// returns every team or channel when empty, and at most limit changes unless limit is 0
+func GetAudits(ctx context.Context, team, channel string, since int64, limit int) ([]types.Audit, error) {
+
+	_, span := tracing.Start(ctx, "db.GetAudits",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting audits for channel(%s) since(%d)", channel, since)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(auditCollection)
+
+	audits := []types.Audit{}
+
+	// retrieve the audits from the db
+	err = col.Find(auditSelector(team, channel, since)).Sort("-created").Limit(limit).All(&audits)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get audits from db for channel(%s)", channel)))
+	}
+
+	return audits, nil
+}
@@ -1,20 +1,28 @@
 package db
 
 import (
+	"context"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
 	"github.com/davidvader/skelly/types"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // GetChannels retrieve a map for channels to reactions from the db
-func GetChannels() (*map[string]int, error) {
+// scoped to the team, when provided
+func GetChannels(ctx context.Context, team string) (*map[string]int, error) {
+
+	_, span := tracing.Start(ctx, "db.GetChannels", attribute.String("skelly.team", team))
+	defer span.End()
 
-	logrus.Infof("getting all channels")
+	logging.FromContext(ctx).Debugf("getting all channels")
 
 	// connect to mongo
 	session, err := connect()
 	if err != nil {
-		return nil, errors.Wrap(err, "could not connect to db")
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
 	defer session.Close()
 
@@ -24,12 +32,12 @@ func GetChannels() (*map[string]int, error) {
 	reactions := []types.Reaction{}
 
 	// retrieve the reactions from the db
-	err = col.Find(nil).All(&reactions)
+	err = col.Find(teamReactionsSelector(team)).All(&reactions)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not get reactions from db for all channels")
+		return nil, tracing.Error(span, errors.Wrap(err, "could not get reactions from db for all channels"))
 	}
 
-	logrus.Tracef("retrieved %v reactions from the db", len(reactions))
+	logging.FromContext(ctx).Tracef("retrieved %v reactions from the db", len(reactions))
 
 	// create a map from id to number of rules
 	channelRules := map[string]int{}
@@ -15,8 +15,26 @@ const (
 	collection = "reactions"
 	// responseCollection is the mongo db collection to store reponses
 	responseCollection = "responses"
+	// teamCollection is the mongo db collection to store installed teams
+	teamCollection = "teams"
+	// trashCollection is the mongo db collection to store deleted reactions
+	trashCollection = "trash"
+	// announcementCollection is the mongo db collection to store scheduled announcements
+	announcementCollection = "announcements"
+	// rotationCollection is the mongo db collection to store response rotation state
+	rotationCollection = "rotations"
+	// oooCollection is the mongo db collection to store out of office settings
+	oooCollection = "ooo"
+	// feedbackCollection is the mongo db collection to store feedback on faq answers
+	feedbackCollection = "feedback"
+	// activityCollection is the mongo db collection to store fired and skipped reactions
+	activityCollection = "activity"
+	// auditCollection is the mongo db collection to store changes made to reactions
+	auditCollection = "audit"
 	// dbTimeout is the primary mongo db collection used for storing reactions
 	dbTimeout = 60 * time.Second
+	// pingTimeout is the maximum time to wait for the mongo db when checking readiness
+	pingTimeout = 5 * time.Second
 )
 
 // global var for storing db Config
@@ -64,6 +82,17 @@ func getConfig() *Config {
 	return config
 }
 
+// Database returns the name of the mongo db used for every session
+func Database() string {
+	return getConfig().DB
+}
+
+// SetDatabase takes the name of a mongo db and uses it for every following session
+// ex: to keep replayed payloads away from the configured db
+func SetDatabase(name string) {
+	getConfig().DB = name
+}
+
 // toURI takes mongo config and returns the connection string
 func (c *Config) toURI() string {
 	return "mongodb://" + c.Username + ":" + url.QueryEscape(c.Password) + "@" + c.Host + "/" + c.DB
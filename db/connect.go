@@ -1,6 +1,9 @@
 package db
 
 import (
+	"context"
+
+	"github.com/davidvader/skelly/tracing"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/mgo.v2"
@@ -12,7 +15,7 @@ func connect() (*mgo.Session, error) {
 	// retrieving database config
 	c := getConfig()
 
-	logrus.Infof("connecting to mongo db(%s:%s:%s)", c.Host, c.DB, c.Username)
+	logrus.Tracef("connecting to mongo db(%s:%s:%s)", c.Host, c.DB, c.Username)
 
 	// connect to mongo db
 	s, err := mgo.Dial(c.toURI())
@@ -21,3 +24,29 @@ func connect() (*mgo.Session, error) {
 	}
 	return s, nil
 }
+
+// Ping starts a short lived session with the mongo db and verifies the server is responding
+func Ping(ctx context.Context) error {
+
+	_, span := tracing.Start(ctx, "db.Ping")
+	defer span.End()
+
+	// retrieving database config
+	c := getConfig()
+
+	// connect to mongo db, using a shorter timeout than
+	// regular sessions so that health probes fail fast
+	session, err := mgo.DialWithTimeout(c.toURI(), pingTimeout)
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to mongo db"))
+	}
+	defer session.Close()
+
+	// ping the server
+	err = session.Ping()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not ping mongo db"))
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AddFeedback adds an faq answer awaiting feedback to the db
+func AddFeedback(ctx context.Context, feedback *types.Feedback) error {
+
+	_, span := tracing.Start(ctx, "db.AddFeedback",
+		attribute.String("skelly.team", feedback.Team),
+		attribute.String("skelly.channel", feedback.Channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("adding feedback for channel(%s) ts(%s)", feedback.Channel, feedback.Timestamp)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(feedbackCollection)
+
+	if len(feedback.ID) == 0 {
+		feedback.ID = bson.NewObjectId()
+	}
+
+	// insert feedback into db
+	err = col.Insert(feedback)
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not insert feedback into db for channel(%s)", feedback.Channel)))
+	}
+
+	return nil
+}
+
+// GetFeedback retrieves feedback on an faq answer from the db
+// returns nil if the feedback does not exist or id is invalid
+func GetFeedback(ctx context.Context, id string) (*types.Feedback, error) {
+
+	_, span := tracing.Start(ctx, "db.GetFeedback", attribute.String("skelly.feedback", id))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting feedback(%s)", id)
+
+	if !bson.IsObjectIdHex(id) {
+		return nil, nil
+	}
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(feedbackCollection)
+
+	feedback := []types.Feedback{}
+
+	// retrieve the feedback from the db
+	err = col.FindId(bson.ObjectIdHex(id)).All(&feedback)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get feedback(%s) from db", id)))
+	}
+
+	if len(feedback) == 0 {
+		return nil, nil
+	}
+
+	return &feedback[0], nil
+}
+
+// UpdateFeedback replaces feedback on an faq answer in the db
+func UpdateFeedback(ctx context.Context, feedback *types.Feedback) error {
+
+	_, span := tracing.Start(ctx, "db.UpdateFeedback", attribute.String("skelly.feedback", feedback.ID.Hex()))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("updating feedback(%s)", feedback.ID.Hex())
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(feedbackCollection)
+
+	// replace the feedback in the db
+	err = col.UpdateId(feedback.ID, feedback)
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not update feedback(%s) in db", feedback.ID.Hex())))
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GetOutOfOffice retrieves a user's out of office settings from the db
+// returns nil if the user has none
+func GetOutOfOffice(ctx context.Context, team, user string) (*types.OutOfOffice, error) {
+
+	_, span := tracing.Start(ctx, "db.GetOutOfOffice",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.user", user))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting out of office for user(%s)", user)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(oooCollection)
+
+	// TODO: improve the use of .All() as .One() check
+	settings := []types.OutOfOffice{}
+
+	// retrieve the settings from the db
+	err = col.Find(oooSelector(team, user)).All(&settings)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get out of office from db for user(%s)", user)))
+	}
+
+	if len(settings) == 0 {
+		return nil, nil
+	}
+
+	return &settings[0], nil
+}
+
+// GetOutOfOffices retrieves the out of office settings for any of the users from the db
+func GetOutOfOffices(ctx context.Context, team string, users []string) ([]types.OutOfOffice, error) {
+
+	_, span := tracing.Start(ctx, "db.GetOutOfOffices", attribute.String("skelly.team", team))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting out of office for (%v) users", len(users))
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(oooCollection)
+
+	settings := []types.OutOfOffice{}
+
+	// retrieve the settings from the db
+	err = col.Find(oooUsersSelector(team, users)).All(&settings)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not get out of office from db"))
+	}
+
+	return settings, nil
+}
+
+// SaveOutOfOffice adds or replaces a user's out of office settings in the db
+func SaveOutOfOffice(ctx context.Context, o *types.OutOfOffice) error {
+
+	_, span := tracing.Start(ctx, "db.SaveOutOfOffice",
+		attribute.String("skelly.team", o.Team),
+		attribute.String("skelly.user", o.User))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("saving out of office for user(%s)", o.User)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(oooCollection)
+
+	// insert or replace the settings in the db
+	_, err = col.Upsert(oooSelector(o.Team, o.User), o)
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not save out of office in db for user(%s)", o.User)))
+	}
+
+	return nil
+}
@@ -1,23 +1,33 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
 	"github.com/davidvader/skelly/types"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
 
 // GetChannelReactions retrieve reactions for a channel from the db
-func GetChannelReactions(channel string) (*[]types.Reaction, error) {
+func GetChannelReactions(ctx context.Context, team, channel string) (*[]types.Reaction, error) {
 
-	logrus.Infof("getting reactions for channel(%s)", channel)
+	_, span := tracing.Start(ctx, "db.GetChannelReactions",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting reactions for channel(%s)", channel)
 
 	// connect to mongo
 	session, err := connect()
 	if err != nil {
-		return nil, errors.Wrap(err, "could not connect to db")
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
 	defer session.Close()
 
@@ -27,23 +37,28 @@ func GetChannelReactions(channel string) (*[]types.Reaction, error) {
 	reactions := []types.Reaction{}
 
 	// retrieve the reactions from the db
-	err = col.Find(bson.M{"channel": channel}).All(&reactions)
+	err = col.Find(channelSelector(team, channel)).All(&reactions)
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("could not get reactions from db for channel(%s)", channel))
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get reactions from db for channel(%s)", channel)))
 	}
 
 	return &reactions, nil
 }
 
 // GetReactions retrieves reactions for a channel from the db
-func GetReactions(channel string) ([]*types.Reaction, error) {
+func GetReactions(ctx context.Context, team, channel string) ([]*types.Reaction, error) {
+
+	_, span := tracing.Start(ctx, "db.GetReactions",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
 
-	logrus.Infof("getting reactions for channel(%s)", channel)
+	logging.FromContext(ctx).Debugf("getting reactions for channel(%s)", channel)
 
 	// connect to mongo
 	session, err := connect()
 	if err != nil {
-		return nil, errors.Wrap(err, "could not connect to db")
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
 	defer session.Close()
 
@@ -53,137 +68,113 @@ func GetReactions(channel string) ([]*types.Reaction, error) {
 	reactions := []*types.Reaction{}
 
 	// retrieve the reaction from the db
-	err = col.Find(reactionsSelector(channel)).All(&reactions)
+	err = col.Find(channelSelector(team, channel)).All(&reactions)
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("could not get reactions from db for channel(%s)", channel))
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get reactions from db for channel(%s)", channel)))
 	}
 
 	return reactions, nil
 }
 
-// GetReaction retrieve reaction for a channel from the db
-func GetReaction(channel string) (*types.Reaction, error) {
-
-	logrus.Infof("getting a reaction for channel(%s)", channel)
-
-	// connect to mongo
-	session, err := connect()
-	if err != nil {
-		return nil, errors.Wrap(err, "could not connect to db")
-	}
-	defer session.Close()
-
-	// retrieve the collection
-	col := session.DB(getConfig().DB).C(collection)
-
-	reaction := types.Reaction{}
-
-	// retrieve the reaction from the db
-	err = col.Find(reactionSelector(channel)).One(&reaction)
-	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("could not get reaction from db for channel(%s)", channel))
-	}
+// AddReaction adds a reaction for a channel to the db
+func AddReaction(ctx context.Context, reaction *types.Reaction) error {
 
-	return &reaction, nil
-}
+	team, channel := reaction.Team, reaction.Channel
 
-// AddReaction adds a reaction for a channel to the db
-func AddReaction(channel, response string) error {
+	_, span := tracing.Start(ctx, "db.AddReaction",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
 
-	logrus.Infof("adding a reaction for channel(%s) response(%s)", channel, response)
+	logging.FromContext(ctx).Debugf("adding a reaction for channel(%s) response(%s)", channel, reaction.Response)
 
 	// connect to mongo
 	session, err := connect()
 	if err != nil {
-		return errors.Wrap(err, "could not connect to db")
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
 	defer session.Close()
 
 	// retrieve the collection
 	col := session.DB(getConfig().DB).C(collection)
 
-	// TODO: improve the use of .All() as .One() check
 	reactions := []types.Reaction{}
 
 	// retrieve the reactions from the db
-	err = col.Find(reactionSelector(channel)).All(&reactions)
+	err = col.Find(channelSelector(team, channel)).All(&reactions)
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("could not get reaction from db for channel(%s)", channel))
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get reaction from db for channel(%s)", channel)))
 	}
 
-	// if it exists, do not add it
-	if len(reactions) != 0 {
-		return fmt.Errorf("reaction already exists for channel(%s)", channel)
+	// if one exists for the same trigger, do not add it
+	for i := range reactions {
+		if key := reaction.Key(); len(key) > 0 && reactions[i].Key() == key {
+			return tracing.Error(span, fmt.Errorf("reaction already exists for channel(%s) trigger(%s)", channel, key))
+		}
 	}
 
-	// save data into Reaction struct
-	reaction := types.Reaction{
-		Channel:  channel,
-		Response: response,
+	// assign the id up front so it can be referenced, ex: in the audit log
+	if len(reaction.ID) == 0 {
+		reaction.ID = bson.NewObjectId()
 	}
 
 	// insert reaction into db
 	err = col.Insert(reaction)
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("could not insert reaction into db for channel(%s) response(%s)", channel, response))
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not insert reaction into db for channel(%s) response(%s)", channel, reaction.Response)))
 	}
 
 	return nil
 }
 
-// UpdateReaction retrieve and updates a reaction for a channel from the db
-func UpdateReaction(channel, response string) error {
+// UpdateReaction replaces a reaction by id in the db
+func UpdateReaction(ctx context.Context, reaction *types.Reaction) error {
+
+	team, channel := reaction.Team, reaction.Channel
+
+	_, span := tracing.Start(ctx, "db.UpdateReaction",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
 
-	logrus.Infof("updating a reaction for channel(%s) response(%s)", channel, response)
+	logging.FromContext(ctx).Debugf("updating a reaction for channel(%s) response(%s)", channel, reaction.Response)
 
 	// connect to mongo
 	session, err := connect()
 	if err != nil {
-		return errors.Wrap(err, "could not connect to db")
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
 	defer session.Close()
 
 	// retrieve the collection
 	col := session.DB(getConfig().DB).C(collection)
 
-	// TODO: improve the use of .All() as .One() check
-	reactions := []types.Reaction{}
-
-	// retrieve the reactions from the db
-	err = col.Find(reactionSelector(channel)).All(&reactions)
-	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("could not get reactions from db for channel(%s)", channel))
-	}
-
-	// if it does not exist, do not update it
-	if len(reactions) == 0 {
-		return fmt.Errorf("reaction does not exist for channel(%s)", channel)
-	}
-
-	reaction := reactions[0]
-
-	if reaction.Response != response {
-		reaction.Response = response
-	}
-
 	// update reaction in db
-	err = col.Update(reactionSelector(channel), reaction)
+	err = col.UpdateId(reaction.ID, reaction)
+	if err == mgo.ErrNotFound {
+		return tracing.Error(span, fmt.Errorf("reaction(%s) does not exist for channel(%s)", reaction.ID.Hex(), channel))
+	}
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("could not update reaction in db for channel(%s)", reaction.Channel))
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not update reaction in db for channel(%s)", channel)))
 	}
 
 	return nil
 }
 
 // DeleteReactions retrieve and deletes reactions for a channel from the db
-func DeleteReactions(channel string) (int, error) {
+func DeleteReactions(ctx context.Context, team, channel string) (int, error) {
 
-	logrus.Infof("removing reactions for channel(%s)", channel)
+	_, span := tracing.Start(ctx, "db.DeleteReactions",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("removing reactions for channel(%s)", channel)
 
 	// connect to mongo
 	session, err := connect()
 	if err != nil {
-		return 0, errors.Wrap(err, "could not connect to db")
+		return 0, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
 	defer session.Close()
 
@@ -193,34 +184,45 @@ func DeleteReactions(channel string) (int, error) {
 	reactions := []types.Reaction{}
 
 	// retrieve the reactions from the db
-	err = col.Find(reactionSelector(channel)).All(&reactions)
+	err = col.Find(channelSelector(team, channel)).All(&reactions)
 	if err != nil {
-		return 0, errors.Wrap(err, fmt.Sprintf("could not get reaction from db for channel(%s)", channel))
+		return 0, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get reaction from db for channel(%s)", channel)))
 	}
 
 	// they do not exist, do not remove them
 	if len(reactions) == 0 {
-		return 0, fmt.Errorf("reactions do not exist for channel(%s)", channel)
+		return 0, tracing.Error(span, fmt.Errorf("reactions do not exist for channel(%s)", channel))
 	}
 
 	// remove reactions from db
-	_, err = col.RemoveAll(reactionSelector(channel))
+	_, err = col.RemoveAll(channelSelector(team, channel))
 	if err != nil {
-		return 0, errors.Wrap(err, fmt.Sprintf("could not delete reactions from db for channel(%s)", channel))
+		return 0, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not delete reactions from db for channel(%s)", channel)))
 	}
 
 	return len(reactions), nil
 }
 
-// DeleteChannelReactions retrieve and deletes reactions for a channel from the db
-func DeleteChannelReactions(channel string) (int, error) {
+// ReactionExists checks for a reaction with the key for a channel in the db, see types.Reaction.Key
+// an empty key never exists, faq reactions are not unique
+func ReactionExists(ctx context.Context, team, channel, key string) (bool, *types.Reaction, error) {
+
+	_, span := tracing.Start(ctx, "db.ReactionExists",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel),
+		attribute.String("skelly.key", key))
+	defer span.End()
 
-	logrus.Infof("removing reactions for channel(%s)", channel)
+	logging.FromContext(ctx).Debugf("checking for reaction channel(%s) key(%s)", channel, key)
+
+	if len(key) == 0 {
+		return false, nil, nil
+	}
 
 	// connect to mongo
 	session, err := connect()
 	if err != nil {
-		return 0, errors.Wrap(err, "could not connect to db")
+		return false, nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
 	defer session.Close()
 
@@ -230,69 +232,84 @@ func DeleteChannelReactions(channel string) (int, error) {
 	reactions := []types.Reaction{}
 
 	// retrieve the reactions from the db
-	err = col.Find(channelSelector(channel)).All(&reactions)
+	err = col.Find(channelSelector(team, channel)).All(&reactions)
 	if err != nil {
-		return 0, errors.Wrap(err, fmt.Sprintf("could not get reaction from db for channel(%s)", channel))
-	}
-
-	// they do not exist, do not remove them
-	if len(reactions) == 0 {
-		return 0, fmt.Errorf("reactions do not exist for channel(%s)", channel)
+		return false, nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get reaction from db for channel(%s)", channel)))
 	}
 
-	// remove reactions from db
-	_, err = col.RemoveAll(channelSelector(channel))
-	if err != nil {
-		return 0, errors.Wrap(err, fmt.Sprintf("could not delete reactions from db for channel(%s)", channel))
+	// check for reaction with the key
+	for i := range reactions {
+		if reactions[i].Key() == key {
+			return true, &reactions[i], nil
+		}
 	}
 
-	return len(reactions), nil
+	return false, nil, nil
 }
 
-// ReactionExists checks for reaction for a channel in the db
-func ReactionExists(channel string) (bool, *types.Reaction, error) {
+// StoreResponse stores a response by a reaction for a channel/user/timestamp in the db
+// reaction is the id of the reaction that responded, keeping responses by different reactions apart
+func StoreResponse(ctx context.Context, channel, user, timestamp, reaction string) error {
+
+	_, span := tracing.Start(ctx, "db.StoreResponse", attribute.String("skelly.channel", channel))
+	defer span.End()
 
-	logrus.Infof("checking for reaction channel(%s)", channel)
+	logging.FromContext(ctx).Debugf("storing response for channel(%s) user(%s) timestamp(%s) reaction(%s)", channel, user, timestamp, reaction)
 
 	// connect to mongo
 	session, err := connect()
 	if err != nil {
-		return false, nil, errors.Wrap(err, "could not connect to db")
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
 	defer session.Close()
 
 	// retrieve the collection
-	col := session.DB(getConfig().DB).C(collection)
+	col := session.DB(getConfig().DB).C(responseCollection)
 
-	reactions := []types.Reaction{}
+	// TODO: improve the use of .All() as .One() check
+	responses := []types.Response{}
 
-	// retrieve the reaction from the db
-	err = col.Find(reactionSelector(channel)).All(&reactions)
+	// retrieve the reactions from the db
+	err = col.Find(responseSelector(channel, user, timestamp, reaction)).All(&responses)
 	if err != nil {
-		return false, nil, errors.Wrap(err, fmt.Sprintf("could not get reaction from db for channel(%s)", channel))
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get response from db for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp)))
 	}
 
-	// check for reaction
-	exists := false
-	var r *types.Reaction
+	// if it exists, do not add it
+	if len(responses) != 0 {
+		return tracing.Error(span, fmt.Errorf("response already exists for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp))
+	}
 
-	if len(reactions) > 0 {
-		exists = true
-		r = &reactions[0]
+	// save data into Response struct
+	response := types.Response{
+		Channel:   channel,
+		User:      user,
+		Timestamp: timestamp,
+		Created:   time.Now().Unix(),
+		Reaction:  reaction,
+	}
+
+	// insert reaction into db
+	err = col.Insert(response)
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not insert response into db for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp)))
 	}
 
-	return exists, r, nil
+	return nil
 }
 
-// StoreResponse stores a response for a channel/user/timestamp in the db
-func StoreResponse(channel, user, timestamp string) error {
+// CheckResponse checks to see if a response by a reaction for a channel/user/timestamp exits in the db
+func CheckResponse(ctx context.Context, channel, user, timestamp, reaction string) (bool, error) {
 
-	logrus.Infof("storing response for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp)
+	_, span := tracing.Start(ctx, "db.CheckResponse", attribute.String("skelly.channel", channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("checking for response for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp)
 
 	// connect to mongo
 	session, err := connect()
 	if err != nil {
-		return errors.Wrap(err, "could not connect to db")
+		return false, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
 	defer session.Close()
 
@@ -303,55 +320,114 @@ func StoreResponse(channel, user, timestamp string) error {
 	responses := []types.Response{}
 
 	// retrieve the reactions from the db
-	err = col.Find(responseSelector(channel, user, timestamp)).All(&responses)
+	err = col.Find(responseSelector(channel, user, timestamp, reaction)).All(&responses)
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("could not get response from db for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp))
+		return false, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get response from db for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp)))
 	}
 
-	// if it exists, do not add it
-	if len(responses) != 0 {
-		return fmt.Errorf("response already exists for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp)
-	}
+	return len(responses) != 0, nil
+}
 
-	// save data into Response struct
-	response := types.Response{
-		Channel:   channel,
-		User:      user,
-		Timestamp: timestamp,
+// CheckMessageResponse checks the db for a response by a reaction to a message from any user
+func CheckMessageResponse(ctx context.Context, channel, timestamp, reaction string) (bool, error) {
+
+	_, span := tracing.Start(ctx, "db.CheckMessageResponse", attribute.String("skelly.channel", channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("checking for response for channel(%s) timestamp(%s)", channel, timestamp)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return false, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
+	defer session.Close()
 
-	// insert reaction into db
-	err = col.Insert(response)
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(responseCollection)
+
+	// count the responses to the message
+	n, err := col.Find(messageResponseSelector(channel, timestamp, reaction)).Count()
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("could not insert response into db for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp))
+		return false, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not count responses in db for channel(%s) timestamp(%s)", channel, timestamp)))
 	}
 
-	return nil
+	return n != 0, nil
 }
 
-// CheckResponse checks to see if a response for a channel/user/timestamp exits in the db
-func CheckResponse(channel, user, timestamp string) (bool, error) {
+// GetLastResponse retrieves the most recent response by a reaction to a user in a channel from the db
+// returns nil if the reaction has not responded to the user in the channel
+func GetLastResponse(ctx context.Context, channel, user, reaction string) (*types.Response, error) {
+
+	_, span := tracing.Start(ctx, "db.GetLastResponse", attribute.String("skelly.channel", channel))
+	defer span.End()
 
-	logrus.Infof("checking for response for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp)
+	logging.FromContext(ctx).Debugf("getting last response for channel(%s) user(%s)", channel, user)
 
 	// connect to mongo
 	session, err := connect()
 	if err != nil {
-		return false, errors.Wrap(err, "could not connect to db")
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
 	}
 	defer session.Close()
 
 	// retrieve the collection
 	col := session.DB(getConfig().DB).C(responseCollection)
 
-	// TODO: improve the use of .All() as .One() check
 	responses := []types.Response{}
 
-	// retrieve the reactions from the db
-	err = col.Find(responseSelector(channel, user, timestamp)).All(&responses)
+	// retrieve the newest response from the db
+	err = col.Find(userResponseSelector(channel, user, reaction)).Sort("-created").Limit(1).All(&responses)
 	if err != nil {
-		return false, errors.Wrap(err, fmt.Sprintf("could not get response from db for channel(%s) user(%s) timestamp(%s)", channel, user, timestamp))
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get last response from db for channel(%s) user(%s)", channel, user)))
 	}
 
-	return len(responses) != 0, nil
+	if len(responses) == 0 {
+		return nil, nil
+	}
+
+	return &responses[0], nil
+}
+
+// CountResponses retrieves the number of responses sent in a channel by the team's reactions from the db
+// responses do not record a team, so they are matched by the reactions of the team in the channel
+func CountResponses(ctx context.Context, team, channel string) (int, error) {
+
+	_, span := tracing.Start(ctx, "db.CountResponses",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("counting responses for channel(%s)", channel)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return 0, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	reactions := []types.Reaction{}
+
+	// retrieve the team's reactions for the channel from the db
+	err = session.DB(getConfig().DB).C(collection).Find(channelSelector(team, channel)).Select(bson.M{"_id": 1}).All(&reactions)
+	if err != nil {
+		return 0, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get reactions from db for channel(%s)", channel)))
+	}
+
+	ids := []string{}
+	for _, r := range reactions {
+		ids = append(ids, r.ID.Hex())
+	}
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(responseCollection)
+
+	// count the responses in the db
+	n, err := col.Find(channelResponsesSelector(channel, ids)).Count()
+	if err != nil {
+		return 0, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not count responses in db for channel(%s)", channel)))
+	}
+
+	return n, nil
 }
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// NextRotation increments the rotation count for a reaction and user in the db
+// returns the count before incrementing, starting at zero
+// user is empty when rotating for the whole channel
+func NextRotation(ctx context.Context, reaction, user string) (int, error) {
+
+	_, span := tracing.Start(ctx, "db.NextRotation",
+		attribute.String("skelly.reaction", reaction),
+		attribute.String("skelly.user", user))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("rotating reaction(%s) for user(%s)", reaction, user)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return 0, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(rotationCollection)
+
+	rotation := new(types.Rotation)
+
+	// increment atomically so concurrent reactions do not repeat a response
+	_, err = col.Find(rotationSelector(reaction, user)).Apply(mgo.Change{
+		Update:    bson.M{"$inc": bson.M{"count": 1}},
+		Upsert:    true,
+		ReturnNew: true,
+	}, rotation)
+	if err != nil {
+		return 0, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not rotate reaction(%s) in db", reaction)))
+	}
+
+	return rotation.Count - 1, nil
+}
@@ -2,53 +2,160 @@ package db
 
 import "gopkg.in/mgo.v2/bson"
 
-// channelSelector return mgo/bson selector for retrieving reactions by channel
-func channelSelector(channel string) bson.M {
+// channelSelector return mgo/bson selector for retrieving reactions by team/channel
+func channelSelector(team, channel string) bson.M {
 
 	// returns mgo/bson selector containing channel
-	return bson.M{
+	return withTeam(bson.M{
 		"channel": channel,
+	}, team)
+}
+
+// teamReactionsSelector return mgo/bson selector for retrieving every reaction for a team
+// matches every reaction when team is empty
+func teamReactionsSelector(team string) bson.M {
+	return withTeam(bson.M{}, team)
+}
+
+// withTeam takes a selector and scopes it to exactly the team
+// slack requests always have a team, only the cli passes an empty team
+// to target every workspace, so an empty team leaves the selector unscoped
+func withTeam(selector bson.M, team string) bson.M {
+
+	if len(team) > 0 {
+		selector["team"] = team
+	}
+
+	return selector
+}
+
+// missingTeamSelector return mgo/bson selector for retrieving documents created without a team
+func missingTeamSelector() bson.M {
+	return bson.M{
+		"team": bson.M{"$in": []interface{}{"", nil}},
 	}
 }
 
-// reactionsSelector return mgo/bson selector for retrieving reactions by channel
-func reactionsSelector(channel string) []bson.DocElem {
+// teamSelector return mgo/bson selector for retrieving installed teams by id
+func teamSelector(id string) bson.M {
 
-	// returns mgo/bson selector containing channel
-	// uses bson.D with channel first for performance
+	// returns mgo/bson selector containing id
+	return bson.M{
+		"id": id,
+	}
+}
+
+// responseSelector return mgo/bson selector for retreiving responses by channel/user/timestamp/reaction
+func responseSelector(channel, user, timestamp, reaction string) []bson.DocElem {
+
+	// return mgo/bson selector containing channel, user, timestamp and reaction
 	return bson.D{
+		{
+			Name:  "timestamp",
+			Value: timestamp,
+		},
 		{
 			Name:  "channel",
 			Value: channel,
 		},
+		{
+			Name:  "user",
+			Value: user,
+		},
+		{
+			Name:  "reaction",
+			Value: reaction,
+		},
+	}
+}
+
+// messageResponseSelector return mgo/bson selector for retreiving responses by channel/timestamp/reaction
+func messageResponseSelector(channel, timestamp, reaction string) bson.M {
+	return bson.M{
+		"channel":   channel,
+		"timestamp": timestamp,
+		"reaction":  reaction,
 	}
 }
 
-// reactionSelector return mgo/bson selector for retrieving reactions by channel
-func reactionSelector(channel string) []bson.DocElem {
+// userResponseSelector return mgo/bson selector for retreiving responses by channel/user/reaction
+func userResponseSelector(channel, user, reaction string) []bson.DocElem {
 
-	// returns mgo/bson selector containing channel
-	// uses bson.D with channel first for performance
+	// return mgo/bson selector containing channel, user and reaction
 	return bson.D{
 		{
 			Name:  "channel",
 			Value: channel,
 		},
+		{
+			Name:  "user",
+			Value: user,
+		},
+		{
+			Name:  "reaction",
+			Value: reaction,
+		},
+	}
+}
+
+// channelResponsesSelector return mgo/bson selector for retreiving responses by channel
+// sent by one of the reactions
+func channelResponsesSelector(channel string, reactions []string) bson.M {
+	return bson.M{
+		"channel":  channel,
+		"reaction": bson.M{"$in": reactions},
+	}
+}
+
+// trashSelector return mgo/bson selector for retrieving deleted reactions by team/channel
+// deleted at or after since
+func trashSelector(team, channel string, since int64) bson.M {
+
+	selector := bson.M{
+		"channel": channel,
+		"deleted": bson.M{"$gte": since},
+	}
+
+	return withTeam(selector, team)
+}
+
+// announcementsSelector return mgo/bson selector for retrieving announcements by team/channel
+// matches every channel for the team when channel is empty
+func announcementsSelector(team, channel string) bson.M {
+
+	selector := bson.M{}
+
+	if len(channel) > 0 {
+		selector["channel"] = channel
+	}
+
+	return withTeam(selector, team)
+}
+
+// dueAnnouncementsSelector return mgo/bson selector for retrieving announcements due at or before now
+func dueAnnouncementsSelector(now int64) bson.M {
+	return bson.M{
+		"nextrun": bson.M{"$lte": now},
+	}
+}
+
+// claimAnnouncementSelector return mgo/bson selector for claiming an announcement's run
+// only matches while the run has not been claimed by another replica
+func claimAnnouncementSelector(id bson.ObjectId, nextRun int64) bson.M {
+	return bson.M{
+		"_id":     id,
+		"nextrun": nextRun,
 	}
 }
 
-// responseSelector return mgo/bson selector for retreiving responses by channel/user/timestamp
-func responseSelector(channel, user, timestamp string) []bson.DocElem {
+// rotationSelector return mgo/bson selector for retrieving rotation state by reaction/user
+func rotationSelector(reaction, user string) []bson.DocElem {
 
-	// return mgo/bson selector containing channel, user, and timestamp
+	// return mgo/bson selector containing reaction and user
 	return bson.D{
 		{
-			Name:  "timestamp",
-			Value: timestamp,
-		},
-		{
-			Name:  "channel",
-			Value: channel,
+			Name:  "reaction",
+			Value: reaction,
 		},
 		{
 			Name:  "user",
@@ -56,3 +163,62 @@ func responseSelector(channel, user, timestamp string) []bson.DocElem {
 		},
 	}
 }
+
+// oooSelector return mgo/bson selector for retrieving out of office settings by team/user
+func oooSelector(team, user string) bson.M {
+	return bson.M{
+		"team": team,
+		"user": user,
+	}
+}
+
+// oooUsersSelector return mgo/bson selector for retrieving out of office settings for any of the users
+func oooUsersSelector(team string, users []string) bson.M {
+	return bson.M{
+		"team": team,
+		"user": bson.M{"$in": users},
+	}
+}
+
+// activitySelector return mgo/bson selector for retrieving reaction activity by team/channel
+// created at or after since, matches every channel for the team when channel is empty
+func activitySelector(team, channel string, since int64) bson.M {
+
+	selector := bson.M{
+		"team":    team,
+		"created": bson.M{"$gte": since},
+	}
+
+	if len(channel) > 0 {
+		selector["channel"] = channel
+	}
+
+	return selector
+}
+
+// activityCountSelector return mgo/bson selector for counting skipped activity by team/channel/reaction/reason
+// created at the start of a day
+func activityCountSelector(team, channel, reaction, reason string, created int64) bson.M {
+	return bson.M{
+		"team":     team,
+		"channel":  channel,
+		"reaction": reaction,
+		"reason":   reason,
+		"created":  created,
+	}
+}
+
+// auditSelector return mgo/bson selector for retrieving audit events by team/channel
+// created at or after since, matches every team or channel when empty
+func auditSelector(team, channel string, since int64) bson.M {
+
+	selector := bson.M{
+		"created": bson.M{"$gte": since},
+	}
+
+	if len(channel) > 0 {
+		selector["channel"] = channel
+	}
+
+	return withTeam(selector, team)
+}
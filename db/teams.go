@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// GetTeam retrieves an installed team from the db
+// returns nil if the team has not installed skelly
+func GetTeam(ctx context.Context, id string) (*types.Team, error) {
+
+	_, span := tracing.Start(ctx, "db.GetTeam", attribute.String("skelly.team", id))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting team(%s)", id)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(teamCollection)
+
+	// TODO: improve the use of .All() as .One() check
+	teams := []types.Team{}
+
+	// retrieve the team from the db
+	err = col.Find(teamSelector(id)).All(&teams)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get team(%s) from db", id)))
+	}
+
+	if len(teams) == 0 {
+		return nil, nil
+	}
+
+	return &teams[0], nil
+}
+
+// SaveTeam adds or replaces an installed team in the db
+func SaveTeam(ctx context.Context, team *types.Team) error {
+
+	_, span := tracing.Start(ctx, "db.SaveTeam", attribute.String("skelly.team", team.ID))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("saving team(%s)", team.ID)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(teamCollection)
+
+	// insert or replace the team in the db
+	_, err = col.Upsert(teamSelector(team.ID), team)
+	if err != nil {
+		return tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not save team(%s) in db", team.ID)))
+	}
+
+	return nil
+}
+
+// BackfillTeam sets the team of reactions, trash and announcements created
+// before multi-workspace support, returns how many documents were updated
+func BackfillTeam(ctx context.Context, team string) (int, error) {
+
+	_, span := tracing.Start(ctx, "db.BackfillTeam", attribute.String("skelly.team", team))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("backfilling team(%s)", team)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return 0, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	n := 0
+
+	for _, c := range []string{collection, trashCollection, announcementCollection} {
+
+		// retrieve the collection
+		col := session.DB(getConfig().DB).C(c)
+
+		// set the team on documents without one
+		info, err := col.UpdateAll(missingTeamSelector(), bson.M{"$set": bson.M{"team": team}})
+		if err != nil {
+			return n, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not backfill team in db for collection(%s)", c)))
+		}
+
+		n += info.Updated
+	}
+
+	return n, nil
+}
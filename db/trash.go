@@ -0,0 +1,267 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ErrReactionExists is returned when restoring reactions to a channel that has had a reaction for the same trigger added since
+var ErrReactionExists = errors.New("reaction already exists")
+
+// TrashReactions moves the reactions for a channel from the db into the trash
+// only the reaction with id is moved, unless id is empty
+// returns the trash entry, used for restoring the reactions
+func TrashReactions(ctx context.Context, team, channel, id, user string) (*types.Trash, error) {
+
+	_, span := tracing.Start(ctx, "db.TrashReactions",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("trashing reactions for channel(%s)", channel)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collections
+	col := session.DB(getConfig().DB).C(collection)
+	trash := session.DB(getConfig().DB).C(trashCollection)
+
+	selector := channelSelector(team, channel)
+
+	// only trash the one reaction, when provided
+	if len(id) > 0 {
+
+		if !bson.IsObjectIdHex(id) {
+			return nil, tracing.Error(span, fmt.Errorf("invalid reaction id(%s)", id))
+		}
+
+		selector["_id"] = bson.ObjectIdHex(id)
+	}
+
+	reactions := []types.Reaction{}
+
+	// retrieve the reactions from the db
+	err = col.Find(selector).All(&reactions)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get reactions from db for channel(%s)", channel)))
+	}
+
+	// they do not exist, do not remove them
+	if len(reactions) == 0 {
+		return nil, tracing.Error(span, fmt.Errorf("reactions do not exist for channel(%s)", channel))
+	}
+
+	t := &types.Trash{
+		ID:        bson.NewObjectId(),
+		Team:      team,
+		Channel:   channel,
+		DeletedBy: user,
+		Deleted:   time.Now().Unix(),
+		Reactions: reactions,
+	}
+
+	// keep the reactions in the trash before removing them
+	err = trash.Insert(t)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not insert trash into db for channel(%s)", channel)))
+	}
+
+	// remove reactions from db
+	_, err = col.RemoveAll(selector)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not delete reactions from db for channel(%s)", channel)))
+	}
+
+	return t, nil
+}
+
+// GetTrash retrieves a trash entry by id from the db
+// returns nil if the entry does not exist
+func GetTrash(ctx context.Context, id string) (*types.Trash, error) {
+
+	_, span := tracing.Start(ctx, "db.GetTrash", attribute.String("skelly.trash", id))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting trash(%s)", id)
+
+	if !bson.IsObjectIdHex(id) {
+		return nil, tracing.Error(span, fmt.Errorf("invalid trash id(%s)", id))
+	}
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(trashCollection)
+
+	trash := []types.Trash{}
+
+	// retrieve the trash from the db
+	err = col.FindId(bson.ObjectIdHex(id)).All(&trash)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get trash(%s) from db", id)))
+	}
+
+	if len(trash) == 0 {
+		return nil, nil
+	}
+
+	return &trash[0], nil
+}
+
+// GetLatestTrash retrieves the most recent trash entry for a channel deleted at or after since
+// returns nil if there is no such entry
+func GetLatestTrash(ctx context.Context, team, channel string, since int64) (*types.Trash, error) {
+
+	_, span := tracing.Start(ctx, "db.GetLatestTrash",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("getting latest trash for channel(%s)", channel)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(trashCollection)
+
+	trash := []types.Trash{}
+
+	// retrieve the newest trash from the db
+	err = col.Find(trashSelector(team, channel, since)).Sort("-deleted").Limit(1).All(&trash)
+	if err != nil {
+		return nil, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get trash from db for channel(%s)", channel)))
+	}
+
+	if len(trash) == 0 {
+		return nil, nil
+	}
+
+	return &trash[0], nil
+}
+
+// RestoreTrash moves the reactions of a trash entry back into the db
+// removing the trash entry claims the restore, so it is restored at most once
+// returns false if the trash was already restored, and ErrReactionExists if
+// the channel has had a reaction for the same trigger added since
+func RestoreTrash(ctx context.Context, t *types.Trash) (bool, error) {
+
+	_, span := tracing.Start(ctx, "db.RestoreTrash",
+		attribute.String("skelly.team", t.Team),
+		attribute.String("skelly.channel", t.Channel))
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("restoring trash(%s) for channel(%s)", t.ID.Hex(), t.Channel)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return false, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collections
+	col := session.DB(getConfig().DB).C(collection)
+	trash := session.DB(getConfig().DB).C(trashCollection)
+
+	existing := []types.Reaction{}
+
+	// retrieve the reactions from the db
+	err = col.Find(channelSelector(t.Team, t.Channel)).All(&existing)
+	if err != nil {
+		return false, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not get reactions from db for channel(%s)", t.Channel)))
+	}
+
+	// do not restore over a newer reaction for the same trigger
+	for i := range existing {
+		for j := range t.Reactions {
+			if key := t.Reactions[j].Key(); len(key) > 0 && existing[i].Key() == key {
+				return false, ErrReactionExists
+			}
+		}
+	}
+
+	// the removal only matches while the trash exists, so one restore wins
+	err = trash.RemoveId(t.ID)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not delete trash(%s) from db", t.ID.Hex())))
+	}
+
+	// insert reactions into db
+	for i := range t.Reactions {
+
+		r := &t.Reactions[i]
+
+		// reactions trashed before multi-workspace support have no team
+		if len(r.Team) == 0 {
+			r.Team = t.Team
+		}
+
+		err = col.Insert(r)
+		if err != nil {
+
+			// keep the reactions restorable
+			if terr := trash.Insert(t); terr != nil {
+				logging.FromContext(ctx).Errorf("could not put trash(%s) back into db: %v", t.ID.Hex(), terr)
+			}
+
+			return false, tracing.Error(span, errors.Wrap(err, fmt.Sprintf("could not insert reaction into db for channel(%s)", t.Channel)))
+		}
+	}
+
+	return true, nil
+}
+
+// PruneTrash removes trash entries deleted before the given time from the db
+func PruneTrash(ctx context.Context, before int64) (int, error) {
+
+	_, span := tracing.Start(ctx, "db.PruneTrash")
+	defer span.End()
+
+	logging.FromContext(ctx).Debugf("pruning trash deleted before(%d)", before)
+
+	// connect to mongo
+	session, err := connect()
+	if err != nil {
+		return 0, tracing.Error(span, errors.Wrap(err, "could not connect to db"))
+	}
+	defer session.Close()
+
+	// retrieve the collection
+	col := session.DB(getConfig().DB).C(trashCollection)
+
+	// remove expired trash from db
+	info, err := col.RemoveAll(bson.M{"deleted": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, tracing.Error(span, errors.Wrap(err, "could not delete expired trash from db"))
+	}
+
+	return info.Removed, nil
+}
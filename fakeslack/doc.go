@@ -0,0 +1,37 @@
+// Package fakeslack provides an in-process fake of the slack web api and a
+// request signer for driving skelly's router without network access.
+//
+// The fake serves auth.test, chat.postMessage, chat.postEphemeral, views.open,
+// views.publish, reactions.add, conversations.replies, users.info and usergroups.users.list,
+// plus response urls, and records every call it receives. The signer builds slash command, event and interaction
+// requests signed with the same secret as SKELLY_SIGNING_SECRET.
+//
+// Usage:
+//
+//	fake := fakeslack.NewServer()
+//	defer fake.Close()
+//
+//	// send every slack api call made by skelly to the fake
+//	fake.Install()
+//
+//	signer := &fakeslack.Signer{Secret: os.Getenv("SKELLY_SIGNING_SECRET")}
+//
+//	req, _ := signer.SlashCommand("/commands", url.Values{
+//		"command":      {"/skelly"},
+//		"text":         {"add"},
+//		"team_id":      {"T123"},
+//		"channel_id":   {"C123"},
+//		"user_id":      {"U123"},
+//		"trigger_id":   {"123.456"},
+//		"response_url": {fake.ResponseURL()},
+//	})
+//
+//	rec := httptest.NewRecorder()
+//	router.New().ServeHTTP(rec, req)
+//
+//	// slash commands are handled asynchronously
+//	calls := fake.CallsFor("views.open")
+//
+// Reactions are still stored in mongo, so the SKELLY_MONGO_* configuration must
+// point at a reachable database.
+package fakeslack
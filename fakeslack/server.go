@@ -0,0 +1,354 @@
+package fakeslack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/slack-go/slack"
+)
+
+// ResponseURLMethod is the method recorded for messages posted to a response url
+const ResponseURLMethod = "response_url"
+
+// Call is the struct representation for a single request received by the fake slack api
+type Call struct {
+	Method string                 `json:"method" yaml:"method"`
+	Token  string                 `json:"token,omitempty" yaml:"token,omitempty"`
+	Params url.Values             `json:"params,omitempty" yaml:"params,omitempty"`
+	JSON   map[string]interface{} `json:"json,omitempty" yaml:"json,omitempty"`
+}
+
+// Server is an in-process fake of the slack web api
+// it records every call and responds the way slack would for the methods used by skelly
+type Server struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	calls   []Call
+	replies map[string][]slack.Message
+	users   map[string]slack.User
+	groups  map[string][]string
+	seq     int
+}
+
+// NewServer starts a fake slack api listening on a random local port
+func NewServer() *Server {
+
+	s := &Server{
+		replies: map[string][]slack.Message{},
+		users:   map[string]slack.User{},
+		groups:  map[string][]string{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/", s.handleAPI)
+	mux.HandleFunc("/response/", s.handleResponseURL)
+
+	s.server = httptest.NewServer(mux)
+
+	return s
+}
+
+// URL returns the base url of the fake slack api, ex: http://127.0.0.1:1234/api/
+// meant for use with slack.OptionAPIURL
+func (s *Server) URL() string {
+	return s.server.URL + "/api/"
+}
+
+// ResponseURL returns a unique response url served by the fake, meant for
+// use in slash command and interaction payloads
+func (s *Server) ResponseURL() string {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+
+	return fmt.Sprintf("%s/response/%d", s.server.URL, s.seq)
+}
+
+// Install points every skelly slack api client at the fake
+func (s *Server) Install() {
+	slackapi.SetFactory(func(token string) slackapi.Client {
+		return slackapi.New(token, slack.OptionAPIURL(s.URL()))
+	})
+}
+
+// Close shuts down the fake slack api
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Calls returns every call received so far, in order
+func (s *Server) Calls() []Call {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Call{}, s.calls...)
+}
+
+// CallsFor returns the calls received so far for a single method, ex: chat.postMessage
+func (s *Server) CallsFor(method string) []Call {
+
+	calls := []Call{}
+
+	for _, c := range s.Calls() {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+
+	return calls
+}
+
+// Wait waits for calls to stop arriving and returns every call received so far
+// it returns once a call was received and none followed within quiet, or after timeout
+// meant for requests that skelly acknowledges before handling them
+func (s *Server) Wait(quiet, timeout time.Duration) []Call {
+
+	deadline := time.Now().Add(timeout)
+
+	count, last := 0, time.Now()
+
+	for time.Now().Before(deadline) {
+
+		n := len(s.Calls())
+
+		if n != count {
+			count, last = n, time.Now()
+		} else if n > 0 && time.Since(last) >= quiet {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return s.Calls()
+}
+
+// Reset forgets every call received so far
+func (s *Server) Reset() {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = nil
+}
+
+// SetReplies sets the messages returned by conversations.replies for a channel and timestamp
+func (s *Server) SetReplies(channel, ts string, msgs []slack.Message) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.replies[channel+":"+ts] = msgs
+}
+
+// SetUser sets the user returned by users.info, ex: a workspace admin
+// unknown users are returned as regular members
+func (s *Server) SetUser(u slack.User) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[u.ID] = u
+}
+
+// SetUserGroup sets the members returned by usergroups.users.list for a user group
+func (s *Server) SetUserGroup(group string, members []string) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.groups[group] = members
+}
+
+// record stores a call
+func (s *Server) record(c Call) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, c)
+}
+
+// handleAPI serves a slack web api method, ex: POST /api/chat.postMessage
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+
+	method := strings.TrimPrefix(r.URL.Path, "/api/")
+
+	call, err := parseCall(method, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.record(call)
+
+	var resp map[string]interface{}
+
+	switch method {
+	case "auth.test":
+		resp = map[string]interface{}{
+			"url":     "https://fake.slack.com/",
+			"team":    "fake",
+			"user":    "skelly",
+			"team_id": "T00000000",
+			"user_id": "U00000000",
+		}
+
+	case "chat.postMessage":
+		resp = map[string]interface{}{
+			"channel": call.Params.Get("channel"),
+			"ts":      s.timestamp(),
+		}
+
+	case "chat.postEphemeral":
+		resp = map[string]interface{}{
+			"message_ts": s.timestamp(),
+		}
+
+	case "views.open", "views.publish":
+		// echo the view back with an id, leaving the recorded call untouched
+		view := map[string]interface{}{}
+		if v, ok := call.JSON["view"].(map[string]interface{}); ok {
+			for k, val := range v {
+				view[k] = val
+			}
+		}
+		view["id"] = fmt.Sprintf("V%08d", s.next())
+
+		resp = map[string]interface{}{
+			"view": view,
+		}
+
+	case "reactions.add":
+		resp = map[string]interface{}{}
+
+	case "conversations.replies":
+		s.mu.Lock()
+		msgs, ok := s.replies[call.Params.Get("channel")+":"+call.Params.Get("ts")]
+		s.mu.Unlock()
+
+		// default to a message that is not part of a thread
+		if !ok {
+			msgs = []slack.Message{{Msg: slack.Msg{Timestamp: call.Params.Get("ts")}}}
+		}
+
+		resp = map[string]interface{}{
+			"messages": msgs,
+			"has_more": false,
+		}
+
+	case "users.info":
+		id := call.Params.Get("user")
+
+		s.mu.Lock()
+		u, ok := s.users[id]
+		s.mu.Unlock()
+
+		if !ok {
+			u = slack.User{ID: id, Name: id}
+		}
+
+		resp = map[string]interface{}{
+			"user": u,
+		}
+
+	case "usergroups.users.list":
+		s.mu.Lock()
+		members := s.groups[call.Params.Get("usergroup")]
+		s.mu.Unlock()
+
+		if members == nil {
+			members = []string{}
+		}
+
+		resp = map[string]interface{}{
+			"users": members,
+		}
+
+	default:
+		resp = map[string]interface{}{
+			"ok":    false,
+			"error": "unknown_method",
+		}
+	}
+
+	if _, ok := resp["ok"]; !ok {
+		resp["ok"] = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleResponseURL serves a response url, slack responds with a plain ok
+func (s *Server) handleResponseURL(w http.ResponseWriter, r *http.Request) {
+
+	call, err := parseCall(ResponseURLMethod, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.record(call)
+
+	w.Write([]byte("ok"))
+}
+
+// timestamp returns a unique slack message timestamp
+func (s *Server) timestamp() string {
+	return fmt.Sprintf("%d.%06d", time.Now().Unix(), s.next())
+}
+
+// next returns the next value of the fake's sequence
+func (s *Server) next() int {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+
+	return s.seq
+}
+
+// parseCall takes a request to the fake and extracts its token and parameters
+// slack api clients send form encoded values or, for some methods, a json body
+func parseCall(method string, r *http.Request) (Call, error) {
+
+	call := Call{
+		Method: method,
+		Token:  strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "),
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return call, err
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		err = json.Unmarshal(b, &call.JSON)
+		return call, err
+	}
+
+	call.Params, err = url.ParseQuery(string(b))
+	if err != nil {
+		return call, err
+	}
+
+	if t := call.Params.Get("token"); len(t) > 0 {
+		call.Token = t
+	}
+
+	return call, nil
+}
@@ -0,0 +1,135 @@
+package fakeslack
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestServerCalls(t *testing.T) {
+
+	fake := NewServer()
+	defer fake.Close()
+
+	api := slack.New("xoxb-fake", slack.OptionAPIURL(fake.URL()))
+
+	channel, ts, err := api.PostMessage("C123", slack.MsgOptionText("Hello!", false))
+	if err != nil {
+		t.Fatalf("could not post message: %v", err)
+	}
+
+	if channel != "C123" || len(ts) == 0 {
+		t.Errorf("PostMessage() = %q %q, want the channel and a timestamp", channel, ts)
+	}
+
+	fake.SetUser(slack.User{ID: "U123", IsAdmin: true})
+
+	user, err := api.GetUserInfo("U123")
+	if err != nil {
+		t.Fatalf("could not get user: %v", err)
+	}
+
+	if !user.IsAdmin {
+		t.Errorf("GetUserInfo() admin = %t, want the user that was set", user.IsAdmin)
+	}
+
+	_, err = api.GetUserInfo("U456")
+	if err != nil {
+		t.Fatalf("could not get unknown user: %v", err)
+	}
+
+	resp, err := http.Post(fake.ResponseURL(), "application/json", strings.NewReader(`{"text":"Done!"}`))
+	if err != nil {
+		t.Fatalf("could not post to response url: %v", err)
+	}
+	resp.Body.Close()
+
+	calls := fake.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("Calls() = %d calls, want 4", len(calls))
+	}
+
+	post := calls[0]
+	if post.Method != "chat.postMessage" || post.Token != "xoxb-fake" || post.Params.Get("channel") != "C123" || post.Params.Get("text") != "Hello!" {
+		t.Errorf("calls[0] = %+v, want the posted message", post)
+	}
+
+	if n := len(fake.CallsFor("users.info")); n != 2 {
+		t.Errorf("CallsFor(users.info) = %d calls, want 2", n)
+	}
+
+	responses := fake.CallsFor(ResponseURLMethod)
+	if len(responses) != 1 || responses[0].JSON["text"] != "Done!" {
+		t.Errorf("CallsFor(%s) = %+v, want the posted response", ResponseURLMethod, responses)
+	}
+
+	fake.Reset()
+
+	if n := len(fake.Calls()); n != 0 {
+		t.Errorf("Calls() after Reset() = %d calls, want 0", n)
+	}
+}
+
+func TestServerUnknownMethod(t *testing.T) {
+
+	fake := NewServer()
+	defer fake.Close()
+
+	api := slack.New("xoxb-fake", slack.OptionAPIURL(fake.URL()))
+
+	_, err := api.GetChannelInfo("C123")
+	if err == nil || !strings.Contains(err.Error(), "unknown_method") {
+		t.Errorf("GetChannelInfo() err = %v, want unknown_method", err)
+	}
+
+	if calls := fake.CallsFor("channels.info"); len(calls) != 1 || calls[0].Params.Get("channel") != "C123" {
+		t.Errorf("CallsFor(channels.info) = %+v, want the call recorded", calls)
+	}
+}
+
+func TestParseCall(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		contentType string
+		auth        string
+		body        string
+		wantToken   string
+		wantParam   string
+		wantJSON    interface{}
+	}{
+		{name: "form with token", contentType: "application/x-www-form-urlencoded", body: url.Values{"token": {"xoxb-form"}, "channel": {"C1"}}.Encode(), wantToken: "xoxb-form", wantParam: "C1"},
+		{name: "form with bearer", contentType: "application/x-www-form-urlencoded", auth: "Bearer xoxb-header", body: "channel=C1", wantToken: "xoxb-header", wantParam: "C1"},
+		{name: "json", contentType: "application/json; charset=utf-8", auth: "Bearer xoxb-json", body: `{"channel":"C1"}`, wantToken: "xoxb-json", wantJSON: "C1"},
+	}
+
+	for _, test := range tests {
+
+		r, _ := http.NewRequest(http.MethodPost, "/api/chat.postMessage", strings.NewReader(test.body))
+		r.Header.Set("Content-Type", test.contentType)
+
+		if len(test.auth) > 0 {
+			r.Header.Set("Authorization", test.auth)
+		}
+
+		call, err := parseCall("chat.postMessage", r)
+		if err != nil {
+			t.Fatalf("%s: parseCall() err = %v", test.name, err)
+		}
+
+		if call.Token != test.wantToken {
+			t.Errorf("%s: token = %q, want %q", test.name, call.Token, test.wantToken)
+		}
+
+		if call.Params.Get("channel") != test.wantParam {
+			t.Errorf("%s: channel param = %q, want %q", test.name, call.Params.Get("channel"), test.wantParam)
+		}
+
+		if test.wantJSON != nil && call.JSON["channel"] != test.wantJSON {
+			t.Errorf("%s: channel json = %v, want %v", test.name, call.JSON["channel"], test.wantJSON)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package fakeslack
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Signer builds requests signed the way slack signs requests to skelly
+// See: https://api.slack.com/authentication/verifying-requests-from-slack
+type Signer struct {
+	// Secret is the signing secret, the same value as SKELLY_SIGNING_SECRET
+	Secret string
+	// Now returns the signing time, defaults to time.Now
+	Now func() time.Time
+}
+
+// Sign adds the slack timestamp and signature headers for body to the request
+func (s *Signer) Sign(r *http.Request, body []byte) {
+
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	ts := strconv.FormatInt(now().Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write([]byte(fmt.Sprintf("v0:%s:", ts)))
+	mac.Write(body)
+
+	r.Header.Set("X-Slack-Request-Timestamp", ts)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Request builds a signed POST request with the given content type and body
+func (s *Signer) Request(target, contentType string, body []byte) (*http.Request, error) {
+
+	r, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create request")
+	}
+
+	r.Header.Set("Content-Type", contentType)
+
+	s.Sign(r, body)
+
+	return r, nil
+}
+
+// SlashCommand builds a signed slash command request, ex: for /commands
+// values are the form fields slack sends, such as command, text, team_id and trigger_id
+func (s *Signer) SlashCommand(target string, values url.Values) (*http.Request, error) {
+	return s.Request(target, "application/x-www-form-urlencoded", []byte(values.Encode()))
+}
+
+// Event builds a signed events api request, ex: for /events
+// payload is marshaled to json unless it is already a []byte
+func (s *Signer) Event(target string, payload interface{}) (*http.Request, error) {
+
+	b, err := toJSON(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Request(target, "application/json", b)
+}
+
+// Interaction builds a signed interaction request, ex: for /interactions
+// payload is marshaled to json, unless it is already a []byte, and sent as the payload form field
+func (s *Signer) Interaction(target string, payload interface{}) (*http.Request, error) {
+
+	b, err := toJSON(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("payload", string(b))
+
+	return s.Request(target, "application/x-www-form-urlencoded", []byte(values.Encode()))
+}
+
+// toJSON returns payload as json bytes
+func toJSON(payload interface{}) ([]byte, error) {
+
+	if b, ok := payload.([]byte); ok {
+		return b, nil
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal payload")
+	}
+
+	return b, nil
+}
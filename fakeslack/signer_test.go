@@ -0,0 +1,82 @@
+package fakeslack
+
+import (
+	"io/ioutil"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestSigner(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		secret  string
+		now     func() time.Time
+		wantErr bool
+	}{
+		{name: "signed now", secret: "secret"},
+		{name: "signed with another secret", secret: "other", wantErr: true},
+		{name: "signed too long ago", secret: "secret", now: func() time.Time { return time.Now().Add(-time.Hour) }, wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		signer := &Signer{Secret: test.secret, Now: test.now}
+
+		r, err := signer.SlashCommand("http://skelly/commands", url.Values{"text": {"add"}})
+		if err != nil {
+			t.Fatalf("%s: could not build request: %v", test.name, err)
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("%s: could not read body: %v", test.name, err)
+		}
+
+		// verify the request the way the router does
+		verifier, err := slack.NewSecretsVerifier(r.Header, "secret")
+		if err == nil {
+			verifier.Write(body)
+			err = verifier.Ensure()
+		}
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: verify err = %v, wantErr %t", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestSignerRequests(t *testing.T) {
+
+	signer := &Signer{Secret: "secret"}
+
+	event, err := signer.Event("http://skelly/events", []byte(`{"type":"event_callback"}`))
+	if err != nil {
+		t.Fatalf("could not build event: %v", err)
+	}
+
+	body, _ := ioutil.ReadAll(event.Body)
+
+	if event.Header.Get("Content-Type") != "application/json" || string(body) != `{"type":"event_callback"}` {
+		t.Errorf("event = %s %s, want the json body unchanged", event.Header.Get("Content-Type"), body)
+	}
+
+	interaction, err := signer.Interaction("http://skelly/interactions", map[string]string{"type": "block_actions"})
+	if err != nil {
+		t.Fatalf("could not build interaction: %v", err)
+	}
+
+	body, _ = ioutil.ReadAll(interaction.Body)
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		t.Fatalf("could not parse interaction: %v", err)
+	}
+
+	if values.Get("payload") != `{"type":"block_actions"}` {
+		t.Errorf("interaction payload = %s, want the marshaled payload", values.Get("payload"))
+	}
+}
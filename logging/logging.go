@@ -0,0 +1,121 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is the http header used to pass in and echo back the correlation id
+const RequestIDHeader = "X-Request-ID"
+
+// fieldsKey is the context key for storing log fields
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying fields that are added to every
+// log line written with FromContext, merged with any fields already present
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+
+	merged := logrus.Fields{}
+
+	// copy the existing fields to avoid mutating the parent context
+	for k, v := range fieldsFromContext(ctx) {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		// skip empty values to keep log lines short
+		if s, ok := v.(string); ok && len(s) == 0 {
+			continue
+		}
+
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// FromContext returns a log entry including the fields stored in ctx
+// and the trace and span ids of the current span, if one is recording
+func FromContext(ctx context.Context) *logrus.Entry {
+
+	entry := logrus.WithFields(fieldsFromContext(ctx))
+
+	// correlate log lines with traces
+	sc := trace.SpanContextFromContext(ctx)
+	if sc.IsValid() {
+		entry = entry.WithFields(logrus.Fields{
+			"trace_id": sc.TraceID().String(),
+			"span_id":  sc.SpanID().String(),
+		})
+	}
+
+	return entry.WithContext(ctx)
+}
+
+// fieldsFromContext returns the fields stored in ctx, if any
+func fieldsFromContext(ctx context.Context) logrus.Fields {
+
+	if ctx == nil {
+		return logrus.Fields{}
+	}
+
+	fields, ok := ctx.Value(fieldsKey{}).(logrus.Fields)
+	if !ok {
+		return logrus.Fields{}
+	}
+
+	return fields
+}
+
+// NewCorrelationID returns a random id for correlating log lines of a single request
+func NewCorrelationID() string {
+
+	b := make([]byte, 8)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		// fall back to the clock, uniqueness is best effort
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// Middleware returns a gin middleware that assigns a correlation id to every incoming request
+// the id is taken from the X-Request-ID header when present and echoed back in the response
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		// reuse an id assigned upstream, if one exists
+		id := c.GetHeader(RequestIDHeader)
+		if len(id) == 0 {
+			id = NewCorrelationID()
+		}
+
+		c.Header(RequestIDHeader, id)
+
+		ctx := WithFields(c.Request.Context(), logrus.Fields{
+			"correlation_id": id,
+		})
+
+		// pass the fields to the handlers
+		c.Request = c.Request.WithContext(ctx)
+
+		start := time.Now()
+
+		c.Next()
+
+		FromContext(c.Request.Context()).WithFields(logrus.Fields{
+			"method":  c.Request.Method,
+			"path":    c.Request.URL.Path,
+			"status":  c.Writer.Status(),
+			"latency": time.Since(start).String(),
+		}).Debug("handled request")
+	}
+}
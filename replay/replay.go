@@ -0,0 +1,482 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/fakeslack"
+	"github.com/davidvader/skelly/router"
+	"github.com/davidvader/skelly/types"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// CommandPayload is a slash command payload, form encoded
+	CommandPayload = "command"
+	// EventPayload is an events api payload, json encoded
+	EventPayload = "event"
+	// InteractionPayload is an interaction payload, json encoded or form encoded in the payload field
+	InteractionPayload = "interaction"
+)
+
+// quiet is how long no slack api call must be made before an in-process replay is considered handled
+const quiet = 500 * time.Millisecond
+
+// replayToken is the bot token of teams installed for in-process replays, every call goes to the fake slack api
+const replayToken = "xoxb-replay"
+
+// Replay takes a captured slack payload, signs it with secret and submits it to skelly
+// when server is empty the payload is handled in-process against a fake slack api and
+// the mongo db named database, and every slack api call made, waiting at most wait, is printed
+// fixtures is a yaml file of reactions added to that db first, ignored when empty
+func Replay(ctx context.Context, path, kind, server, secret, database, fixtures string, wait time.Duration) error {
+
+	// read the captured payload
+	b, err := read(path)
+	if err != nil {
+		err = errors.Wrap(err, "could not read payload")
+		return err
+	}
+
+	// detect the payload type, if not provided
+	if len(kind) == 0 {
+		kind, err = detect(b)
+		if err != nil {
+			err = errors.Wrap(err, "could not detect payload type")
+			return err
+		}
+	}
+
+	logrus.Infof("replaying %s payload from %s", kind, path)
+
+	// submit to a running server
+	if len(server) > 0 {
+		return remote(ctx, b, kind, server, secret)
+	}
+
+	return local(ctx, b, kind, secret, database, fixtures, wait)
+}
+
+// remote signs the payload and submits it to a running skelly server
+func remote(ctx context.Context, b []byte, kind, server, secret string) error {
+
+	req, err := build(b, kind, strings.TrimSuffix(server, "/"), secret, "")
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		err = errors.Wrap(err, "could not submit payload")
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		err = errors.Wrap(err, "could not read response body")
+		return err
+	}
+
+	fmt.Printf("%s %s\n%s\n", req.URL, resp.Status, body)
+
+	logrus.Info("slack api calls are only printed when replaying in-process, check the server logs")
+
+	return nil
+}
+
+// local signs the payload and handles it in-process, with every
+// slack api call sent to a fake that records it
+// handling the payload writes to the mongo db, so a db other than the configured one is required
+func local(ctx context.Context, b []byte, kind, secret, database, fixtures string, wait time.Duration) error {
+
+	if len(database) == 0 {
+		return errors.New("replaying in-process writes to the mongo db, provide a separate db with --db")
+	}
+
+	if database == db.Database() {
+		return fmt.Errorf("refusing to replay in-process against the configured mongo db(%s), provide a separate db with --db", database)
+	}
+
+	logrus.Infof("replaying against mongo db(%s)", database)
+
+	db.SetDatabase(database)
+
+	// install the payload's team and add the fixtures
+	team, err := payloadTeam(b, kind)
+	if err != nil {
+		return err
+	}
+
+	err = seed(ctx, team, fixtures)
+	if err != nil {
+		return err
+	}
+
+	// start the fake slack api and send all calls to it
+	fake := fakeslack.NewServer()
+	defer fake.Close()
+
+	fake.Install()
+
+	// the router verifies requests using the configured secret
+	if len(secret) > 0 {
+		os.Setenv("SKELLY_SIGNING_SECRET", secret)
+	}
+
+	req, err := build(b, kind, "", os.Getenv("SKELLY_SIGNING_SECRET"), fake.ResponseURL())
+	if err != nil {
+		return err
+	}
+
+	// keep the output limited to the replay
+	gin.SetMode(gin.ReleaseMode)
+
+	// handle the request
+	rec := httptest.NewRecorder()
+	router.New().ServeHTTP(rec, req.WithContext(ctx))
+
+	fmt.Printf("%s %d %s\n%s\n", req.URL.Path, rec.Code, http.StatusText(rec.Code), rec.Body.String())
+
+	// most requests are acknowledged before they are handled, so
+	// wait for the asynchronous handling to finish calling slack
+	calls := fake.Wait(quiet, wait)
+
+	fmt.Printf("%d slack api calls\n", len(calls))
+
+	if len(calls) == 0 {
+		return nil
+	}
+
+	// use yaml as output format
+	output, err := yaml.Marshal(calls)
+	if err != nil {
+		err = errors.Wrap(err, "could not yaml marshal")
+		return err
+	}
+
+	fmt.Println(string(output))
+
+	return nil
+}
+
+// seed takes the team of a replayed payload and prepares the replay db for it
+// the team is installed with a fake bot token unless it already is, so no real token is needed,
+// and the reactions in fixtures replace the reactions of their channels, defaulting to the team
+func seed(ctx context.Context, team, fixtures string) error {
+
+	if len(team) > 0 {
+
+		t, err := db.GetTeam(ctx, team)
+		if err != nil {
+			err = errors.Wrap(err, "could not get team from db")
+			return err
+		}
+
+		if t == nil {
+
+			logrus.Infof("installing team(%s) with a fake bot token", team)
+
+			err = db.SaveTeam(ctx, &types.Team{ID: team, BotToken: replayToken, InstalledAt: time.Now().Unix()})
+			if err != nil {
+				err = errors.Wrap(err, "could not save team to db")
+				return err
+			}
+		}
+	}
+
+	if len(fixtures) == 0 {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(fixtures)
+	if err != nil {
+		err = errors.Wrap(err, "could not read fixtures")
+		return err
+	}
+
+	reactions := []*types.Reaction{}
+
+	err = yaml.Unmarshal(b, &reactions)
+	if err != nil {
+		err = errors.Wrap(err, "could not yaml unmarshal fixtures")
+		return err
+	}
+
+	// clear each channel once, so fixtures for the same channel are all added
+	cleared := map[string]bool{}
+
+	for _, r := range reactions {
+
+		if len(r.Team) == 0 {
+			r.Team = team
+		}
+
+		if !cleared[r.Team+":"+r.Channel] {
+
+			existing, err := db.GetReactions(ctx, r.Team, r.Channel)
+			if err != nil {
+				err = errors.Wrap(err, "could not get reactions from db")
+				return err
+			}
+
+			// deleting fails for channels without reactions
+			if len(existing) > 0 {
+				_, err = db.DeleteReactions(ctx, r.Team, r.Channel)
+				if err != nil {
+					err = errors.Wrap(err, "could not delete reactions from db")
+					return err
+				}
+			}
+
+			cleared[r.Team+":"+r.Channel] = true
+		}
+
+		err = db.AddReaction(ctx, r)
+		if err != nil {
+			err = errors.Wrap(err, "could not add fixture to db")
+			return err
+		}
+	}
+
+	logrus.Infof("added %d reactions from %s", len(reactions), fixtures)
+
+	return nil
+}
+
+// payloadTeam returns the team id of a payload, empty when it has none
+func payloadTeam(b []byte, kind string) (string, error) {
+
+	switch kind {
+	case CommandPayload:
+
+		values, err := commandValues(b)
+		if err != nil {
+			err = errors.Wrap(err, "could not parse slash command")
+			return "", err
+		}
+
+		return values.Get("team_id"), nil
+
+	case InteractionPayload:
+
+		b, err := unwrap(b)
+		if err != nil {
+			return "", err
+		}
+
+		payload := struct {
+			Team struct {
+				ID string `json:"id"`
+			} `json:"team"`
+		}{}
+
+		err = json.Unmarshal(b, &payload)
+		if err != nil {
+			err = errors.Wrap(err, "could not unmarshal payload")
+			return "", err
+		}
+
+		return payload.Team.ID, nil
+	}
+
+	payload := struct {
+		TeamID string `json:"team_id"`
+	}{}
+
+	err := json.Unmarshal(b, &payload)
+	if err != nil {
+		err = errors.Wrap(err, "could not unmarshal payload")
+		return "", err
+	}
+
+	return payload.TeamID, nil
+}
+
+// build creates a signed request for the payload
+// rewrites the verification token and, if provided, the response url
+func build(b []byte, kind, server, secret, responseURL string) (*http.Request, error) {
+
+	signer := &fakeslack.Signer{Secret: secret}
+
+	// the events endpoint verifies the token in the payload
+	vToken := os.Getenv("SKELLY_VERIFICATION_TOKEN")
+
+	switch kind {
+	case CommandPayload:
+
+		values, err := commandValues(b)
+		if err != nil {
+			err = errors.Wrap(err, "could not parse slash command")
+			return nil, err
+		}
+
+		if len(responseURL) > 0 {
+			values.Set("response_url", responseURL)
+		}
+
+		return signer.SlashCommand(server+router.SlackPath("commands"), values)
+
+	case EventPayload:
+
+		payload, err := rewrite(b, vToken, "")
+		if err != nil {
+			return nil, err
+		}
+
+		return signer.Event(server+router.SlackPath("events"), payload)
+
+	case InteractionPayload:
+
+		// unwrap form encoded interactions
+		b, err := unwrap(b)
+		if err != nil {
+			return nil, err
+		}
+
+		payload, err := rewrite(b, "", responseURL)
+		if err != nil {
+			return nil, err
+		}
+
+		return signer.Interaction(server+router.SlackPath("interactions"), payload)
+
+	default:
+		return nil, fmt.Errorf("unsupported payload type(%s)", kind)
+	}
+}
+
+// commandValues returns the form values of a slash command payload
+// captured either form encoded or as json, ex: {"command":"/skelly","text":"list"}
+func commandValues(b []byte) (url.Values, error) {
+
+	body := strings.TrimSpace(string(b))
+
+	if !strings.HasPrefix(body, "{") {
+		return url.ParseQuery(body)
+	}
+
+	fields := map[string]string{}
+
+	err := json.Unmarshal([]byte(body), &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	for k, v := range fields {
+		values.Set(k, v)
+	}
+
+	return values, nil
+}
+
+// rewrite takes a json payload and replaces its token and response url, when provided
+func rewrite(b []byte, token, responseURL string) (map[string]interface{}, error) {
+
+	payload := map[string]interface{}{}
+
+	err := json.Unmarshal(b, &payload)
+	if err != nil {
+		err = errors.Wrap(err, "could not unmarshal payload")
+		return nil, err
+	}
+
+	if len(token) > 0 {
+		payload["token"] = token
+	}
+
+	if _, ok := payload["response_url"]; ok && len(responseURL) > 0 {
+		payload["response_url"] = responseURL
+	}
+
+	return payload, nil
+}
+
+// unwrap returns the json payload of a form encoded interaction, ex: payload=%7B...
+func unwrap(b []byte) ([]byte, error) {
+
+	body := strings.TrimSpace(string(b))
+
+	if !strings.HasPrefix(body, "payload=") {
+		return []byte(body), nil
+	}
+
+	values, err := url.ParseQuery(body)
+	if err != nil {
+		err = errors.Wrap(err, "could not parse interaction")
+		return nil, err
+	}
+
+	return []byte(values.Get("payload")), nil
+}
+
+// detect takes a payload and returns its type based on its encoding and fields
+func detect(b []byte) (string, error) {
+
+	body := strings.TrimSpace(string(b))
+
+	// form encoded payloads
+	if !strings.HasPrefix(body, "{") {
+
+		values, err := url.ParseQuery(body)
+		if err != nil {
+			err = errors.Wrap(err, "could not parse form encoded payload")
+			return "", err
+		}
+
+		switch {
+		case len(values.Get("payload")) > 0:
+			return InteractionPayload, nil
+		case len(values.Get("command")) > 0:
+			return CommandPayload, nil
+		}
+
+		return "", errors.New("form encoded payload has neither a command nor a payload field")
+	}
+
+	// json encoded payloads
+	payload := struct {
+		Type    string `json:"type"`
+		Command string `json:"command"`
+	}{}
+
+	err := json.Unmarshal([]byte(body), &payload)
+	if err != nil {
+		err = errors.Wrap(err, "could not unmarshal payload")
+		return "", err
+	}
+
+	switch {
+	case payload.Type == "event_callback", payload.Type == "url_verification", payload.Type == "app_rate_limited":
+		return EventPayload, nil
+	case len(payload.Command) > 0:
+		return CommandPayload, nil
+	case len(payload.Type) == 0:
+		return "", errors.New("json payload has no type")
+	}
+
+	return InteractionPayload, nil
+}
+
+// read returns the contents of the file at path, or stdin when path is -
+func read(path string) ([]byte, error) {
+
+	if path == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+
+	return ioutil.ReadFile(path)
+}
@@ -0,0 +1,36 @@
+package replay
+
+import "testing"
+
+func TestPayloadTeam(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		kind    string
+		payload string
+		want    string
+		wantErr bool
+	}{
+		{name: "form command", kind: CommandPayload, payload: "command=%2Fskelly&team_id=T123&text=list", want: "T123"},
+		{name: "json command", kind: CommandPayload, payload: `{"command":"/skelly","team_id":"T123"}`, want: "T123"},
+		{name: "event", kind: EventPayload, payload: `{"type":"event_callback","team_id":"T123"}`, want: "T123"},
+		{name: "interaction", kind: InteractionPayload, payload: `{"type":"block_actions","team":{"id":"T123"}}`, want: "T123"},
+		{name: "form interaction", kind: InteractionPayload, payload: "payload=%7B%22team%22%3A%7B%22id%22%3A%22T123%22%7D%7D", want: "T123"},
+		{name: "no team", kind: EventPayload, payload: `{"type":"url_verification"}`, want: ""},
+		{name: "invalid", kind: EventPayload, payload: "team_id=T123", wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		got, err := payloadTeam([]byte(test.payload), test.kind)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: payloadTeam() err = %v, wantErr %t", test.name, err, test.wantErr)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("%s: payloadTeam() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/davidvader/skelly/logging"
 	"github.com/davidvader/skelly/skelly"
+	"github.com/davidvader/skelly/tracing"
 	"github.com/davidvader/skelly/util"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -27,7 +29,7 @@ func commandsHandler(c *gin.Context) {
 	verifier, err := slack.NewSecretsVerifier(r.Header, sSecret)
 	if err != nil {
 		err = errors.Wrap(err, "could not create signing secret verifier")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -40,7 +42,7 @@ func commandsHandler(c *gin.Context) {
 	s, err := slack.SlashCommandParse(r)
 	if err != nil {
 		err = errors.Wrap(err, "could not parse slash command from request")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -49,19 +51,31 @@ func commandsHandler(c *gin.Context) {
 	err = verifier.Ensure()
 	if err != nil {
 		err = errors.Wrap(err, "could not ensure signing secret")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	// attach the slash command to every log line for this request
+	ctx := logging.WithFields(r.Context(), logrus.Fields{
+		"team":       s.TeamID,
+		"channel":    s.ChannelID,
+		"user":       s.UserID,
+		"trigger_id": s.TriggerID,
+	})
+
+	// detach from the request so the command can continue
+	// after the http connection is closed
+	ctx = tracing.Detach(ctx)
+
 	// execute async to allow http connection to close
 	go func() {
 
 		// handle the command
-		err = skelly.HandleSlashCommand(&s)
+		err := skelly.HandleSlashCommand(ctx, &s)
 		if err != nil {
 			err = errors.Wrap(err, "could not execute slash command")
-			logrus.Error(err)
+			logging.FromContext(ctx).Error(err)
 			return
 		}
 	}()
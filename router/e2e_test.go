@@ -0,0 +1,197 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/fakeslack"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	e2eSecret = "e2e-signing-secret"
+	e2eToken  = "e2e-verification-token"
+	e2eTeam   = "TE2E00001"
+	e2eUser   = "UE2E00001"
+	e2eAuthor = "UE2E00002"
+)
+
+// TestEndToEnd adds a reaction with /skelly add and the add modal, then posts a
+// message to the channel and expects skelly to reply in the message's thread
+// requires a reachable mongo db from the SKELLY_MONGO_* configuration, skipped when it is not set
+func TestEndToEnd(t *testing.T) {
+
+	if len(os.Getenv("SKELLY_MONGO_HOST")) == 0 {
+		t.Skip("SKELLY_MONGO_HOST is not set")
+	}
+
+	// a configured mongo db must be reachable, ex: in ci
+	if err := db.Ping(context.Background()); err != nil {
+		t.Fatalf("mongo db is not reachable: %v", err)
+	}
+
+	t.Setenv("SKELLY_SIGNING_SECRET", e2eSecret)
+	t.Setenv("SKELLY_VERIFICATION_TOKEN", e2eToken)
+	t.Setenv("SKELLY_BOT_TOKEN", "xoxb-e2e")
+
+	// let every user manage reactions
+	t.Setenv("SKELLY_ADMINS", "")
+	t.Setenv("SKELLY_ADMIN_GROUPS", "")
+	t.Setenv("SKELLY_CREATOR_ONLY", "")
+
+	gin.SetMode(gin.TestMode)
+
+	// send every slack api call to the fake
+	fake := fakeslack.NewServer()
+	defer fake.Close()
+
+	fake.Install()
+	defer slackapi.SetFactory(func(token string) slackapi.Client { return slackapi.New(token) })
+
+	// use a channel of its own so runs do not collide
+	channel := fmt.Sprintf("CE2E%d", time.Now().UnixNano())
+	defer db.DeleteReactions(context.Background(), e2eTeam, channel)
+
+	signer := &fakeslack.Signer{Secret: e2eSecret}
+	handler := New()
+
+	// /skelly add opens the add modal
+	req, err := signer.SlashCommand(SlackPath("commands"), url.Values{
+		"command":      {"/skelly"},
+		"text":         {"add"},
+		"team_id":      {e2eTeam},
+		"channel_id":   {channel},
+		"user_id":      {e2eUser},
+		"trigger_id":   {"123.456"},
+		"response_url": {fake.ResponseURL()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serve(t, handler, req)
+
+	open := waitForCall(t, fake, "views.open", func(c fakeslack.Call) bool { return true })
+
+	view, ok := open.JSON["view"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("views.open has no view: %v", open.JSON)
+	}
+
+	// submit the modal with a response
+	req, err = signer.Interaction(SlackPath("interactions"), map[string]interface{}{
+		"type": "view_submission",
+		"team": map[string]string{"id": e2eTeam},
+		"user": map[string]string{"id": e2eUser},
+		"view": map[string]interface{}{
+			"callback_id":      view["callback_id"],
+			"private_metadata": view["private_metadata"],
+			"state": map[string]interface{}{
+				"values": map[string]interface{}{
+					"Response": map[string]interface{}{
+						"response": map[string]string{"type": "plain_text_input", "value": "Welcome to the channel!"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := serve(t, handler, req)
+
+	submission := map[string]interface{}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &submission); err != nil {
+		t.Fatalf("could not parse view submission response %q: %v", rec.Body.String(), err)
+	}
+
+	if submission["response_action"] != "update" {
+		t.Fatalf("view submission response_action = %v, want update: %s", submission["response_action"], rec.Body.String())
+	}
+
+	// a message in the channel is answered in its thread
+	ts := "1600000000.000100"
+
+	req, err = signer.Event(SlackPath("events"), map[string]interface{}{
+		"token":      e2eToken,
+		"team_id":    e2eTeam,
+		"type":       "event_callback",
+		"event_id":   "Ev" + channel,
+		"event_time": time.Now().Unix(),
+		"event": map[string]interface{}{
+			"type":         "message",
+			"channel":      channel,
+			"channel_type": "channel",
+			"user":         e2eAuthor,
+			"text":         "hello",
+			"ts":           ts,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serve(t, handler, req)
+
+	post := waitForCall(t, fake, "chat.postMessage", func(c fakeslack.Call) bool {
+		return c.Params.Get("channel") == channel
+	})
+
+	if got := post.Params.Get("thread_ts"); got != ts {
+		t.Errorf("chat.postMessage thread_ts = %q, want %q", got, ts)
+	}
+
+	if got := post.Params.Get("text"); !strings.Contains(got, "Welcome to the channel!") {
+		t.Errorf("chat.postMessage text = %q, want the reaction's response", got)
+	}
+}
+
+// serve takes a request and serves it with handler, failing unless it is accepted
+func serve(t *testing.T, handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s returned %d: %s", req.URL.Path, rec.Code, rec.Body.String())
+	}
+
+	return rec
+}
+
+// waitForCall waits for the fake to receive a call to method matching match
+// slash commands and events are handled asynchronously
+func waitForCall(t *testing.T, fake *fakeslack.Server, method string, match func(fakeslack.Call) bool) fakeslack.Call {
+
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+
+	for time.Now().Before(deadline) {
+
+		for _, c := range fake.CallsFor(method) {
+			if match(c) {
+				return c
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for %s, received: %v", method, fake.Calls())
+
+	return fakeslack.Call{}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/davidvader/skelly/logging"
 	"github.com/davidvader/skelly/skelly"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
@@ -28,7 +29,7 @@ func eventsHandler(c *gin.Context) {
 	verifier, err := slack.NewSecretsVerifier(r.Header, sSecret)
 	if err != nil {
 		err = errors.Wrap(err, "could not create signing secret verifier")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusUnauthorized, err.Error())
 		return
 	}
@@ -40,7 +41,7 @@ func eventsHandler(c *gin.Context) {
 	b, err := c.GetRawData()
 	if err != nil {
 		err = errors.Wrap(err, "could not read body from request")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
 		return
 	}
@@ -58,19 +59,28 @@ func eventsHandler(c *gin.Context) {
 		slackevents.OptionVerifyToken(tokenComparator))
 	if err != nil {
 		err = errors.Wrap(err, "could not parse event from request")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// retrieve the slack secrets from the environment
-	bToken := os.Getenv("SKELLY_BOT_TOKEN")
+	// attach the event to every log line for this request
+	ctx := logging.WithFields(r.Context(), logrus.Fields{
+		"team":  e.TeamID,
+		"event": e.InnerEvent.Type,
+	})
+
+	if cb, ok := e.Data.(*slackevents.EventsAPICallbackEvent); ok {
+		ctx = logging.WithFields(ctx, logrus.Fields{
+			"event_id": cb.EventID,
+		})
+	}
 
 	// handle the event
-	err = skelly.HandleEvent(c, b, &e, bToken)
+	err = skelly.HandleEvent(ctx, c, b, &e)
 	if err != nil {
-		err = errors.Wrap(err, "could not execute slash command")
-		logrus.Error(err)
+		err = errors.Wrap(err, "could not handle event")
+		logging.FromContext(ctx).Error(err)
 		return
 	}
 
@@ -1,13 +1,132 @@
 package router
 
 import (
+	"context"
 	"net/http"
+	"os"
+	"sync"
+	"time"
 
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/types"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
-// healthHandler represents the API handler to
-// report the health status for the application.
-func healthHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, "ok")
+const (
+	// statusOK is the reported status for a healthy dependency
+	statusOK = "ok"
+	// statusFail is the reported status for an unhealthy dependency
+	statusFail = "fail"
+	// slackCheckTTL is how long a slack auth.test result is reused
+	// to avoid calling the slack api on every readiness probe
+	slackCheckTTL = time.Minute
+)
+
+// slackCheck caches the most recent slack auth.test result
+var slackCheck struct {
+	sync.Mutex
+	checked time.Time
+	result  types.HealthCheck
+}
+
+// liveHandler represents the API handler to
+// report the liveness status for the application.
+func liveHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, statusOK)
+}
+
+// readyHandler represents the API handler to
+// report the readiness status for the application
+// and each of its dependencies.
+func readyHandler(c *gin.Context) {
+
+	health := types.Health{
+		Status: statusOK,
+		Checks: map[string]*types.HealthCheck{
+			"mongo": checkMongo(c.Request.Context()),
+		},
+	}
+
+	// multi-workspace deployments resolve tokens per team
+	// so slack is only checked when a single bot token is configured
+	if len(os.Getenv("SKELLY_BOT_TOKEN")) > 0 {
+		health.Checks["slack"] = checkSlack(c.Request.Context())
+	}
+
+	// report not ready if any dependency is failing
+	code := http.StatusOK
+	for name, check := range health.Checks {
+		if check.Status != statusOK {
+			logrus.Warnf("readiness check failed for %s: %s", name, check.Error)
+
+			health.Status = statusFail
+			code = http.StatusServiceUnavailable
+		}
+	}
+
+	c.JSON(code, health)
+}
+
+// checkMongo pings the database and reports its status and latency
+func checkMongo(ctx context.Context) *types.HealthCheck {
+
+	start := time.Now()
+
+	err := db.Ping(ctx)
+
+	return newHealthCheck(start, err)
+}
+
+// checkSlack calls slack auth.test with the bot token and reports its status and latency
+// results are cached for slackCheckTTL
+func checkSlack(ctx context.Context) *types.HealthCheck {
+
+	slackCheck.Lock()
+	defer slackCheck.Unlock()
+
+	// reuse the previous result if it is still fresh
+	if !slackCheck.checked.IsZero() && time.Since(slackCheck.checked) < slackCheckTTL {
+		check := slackCheck.result
+		check.Cached = true
+		return &check
+	}
+
+	start := time.Now()
+
+	// create an api client
+	bToken := os.Getenv("SKELLY_BOT_TOKEN")
+
+	api := slackapi.For(bToken)
+
+	// verify the bot token
+	_, err := api.AuthTest(ctx)
+	if err != nil {
+		err = errors.Wrap(err, "could not auth test")
+	}
+
+	check := newHealthCheck(start, err)
+
+	slackCheck.checked = time.Now()
+	slackCheck.result = *check
+
+	return check
+}
+
+// newHealthCheck takes the start time and result of a dependency check and builds its status
+func newHealthCheck(start time.Time, err error) *types.HealthCheck {
+
+	check := &types.HealthCheck{
+		Status:  statusOK,
+		Latency: time.Since(start).String(),
+	}
+
+	if err != nil {
+		check.Status = statusFail
+		check.Error = err.Error()
+	}
+
+	return check
 }
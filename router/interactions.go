@@ -4,11 +4,11 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/davidvader/skelly/logging"
 	"github.com/davidvader/skelly/skelly"
 	"github.com/davidvader/skelly/util"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 )
 
@@ -26,7 +26,7 @@ func interactionsHandler(c *gin.Context) {
 	s, err := slack.NewSecretsVerifier(r.Header, sSecret)
 	if err != nil {
 		err = errors.Wrap(err, "could not create signing secret verifier")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
 		return
 	}
@@ -35,7 +35,7 @@ func interactionsHandler(c *gin.Context) {
 	b, err := c.GetRawData()
 	if err != nil {
 		err = errors.Wrap(err, "could not read body from request")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
 		return
 	}
@@ -45,7 +45,7 @@ func interactionsHandler(c *gin.Context) {
 	err = s.Ensure()
 	if err != nil {
 		err = errors.Wrap(err, "could not ensure signing secret")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
 		return
 	}
@@ -54,16 +54,16 @@ func interactionsHandler(c *gin.Context) {
 	body, err := util.ParsePayload(b)
 	if err != nil {
 		err = errors.Wrap(err, "could not parse payload")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
 		return
 	}
 
 	// Handle the interaction
-	err = skelly.HandleInteraction(c, body)
+	err = skelly.HandleInteraction(r.Context(), c, body)
 	if err != nil {
 		err = errors.Wrap(err, "could not handle interaction")
-		logrus.Error(err)
+		logging.FromContext(r.Context()).Error(err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
 		return
 	}
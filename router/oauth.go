@@ -0,0 +1,202 @@
+package router
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// authorizeURL is the slack endpoint that starts the oauth v2 install flow
+	authorizeURL = "https://slack.com/oauth/v2/authorize"
+	// defaultScopes are the bot scopes requested when SKELLY_OAUTH_SCOPES is not set
+	defaultScopes = "commands,chat:write,channels:history,groups:history,channels:read,groups:read,app_mentions:read,reactions:read,users:read,usergroups:read"
+	// stateTTL is how long an install link remains valid
+	stateTTL = 10 * time.Minute
+	// stateCookie binds the oauth state to the browser that started the install
+	stateCookie = "skelly_oauth_state"
+	// stateCookiePath limits the state cookie to the oauth endpoints
+	stateCookiePath = "/slack/oauth"
+)
+
+// installHandler represents the API handler for starting the slack oauth v2 install flow
+// redirects the user to slack to approve installing skelly in their workspace
+func installHandler(c *gin.Context) {
+
+	// retrieve the slack app credentials from the environment
+	clientID := os.Getenv("SKELLY_CLIENT_ID")
+	clientSecret := os.Getenv("SKELLY_CLIENT_SECRET")
+
+	if len(clientID) == 0 || len(clientSecret) == 0 {
+		err := errors.New("oauth install is not configured")
+		logging.FromContext(c.Request.Context()).Error(err)
+		c.AbortWithStatusJSON(http.StatusNotFound, err.Error())
+		return
+	}
+
+	scopes := os.Getenv("SKELLY_OAUTH_SCOPES")
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+
+	state, err := newState(clientSecret, time.Now())
+	if err != nil {
+		err = errors.Wrap(err, "could not create oauth state")
+		logging.FromContext(c.Request.Context()).Error(err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// remember the state in the browser, the callback must come from the same browser
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(stateCookie, state, int(stateTTL.Seconds()), stateCookiePath, "", c.Request.TLS != nil, true)
+
+	// build the authorize url
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("scope", scopes)
+	params.Set("state", state)
+
+	if redirectURL := os.Getenv("SKELLY_REDIRECT_URL"); len(redirectURL) > 0 {
+		params.Set("redirect_uri", redirectURL)
+	}
+
+	c.Redirect(http.StatusFound, authorizeURL+"?"+params.Encode())
+}
+
+// oauthCallbackHandler represents the API handler for completing the slack oauth v2 install flow
+// exchanges the temporary code for a bot token and stores it for the installing team
+func oauthCallbackHandler(c *gin.Context) {
+
+	ctx := c.Request.Context()
+
+	// retrieve the slack app credentials from the environment
+	clientID := os.Getenv("SKELLY_CLIENT_ID")
+	clientSecret := os.Getenv("SKELLY_CLIENT_SECRET")
+
+	// the user cancelled or slack rejected the install
+	if e := c.Query("error"); len(e) > 0 {
+		err := fmt.Errorf("install was not approved: %s", e)
+		logging.FromContext(ctx).Warn(err)
+		c.AbortWithStatusJSON(http.StatusForbidden, err.Error())
+		return
+	}
+
+	// the state is only used once
+	cookie, _ := c.Cookie(stateCookie)
+	c.SetCookie(stateCookie, "", -1, stateCookiePath, "", c.Request.TLS != nil, true)
+
+	// verify the install was started by this server, in this browser
+	err := verifyState(clientSecret, c.Query("state"), cookie, time.Now())
+	if err != nil {
+		err = errors.Wrap(err, "could not verify oauth state")
+		logging.FromContext(ctx).Error(err)
+		c.AbortWithStatusJSON(http.StatusForbidden, err.Error())
+		return
+	}
+
+	// exchange the code for a bot token
+	resp, err := slack.GetOAuthV2ResponseContext(ctx, tracing.HTTPClient(),
+		clientID, clientSecret, c.Query("code"), os.Getenv("SKELLY_REDIRECT_URL"))
+	if err != nil {
+		err = errors.Wrap(err, "could not exchange oauth code")
+		logging.FromContext(ctx).Error(err)
+		c.AbortWithStatusJSON(http.StatusBadGateway, err.Error())
+		return
+	}
+
+	team := &types.Team{
+		ID:          resp.Team.ID,
+		Name:        resp.Team.Name,
+		BotToken:    resp.AccessToken,
+		BotUserID:   resp.BotUserID,
+		Scope:       resp.Scope,
+		InstalledBy: resp.AuthedUser.ID,
+		InstalledAt: time.Now().Unix(),
+	}
+
+	// store the token for the team
+	err = db.SaveTeam(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not save team")
+		logging.FromContext(ctx).Error(err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logging.FromContext(ctx).Infof("installed for team(%s) by user(%s)", team.ID, team.InstalledBy)
+
+	c.String(http.StatusOK, fmt.Sprintf("Skelly has been installed in %s! Invite @skelly to a channel to get started.", team.Name))
+}
+
+// newState returns an oauth state parameter containing the current time and a random nonce
+// signed with the client secret, ex: 1600000000.0123456789abcdef.abcdef
+func newState(secret string, now time.Time) (string, error) {
+
+	nonce := make([]byte, 16)
+
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	value := strconv.FormatInt(now.Unix(), 10) + "." + hex.EncodeToString(nonce)
+
+	return value + "." + signState(secret, value), nil
+}
+
+// verifyState takes an oauth state parameter and verifies it matches the state cookie
+// of the browser that started the install, was signed with the client secret and has not expired
+func verifyState(secret, state, cookie string, now time.Time) error {
+
+	// compare the state to the browser's in constant time
+	if len(cookie) == 0 || !hmac.Equal([]byte(state), []byte(cookie)) {
+		return errors.New("state does not match the browser that started the install")
+	}
+
+	parts := strings.Split(state, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid state(%s)", state)
+	}
+
+	// compare signatures in constant time
+	if !hmac.Equal([]byte(parts[2]), []byte(signState(secret, parts[0]+"."+parts[1]))) {
+		return errors.New("state signature does not match")
+	}
+
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return errors.Wrap(err, "could not parse state timestamp")
+	}
+
+	if now.Sub(time.Unix(ts, 0)) > stateTTL {
+		return errors.New("state has expired")
+	}
+
+	return nil
+}
+
+// signState returns the hex encoded hmac sha256 of value using secret
+func signState(secret, value string) string {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -6,6 +6,9 @@ import (
 	"os"
 	"strings"
 
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/skelly"
+	"github.com/davidvader/skelly/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/tomb.v2"
@@ -14,23 +17,10 @@ import (
 // Run executes router to serve http for the application
 func Run(port string) error {
 
-	// router configurations
-	router := gin.New()
-	router.Use(gin.Recovery())
-
-	// health endpoint
-	router.GET("/health", healthHandler)
-
-	// commands endpoint
-	router.POST(slackRouterPrefix("commands"), commandsHandler)
-
-	// events endpoint
-	router.POST(slackRouterPrefix("events"), eventsHandler)
-
-	// interactions endpoint
-	router.POST(slackRouterPrefix("interactions"), interactionsHandler)
+	router := New()
 
 	var tomb tomb.Tomb
+
 	// start http server
 	tomb.Go(func() error {
 		srv := &http.Server{Addr: ":" + port, Handler: router}
@@ -52,14 +42,50 @@ func Run(port string) error {
 		}
 	})
 
+	// start the announcement scheduler
+	tomb.Go(func() error {
+		return skelly.RunScheduler(tomb.Context(context.Background()))
+	})
+
 	// watch for errors and terminate safely
 	tomb.Wait()
 
 	return tomb.Err()
 }
 
-// slackRouterPrefix returns appropriate an optional router prefix
-func slackRouterPrefix(endpoint string) string {
+// New returns the http handler serving the health, oauth and slack endpoints
+func New() *gin.Engine {
+
+	// router configurations
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(tracing.Middleware())
+	router.Use(logging.Middleware())
+
+	// health endpoints
+	// /health is kept as an alias of /health/live
+	router.GET("/health", liveHandler)
+	router.GET("/health/live", liveHandler)
+	router.GET("/health/ready", readyHandler)
+
+	// oauth install endpoints
+	router.GET("/slack/install", installHandler)
+	router.GET("/slack/oauth/callback", oauthCallbackHandler)
+
+	// commands endpoint
+	router.POST(SlackPath("commands"), commandsHandler)
+
+	// events endpoint
+	router.POST(SlackPath("events"), eventsHandler)
+
+	// interactions endpoint
+	router.POST(SlackPath("interactions"), interactionsHandler)
+
+	return router
+}
+
+// SlackPath takes a slack endpoint and returns its path, with the optional router prefix
+func SlackPath(endpoint string) string {
 
 	// grab prefix from the env
 	prefix := strings.ToLower(os.Getenv("slack_router_prefix"))
@@ -1,65 +1,81 @@
 package skelly
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"strings"
 
 	"github.com/davidvader/skelly/db"
-	"github.com/davidvader/skelly/util"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/types"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 )
 
 // openAddModal takes slash command configuration and responds
 // to the triggering user with a dialog window for adding a new
 // reaction to the skelly database
-func openAddModal(s *slack.SlashCommand, command string, args []string) error {
+func openAddModal(ctx context.Context, s *slack.SlashCommand, command string, args []string) error {
+	return showAddModal(ctx, s.TeamID, s.ChannelID, s.ChannelID, s.UserID, s.TriggerID, "")
+}
 
-	channel := s.ChannelID
-	user := s.UserID
-	triggerID := s.TriggerID
+// showAddModal opens the modal for adding a reaction to channel, selected by default
+// errors are sent to the user in origin, the channel or direct message the modal was opened from
+func showAddModal(ctx context.Context, team, channel, origin, user, triggerID, response string) error {
 
-	// attempt to retrieve an existing reaction
-	exists, _, err := db.ReactionExists(channel)
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
 	if err != nil {
-		err = errors.Wrap(err, "could not check for reaction")
+		err = errors.Wrap(err, "could not get bot token")
 		return err
 	}
 
-	// if reaction exists
-	if exists {
-
-		logrus.Infof("reaction already exists for channel(%s)", channel)
+	// the modal adds reactions to messages
+	reaction := &types.Reaction{Channel: channel}
 
-		// notify user
-		err = util.SendError("Sorry, a typing reaction already exists for this channel. Did you mean to update?", channel, user)
+	// attempt to retrieve an existing reaction, unless opened from a direct message
+	exists := false
+	if !isDirectMessage(channel) {
+		exists, _, err = db.ReactionExists(ctx, team, channel, reaction.Key())
 		if err != nil {
-			err = errors.Wrap(err, "could not send error")
+			err = errors.Wrap(err, "could not check for reaction")
 			return err
 		}
+	}
 
-		return nil
+	// if reaction exists
+	if exists {
+
+		logging.FromContext(ctx).Infof("reaction already exists for channel(%s) trigger(%s)", channel, reaction.Key())
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, a reaction to %s already exists for <#%s>. Did you mean to update? Use `/skelly add` with `--trigger` or `--faq` to add another kind of reaction.", describeEvent(reaction), channel), origin, user)
 	}
 
 	// build default modal
-	// uses channel and slash command as metadata
-	metadata := strings.Join([]string{addSubCommand, channel}, " ")
+	// uses signed subcommand, team, channel and user as metadata
+	metadata, err := encodeViewMetadata(&viewMetadata{
+		SubCommand: addSubCommand,
+		Team:       team,
+		Channel:    channel,
+		User:       user,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "could not encode metadata")
+		return err
+	}
 
 	modal := modal(addSubCommand,
-		"Add a reaction to this channel.",
-		metadata, "")
+		"Add a reaction to a channel.",
+		metadata, channel, response, "")
 
-	logrus.Infof("opening add modal for channel(%s) trigger_id(%s)", channel, triggerID)
+	logging.FromContext(ctx).Debugf("opening add modal for channel(%s) trigger_id(%s)", channel, triggerID)
 
 	// create an api client
-	bToken := os.Getenv("SKELLY_BOT_TOKEN")
-
-	api := slack.New(bToken)
+	api := slackapi.For(bToken)
 
 	// open modal view
-	_, err = api.OpenView(triggerID, modal)
+	_, err = api.OpenView(ctx, triggerID, modal)
 	if err != nil {
 		err = errors.Wrap(err, "could not open view")
 		return err
@@ -68,73 +84,253 @@ func openAddModal(s *slack.SlashCommand, command string, args []string) error {
 }
 
 // handleAddSubmission takes slack view, extracts args, and attempts to add a reaction to the database
-func handleAddSubmission(view *slack.View, user, responseURL string) error {
+// invalid input is returned as errors to show in the modal, otherwise a confirmation replaces the modal
+func handleAddSubmission(ctx context.Context, view *slack.View, team, user string) (*slack.ViewSubmissionResponse, error) {
 
 	// parse submission value
 	response, err := parseViewResponse(view)
 	if err != nil {
 		err = errors.Wrap(err, "could not parse response")
-		return err
+		return nil, err
 	}
 
-	// parse out args from private metadata
-	// ex: META:CHANNEL_ID
-	channel, err := parseViewMetadata(view)
+	// parse and verify the private metadata
+	metadata, err := parseViewMetadata(view, team, user)
 	if err != nil {
 		err = errors.Wrap(err, "could not parse metadata")
-		return err
+		return nil, err
+	}
+
+	// parse the selected channel, defaulting to the channel the modal was opened for
+	channel, err := parseViewChannel(view, metadata.Channel)
+	if err != nil {
+		err = errors.Wrap(err, "could not parse channel")
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debugf("parsed metadata channel(%s) selected channel(%s)", metadata.Channel, channel)
+
+	// validate input, keeping the modal open with the errors
+	invalid := map[string]string{}
+
+	// reactions belong to channels, not direct messages
+	if isDirectMessage(channel) {
+		invalid["Channel"] = "Reactions can only be managed for channels. Please select a channel."
+	}
+
+	err = validateResponse(response)
+	if err != nil {
+		invalid["Response"] = err.Error()
+	}
+
+	locales, err := parseViewLocales(view)
+	if err != nil {
+		invalid["Locales"] = err.Error()
+	}
+
+	if len(invalid) > 0 {
+		logging.FromContext(ctx).Debugf("invalid add submission for channel(%s): %v", channel, invalid)
+		return slack.NewErrorsViewSubmissionResponse(invalid), nil
 	}
 
-	logrus.Infof("parsed metadata channel(%s)", channel)
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return nil, err
+	}
+
+	// check that the user may still manage reactions in the channel
+	allowed, err := authorize(ctx, bToken, team, channel, user, addSubCommand)
+	if err != nil {
+		err = errors.Wrap(err, "could not authorize user")
+		return nil, err
+	}
+
+	if !allowed {
+
+		logging.FromContext(ctx).Infof("user(%s) is not allowed to add reactions in channel(%s)", user, channel)
+
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			"Channel": "You are not allowed to add reactions in this channel. Ask a workspace admin or a skelly admin for help.",
+		}), nil
+	}
+
+	reaction := &types.Reaction{
+		Team:      team,
+		Channel:   channel,
+		CreatedBy: user,
+		Response:  response,
+		Cooldown:  defaultCooldown,
+		Locales:   locales,
+	}
 
-	// check for reaction in the database
-	exists, _, err := db.ReactionExists(channel)
+	// check for reaction to the same trigger in the database
+	exists, _, err := db.ReactionExists(ctx, team, channel, reaction.Key())
 	if err != nil {
 		err = errors.Wrap(err, "could not check for reaction in db")
-		return err
+		return nil, err
 	}
 
 	if exists {
 
-		logrus.Infof("reaction already exists for channel(%s)", channel)
+		logging.FromContext(ctx).Infof("reaction already exists for channel(%s) trigger(%s)", channel, reaction.Key())
+
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			"Channel": fmt.Sprintf("A reaction to %s already exists for this channel. Did you mean to update?", describeEvent(reaction)),
+		}), nil
+	}
+
+	// add reaction to the database
+	err = db.AddReaction(ctx, reaction)
+	if err != nil {
+		err = errors.Wrap(err, "could not add reaction to db")
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Infof("reaction added for channel(%s)", channel)
+
+	recordAudit(ctx, team, channel, reaction.ID.Hex(), user, auditAdd, sourceModal, nil, auditSnapshot(reaction))
+
+	// show the confirmation
+	return slack.NewUpdateViewSubmissionResponse(confirmationModal(addSubCommand,
+		fmt.Sprintf("Okay, I will respond to %s.", describeReaction(reaction)))), nil
+}
+
+// addInline takes slash command configuration and arguments and adds
+// a reaction to the skelly database without opening a modal
+// ex: /skelly add "Welcome!" --cooldown 1d --users @a @b
+func addInline(ctx context.Context, s *slack.SlashCommand, args []string) error {
+
+	team := s.TeamID
+	channel := s.ChannelID
+	user := s.UserID
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	// inline reactions apply to the current channel
+	if isDirectMessage(channel) {
 
 		// notify user
-		err = util.SendError("Sorry, that reaction already exists. Did you mean to update?", channel, user)
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, run this in the channel the reaction is for, or use `%s %s` to pick a channel.", s.Command, addSubCommand), channel, user)
+	}
+
+	// parse and validate input
+	parsed, err := parseReactionArgs(args)
+	if err == nil && len(parsed.Response) == 0 {
+		err = errors.New("a response is required, ex: /skelly add \"Welcome!\"")
+	}
+
+	if err != nil {
+
+		logging.FromContext(ctx).Infof("invalid add arguments for channel(%s): %v", channel, err)
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
+	}
+
+	// validate the response, if provided
+	if len(parsed.Response) > 0 {
+
+		err = validateResponse(parsed.Response)
 		if err != nil {
-			err = errors.Wrap(err, "could not send error")
-			return err
+
+			logging.FromContext(ctx).Infof("invalid response for channel(%s): %v", channel, err)
+
+			// notify user
+			return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, that response cannot be used. %s", err), channel, user)
 		}
+	}
+
+	// limit when the reaction is active, if provided
+	schedule, err := applySchedule(nil, &parsed.Schedule)
+	if err != nil {
 
-		return nil
+		logging.FromContext(ctx).Infof("invalid schedule for channel(%s): %v", channel, err)
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
 	}
 
-	// add reaction to the database
-	err = db.AddReaction(channel, response)
+	reaction := &types.Reaction{
+		Team:      team,
+		Channel:   channel,
+		CreatedBy: user,
+		Response:  parsed.Response,
+		Cooldown:  defaultCooldown,
+		Users:     parsed.Users,
+		Schedule:  schedule,
+	}
+
+	if parsed.Cooldown != nil {
+		reaction.Cooldown = *parsed.Cooldown
+	}
+
+	// add the other responses, if provided
+	err = applyVariants(reaction, &parsed.Variants)
 	if err != nil {
-		err = errors.Wrap(err, "could not add reaction to db")
+
+		logging.FromContext(ctx).Infof("invalid variants for channel(%s): %v", channel, err)
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
+	}
+
+	// set what the reaction responds to, if provided
+	err = applyTrigger(reaction, &parsed.Trigger)
+	if err != nil {
+
+		logging.FromContext(ctx).Infof("invalid trigger for channel(%s): %v", channel, err)
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
+	}
+
+	// set the questions the reaction answers, if provided
+	err = applyFAQ(reaction, &parsed.FAQ)
+	if err != nil {
+
+		logging.FromContext(ctx).Infof("invalid faq for channel(%s): %v", channel, err)
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
+	}
+
+	// check for reaction to the same trigger in the database, faq reactions may share a trigger
+	exists, _, err := db.ReactionExists(ctx, team, channel, reaction.Key())
+	if err != nil {
+		err = errors.Wrap(err, "could not check for reaction in db")
 		return err
 	}
 
-	// set response
-	var text *slack.TextBlockObject = slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("Okay, I will respond to all users that type in this channel, once a day."), false, false)
+	if exists {
 
-	section := slack.NewSectionBlock(text, nil, nil)
+		logging.FromContext(ctx).Infof("reaction already exists for channel(%s) trigger(%s)", channel, reaction.Key())
 
-	// create default msg options
-	options := []slack.MsgOption{
-		slack.MsgOptionBlocks(section),
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, a reaction to %s already exists for this channel. Did you mean to update?", describeEvent(reaction)), channel, user)
 	}
 
-	// create an api client
-	bToken := os.Getenv("SKELLY_BOT_TOKEN")
+	// add reaction to the database
+	err = db.AddReaction(ctx, reaction)
+	if err != nil {
+		err = errors.Wrap(err, "could not add reaction to db")
+		return err
+	}
 
-	api := slack.New(bToken)
+	recordAudit(ctx, team, channel, reaction.ID.Hex(), user, auditAdd, sourceSlash, nil, auditSnapshot(reaction))
 
 	// post the confirmation
-	_, err = api.PostEphemeral(channel, user, options...)
+	err = postConfirmation(ctx, bToken, fmt.Sprintf("Okay, I will respond to %s.", describeReaction(reaction)), channel, user)
 	if err != nil {
-		err = errors.Wrap(err, "could not post response")
+		err = errors.Wrap(err, "could not post confirmation")
 		return err
 	}
+
 	return nil
 }
@@ -0,0 +1,393 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// announceSubCommand manages announcements, ex: /skelly announce list
+	announceSubCommand = "announce"
+
+	// announceInterval is how often the scheduler checks for due announcements
+	announceInterval = 30 * time.Second
+	// announceGrace is how late an announcement may run, older runs missed
+	// while skelly was down are skipped rather than posted out of time
+	announceGrace = 10 * time.Minute
+)
+
+// announceArgs is the parsed form of inline arguments for adding an announcement
+// ex: /skelly announce "Standup in 5 minutes!" --cron "55 8 * * mon-fri" --tz America/Chicago
+type announceArgs struct {
+	// Response is the message to post
+	Response string
+	// Cron is the cron spec for when to post
+	Cron string
+	// TimeZone is the location the cron spec is in, empty for UTC
+	TimeZone string
+}
+
+// RunScheduler posts announcements as they come due until ctx is done.
+// runs are claimed in the db so only one replica posts each announcement.
+func RunScheduler(ctx context.Context) error {
+
+	logging.FromContext(ctx).Info("starting announcement scheduler...")
+
+	ticker := time.NewTicker(announceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logging.FromContext(ctx).Info("stopping announcement scheduler...")
+			return nil
+
+		case now := <-ticker.C:
+
+			err := runAnnouncements(ctx, now)
+			if err != nil {
+				logging.FromContext(ctx).Errorf("could not run announcements: %v", err)
+			}
+		}
+	}
+}
+
+// runAnnouncements takes the current time and posts every announcement due by then
+func runAnnouncements(ctx context.Context, now time.Time) error {
+
+	ctx, span := tracing.Start(ctx, "skelly.runAnnouncements")
+	defer span.End()
+
+	// retrieve the announcements that are due
+	announcements, err := db.GetDueAnnouncements(ctx, now.Unix())
+	if err != nil {
+		err = errors.Wrap(err, "could not get due announcements")
+		return tracing.Error(span, err)
+	}
+
+	for i := range announcements {
+
+		a := &announcements[i]
+
+		// schedule the following run
+		next, err := nextAnnouncementRun(a.Cron, a.TimeZone, now)
+		if err != nil {
+			logging.FromContext(ctx).Errorf("could not schedule announcement(%s): %v", a.ID.Hex(), err)
+			continue
+		}
+
+		// claim this run, another replica may have already
+		claimed, err := db.ClaimAnnouncement(ctx, a, next, now.Unix())
+		if err != nil {
+			logging.FromContext(ctx).Errorf("could not claim announcement(%s): %v", a.ID.Hex(), err)
+			continue
+		}
+
+		if !claimed {
+			logging.FromContext(ctx).Debugf("skipping, announcement(%s) was claimed by another replica", a.ID.Hex())
+			continue
+		}
+
+		// skip runs missed while skelly was down
+		if now.Sub(time.Unix(a.NextRun, 0)) > announceGrace {
+			logging.FromContext(ctx).Infof("skipping, announcement(%s) missed its run at(%d)", a.ID.Hex(), a.NextRun)
+			continue
+		}
+
+		err = announce(ctx, a)
+		if err != nil {
+			logging.FromContext(ctx).Errorf("could not post announcement(%s): %v", a.ID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+// announce takes an announcement and posts it to its channel
+func announce(ctx context.Context, a *types.Announcement) error {
+
+	ctx, span := tracing.Start(ctx, "skelly.announce",
+		attribute.String("skelly.team", a.Team),
+		attribute.String("skelly.channel", a.Channel),
+		attribute.String("skelly.announcement", a.ID.Hex()))
+	defer span.End()
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, a.Team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return tracing.Error(span, err)
+	}
+
+	// create an api client
+	api := slackapi.For(bToken)
+
+	// post the announcement, {{.User}} mentions its creator
+	mts, err := postResponse(ctx, api, a.Response, a.CreatedBy, a.Channel, "none", false)
+	if err != nil {
+		err = errors.Wrap(err, "could not post response")
+		return tracing.Error(span, err)
+	}
+
+	logging.FromContext(ctx).Infof("announcement(%s) posted for channel(%s) at msg_ts(%s)", a.ID.Hex(), a.Channel, mts)
+
+	return nil
+}
+
+// nextAnnouncementRun takes a cron spec and time zone and returns the unix time of the first run after now
+func nextAnnouncementRun(cron, timeZone string, now time.Time) (int64, error) {
+
+	spec, err := parseCron(cron)
+	if err != nil {
+		return 0, err
+	}
+
+	loc := time.UTC
+	if len(timeZone) > 0 {
+
+		loc, err = time.LoadLocation(timeZone)
+		if err != nil {
+			return 0, fmt.Errorf("unknown time zone %s, ex: America/Chicago", timeZone)
+		}
+	}
+
+	next := spec.next(now.In(loc))
+	if next.IsZero() {
+		return 0, fmt.Errorf("cron %s never runs", cron)
+	}
+
+	return next.Unix(), nil
+}
+
+// newAnnouncement takes an announcement's fields, validates them and schedules its first run
+// returns an error meant for showing to the user if the announcement cannot be used
+func newAnnouncement(team, channel, user, response, cron, timeZone string) (*types.Announcement, error) {
+
+	err := validateResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := nextAnnouncementRun(cron, timeZone, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Announcement{
+		Team:      team,
+		Channel:   channel,
+		CreatedBy: user,
+		Response:  response,
+		Cron:      cron,
+		TimeZone:  timeZone,
+		NextRun:   next,
+	}, nil
+}
+
+// parseAnnounceArgs takes the arguments following announce and parses them
+// positional arguments are joined as the response and flags may appear in any order
+func parseAnnounceArgs(args []string) (*announceArgs, error) {
+
+	parsed := new(announceArgs)
+	response := []string{}
+
+	for i := 0; i < len(args); i++ {
+
+		arg := args[i]
+
+		switch strings.ToLower(arg) {
+		case "--cron", "--tz":
+
+			flag := strings.ToLower(arg)
+
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value, ex: --cron \"0 9 * * mon-fri\" --tz America/Chicago", flag)
+			}
+			i++
+
+			if flag == "--cron" {
+				parsed.Cron = args[i]
+			} else {
+				parsed.TimeZone = args[i]
+			}
+
+		default:
+
+			if strings.HasPrefix(arg, "--") {
+				return nil, fmt.Errorf("unknown flag %s", arg)
+			}
+
+			response = append(response, arg)
+		}
+	}
+
+	parsed.Response = strings.Join(response, " ")
+
+	if len(parsed.Response) == 0 {
+		return nil, errors.New("a message is required, ex: /skelly announce \"Standup!\" --cron \"0 9 * * mon-fri\"")
+	}
+
+	if len(parsed.Cron) == 0 {
+		return nil, errors.New("a schedule is required, ex: --cron \"0 9 * * mon-fri\"")
+	}
+
+	return parsed, nil
+}
+
+// handleAnnounce takes slash command configuration and arguments and adds, lists or deletes announcements
+// ex: /skelly announce "Standup!" --cron "0 9 * * mon-fri"
+// ex: /skelly announce list
+// ex: /skelly announce delete 5f1b...
+func handleAnnounce(ctx context.Context, s *slack.SlashCommand, args []string) error {
+
+	team := s.TeamID
+	channel := s.ChannelID
+	user := s.UserID
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	// announcements belong to channels, not direct messages
+	if isDirectMessage(channel) {
+
+		// notify user
+		return notifyUser(ctx, bToken, "Sorry, run this in the channel the announcement is for.", channel, user)
+	}
+
+	// listing is open to everyone, adding and deleting require permission
+	listing := len(args) == 0 || (len(args) == 1 && strings.ToLower(args[0]) == listSubCommand)
+
+	if !listing {
+
+		allowed, err := checkPermission(ctx, team, channel, channel, user, announceSubCommand)
+		if err != nil {
+			err = errors.Wrap(err, "could not check permission")
+			return err
+		}
+
+		if !allowed {
+			return nil
+		}
+	}
+
+	text := ""
+
+	switch {
+	// /skelly announce list
+	case listing:
+
+		announcements, err := db.GetAnnouncements(ctx, team, channel)
+		if err != nil {
+			err = errors.Wrap(err, "could not get announcements")
+			return err
+		}
+
+		text = describeAnnouncements(announcements)
+
+	// /skelly announce delete <id>
+	case len(args) == 2 && strings.ToLower(args[0]) == deleteSubCommand:
+
+		a, err := db.GetAnnouncement(ctx, args[1])
+		if err != nil {
+			err = errors.Wrap(err, "could not get announcement")
+			return err
+		}
+
+		if a == nil || a.Team != team || a.Channel != channel {
+			text = fmt.Sprintf("Sorry, announcement `%s` does not exist in this channel. Use `%s %s list` to see them.", args[1], s.Command, announceSubCommand)
+			break
+		}
+
+		err = db.DeleteAnnouncement(ctx, a)
+		if err != nil {
+			err = errors.Wrap(err, "could not delete announcement")
+			return err
+		}
+
+		text = fmt.Sprintf("Okay, I've deleted announcement `%s`.", a.ID.Hex())
+
+	// /skelly announce "message" --cron "spec"
+	default:
+
+		parsed, err := parseAnnounceArgs(args)
+		if err == nil {
+
+			var a *types.Announcement
+
+			a, err = newAnnouncement(team, channel, user, parsed.Response, parsed.Cron, parsed.TimeZone)
+			if err == nil {
+
+				err = db.AddAnnouncement(ctx, a)
+				if err != nil {
+					err = errors.Wrap(err, "could not add announcement")
+					return err
+				}
+
+				text = fmt.Sprintf("Okay, I will post this in <#%s> %s, starting %s.", channel, describeCron(a), describeRun(a.NextRun))
+				break
+			}
+		}
+
+		logging.FromContext(ctx).Infof("invalid announcement for channel(%s): %v", channel, err)
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
+	}
+
+	// post the confirmation
+	err = postConfirmation(ctx, bToken, text, channel, user)
+	if err != nil {
+		err = errors.Wrap(err, "could not post confirmation")
+		return err
+	}
+
+	return nil
+}
+
+// describeAnnouncements takes announcements and describes them for listing
+func describeAnnouncements(announcements []types.Announcement) string {
+
+	if len(announcements) == 0 {
+		return "There are no announcements in this channel."
+	}
+
+	lines := []string{}
+
+	for _, a := range announcements {
+		lines = append(lines, fmt.Sprintf("`%s` %s, next %s\n>%s", a.ID.Hex(), describeCron(&a), describeRun(a.NextRun), a.Response))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// describeCron takes an announcement and describes its schedule
+// ex: on cron `0 9 * * mon-fri` (America/Chicago)
+func describeCron(a *types.Announcement) string {
+
+	tz := a.TimeZone
+	if len(tz) == 0 {
+		tz = "UTC"
+	}
+
+	return fmt.Sprintf("on cron `%s` (%s)", a.Cron, tz)
+}
+
+// describeRun takes a unix time and formats it in the reader's time zone
+func describeRun(unix int64) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} at {time}|%s>", unix, time.Unix(unix, 0).UTC().Format(time.RFC1123))
+}
@@ -0,0 +1,371 @@
+package skelly
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultCooldown is how long to wait before responding to the same user again
+	defaultCooldown = 24 * time.Hour
+)
+
+// userMention matches an escaped slack user mention, ex: <@U123> or <@U123|david>
+var userMention = regexp.MustCompile(`^<@([UW][A-Z0-9]+)(\|[^>]*)?>$`)
+
+// userID matches a raw slack user id, ex: U0123ABCD
+// slack ids are at least 9 characters so words such as USA are not taken for ids
+var userID = regexp.MustCompile(`^[UW][A-Z0-9]{8,}$`)
+
+// reactionArgs is the parsed form of inline arguments for adding or updating a reaction
+// ex: /skelly add "Welcome!" --cooldown 1d --users @a @b
+type reactionArgs struct {
+	// Response is the message to respond with, empty when not provided
+	Response string
+	// Cooldown is how long to wait before responding to the same user again, nil when not provided
+	Cooldown *time.Duration
+	// Users are the user ids to respond to, nil when not provided
+	Users []string
+	// Schedule limits when the reaction is active, empty when not provided
+	Schedule ScheduleOptions
+	// Variants are the other responses and how to select one, empty when not provided
+	Variants VariantOptions
+	// Trigger is what the reaction responds to and where, empty when not provided
+	Trigger TriggerOptions
+	// FAQ are the questions the reaction answers, empty when not provided
+	FAQ FAQOptions
+	// ID picks the reaction to update when the channel has several, empty when not provided
+	ID string
+}
+
+// tokenize takes slash command text and splits it into arguments
+// whitespace separates arguments unless it is quoted with double, single or
+// slack's smart quotes, and a backslash escapes the following character
+// single quotes only open at the start of an argument and only close at its end,
+// so apostrophes are kept, ex: don't
+// case is preserved
+func tokenize(text string) ([]string, error) {
+
+	args := []string{}
+
+	var (
+		current strings.Builder
+		quote   rune
+		escaped bool
+		started bool
+	)
+
+	runes := []rune(text)
+
+	for i, r := range runes {
+
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+
+		case r == '\\':
+			escaped = true
+			started = true
+
+		case quote != 0:
+			// close the quote, smart quotes close with their counterpart
+			if closesQuote(quote, r, runes[i+1:]) {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+
+		case r == '"' || r == '“':
+			quote = r
+			started = true
+
+		case (r == '\'' || r == '‘') && !started:
+			quote = r
+			started = true
+
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote %q", quote)
+	}
+
+	if escaped {
+		return nil, errors.New("trailing escape character")
+	}
+
+	if started {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// closesQuote takes an open quote, a character and the text following it and returns
+// true if the character closes the quote, single quotes only close at the end of an argument
+func closesQuote(quote, r rune, rest []rune) bool {
+
+	switch quote {
+	case '“':
+		return r == '”'
+	case '‘':
+		return r == '’' && endsArgument(rest)
+	case '\'':
+		return r == '\'' && endsArgument(rest)
+	}
+
+	return r == quote
+}
+
+// endsArgument takes the text following a character and returns true if the character ends an argument
+func endsArgument(rest []rune) bool {
+	return len(rest) == 0 || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n' || rest[0] == '\r'
+}
+
+// parseReactionArgs takes the arguments following add or update and parses them
+// positional arguments are joined as the response and flags may appear in any order
+func parseReactionArgs(args []string) (*reactionArgs, error) {
+
+	parsed := new(reactionArgs)
+	response := []string{}
+
+	for i := 0; i < len(args); i++ {
+
+		arg := args[i]
+
+		switch strings.ToLower(arg) {
+		case "--cooldown", "-c":
+
+			if i+1 >= len(args) {
+				return nil, errors.New("--cooldown requires a value, ex: --cooldown 1d")
+			}
+			i++
+
+			d, err := parseCooldown(args[i])
+			if err != nil {
+				return nil, err
+			}
+
+			parsed.Cooldown = &d
+
+		case "--users", "-u":
+
+			parsed.Users = []string{}
+
+			// consume users until the first argument that is not a mention,
+			// slack escapes mentions so raw ids are left to the response, ex: WOW
+			for i+1 < len(args) {
+
+				m := userMention.FindStringSubmatch(args[i+1])
+				if m == nil {
+
+					// unescaped mentions are users that could not be resolved
+					if strings.HasPrefix(args[i+1], "@") {
+						return nil, unresolvedUser(args[i+1])
+					}
+
+					break
+				}
+				i++
+
+				parsed.Users = append(parsed.Users, m[1])
+			}
+
+			if len(parsed.Users) == 0 {
+				return nil, errors.New("--users requires at least one user, ex: --users @david")
+			}
+
+		case "--days", "--hours", "--tz", "--from", "--until":
+
+			flag := strings.ToLower(arg)
+
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value, ex: --days mon-fri --hours 09:00-17:00 --tz America/Chicago", flag)
+			}
+			i++
+
+			switch flag {
+			case "--days":
+				parsed.Schedule.Days = args[i]
+			case "--hours":
+				parsed.Schedule.Hours = args[i]
+			case "--tz":
+				parsed.Schedule.TimeZone = args[i]
+			case "--from":
+				parsed.Schedule.From = args[i]
+			case "--until":
+				parsed.Schedule.Until = args[i]
+			}
+
+		case "--always":
+			parsed.Schedule.Always = true
+
+		case "--variant", "--weights", "--strategy", "--locale":
+
+			flag := strings.ToLower(arg)
+
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value, ex: --variant \"Hello!\" --strategy round-robin", flag)
+			}
+			i++
+
+			switch flag {
+			case "--variant":
+				parsed.Variants.Variants = append(parsed.Variants.Variants, args[i])
+			case "--weights":
+				parsed.Variants.Weights = args[i]
+			case "--strategy":
+				parsed.Variants.Strategy = args[i]
+			case "--locale":
+				parsed.Variants.Locales = append(parsed.Variants.Locales, args[i])
+			}
+
+		case "--no-variants":
+			parsed.Variants.Clear = true
+
+		case "--id":
+
+			if i+1 >= len(args) {
+				return nil, errors.New("--id requires a value, ex: --id 5f1d7a3c9e1b2a0001a1b2c3")
+			}
+			i++
+
+			parsed.ID = args[i]
+
+		case "--trigger":
+
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--trigger requires a value, options: %s", strings.Join(triggers, ", "))
+			}
+			i++
+
+			parsed.Trigger.Trigger = args[i]
+
+		case "--emoji":
+
+			if i+1 >= len(args) {
+				return nil, errors.New("--emoji requires a value, ex: --emoji ticket")
+			}
+			i++
+
+			parsed.Trigger.Emoji = args[i]
+
+		case "--faq", "--threshold":
+
+			flag := strings.ToLower(arg)
+
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value, ex: --faq \"How do I reset my password?\" --threshold 0.4", flag)
+			}
+			i++
+
+			if flag == "--faq" {
+				parsed.FAQ.Questions = append(parsed.FAQ.Questions, args[i])
+			} else {
+				parsed.FAQ.Threshold = args[i]
+			}
+
+		case "--no-faq":
+			parsed.FAQ.Clear = true
+
+		case "--dm":
+			parsed.Trigger.DirectMessage = true
+
+		case "--no-dm":
+			parsed.Trigger.ChannelMessage = true
+
+		default:
+
+			if strings.HasPrefix(arg, "--") {
+				return nil, fmt.Errorf("unknown flag %s", arg)
+			}
+
+			response = append(response, arg)
+		}
+	}
+
+	parsed.Response = strings.Join(response, " ")
+
+	return parsed, nil
+}
+
+// parseCooldown takes a duration such as 30m, 12h, 1d or 2w and returns it as a time.Duration
+// 0 disables the cooldown
+func parseCooldown(s string) (time.Duration, error) {
+
+	unit := time.Duration(0)
+
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+
+	if unit > 0 {
+		n, err := strconv.Atoi(strings.TrimRight(s, "dw"))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid cooldown %s, ex: 30m, 12h, 1d", s)
+		}
+
+		return time.Duration(n) * unit, nil
+	}
+
+	if s == "0" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid cooldown %s, ex: 30m, 12h, 1d", s)
+	}
+
+	return d, nil
+}
+
+// formatCooldown returns a cooldown in the form accepted by parseCooldown
+func formatCooldown(d time.Duration) string {
+
+	day := 24 * time.Hour
+
+	if d > 0 && d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+
+	return d.String()
+}
+
+// parseUser takes a user mention or id and returns the user id, meant for the cli
+func parseUser(s string) (string, error) {
+
+	if m := userMention.FindStringSubmatch(s); m != nil {
+		return m[1], nil
+	}
+
+	if userID.MatchString(s) {
+		return s, nil
+	}
+
+	return "", unresolvedUser(s)
+}
+
+// unresolvedUser returns the error for a user that is not a mention or id
+func unresolvedUser(s string) error {
+	return fmt.Errorf("could not resolve user %s, make sure 'Escape channels, users, and links' is enabled for the command", s)
+}
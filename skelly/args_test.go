@@ -0,0 +1,129 @@
+package skelly
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+
+	tests := []struct {
+		text    string
+		want    []string
+		wantErr bool
+	}{
+		{text: `add Welcome! --cooldown 1d`, want: []string{"add", "Welcome!", "--cooldown", "1d"}},
+		{text: `add "Welcome to the channel!"`, want: []string{"add", "Welcome to the channel!"}},
+		{text: `add “Welcome to the channel!”`, want: []string{"add", "Welcome to the channel!"}},
+		{text: `add 'Welcome to the channel!'`, want: []string{"add", "Welcome to the channel!"}},
+		{text: `add ‘Welcome to the channel!’`, want: []string{"add", "Welcome to the channel!"}},
+		{text: `add don't forget`, want: []string{"add", "don't", "forget"}},
+		{text: `add 'don't forget'`, want: []string{"add", "don't forget"}},
+		{text: `add we’re here`, want: []string{"add", "we’re", "here"}},
+		{text: `add a\ b`, want: []string{"add", "a b"}},
+		{text: `add ""`, want: []string{"add", ""}},
+		{text: `add "unterminated`, wantErr: true},
+		{text: `add 'unterminated`, wantErr: true},
+		{text: `add trailing\`, wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		got, err := tokenize(test.text)
+
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("tokenize(%q) = %q, want an error", test.text, got)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("tokenize(%q) returned error: %v", test.text, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestParseReactionArgsUsers(t *testing.T) {
+
+	tests := []struct {
+		args     []string
+		users    []string
+		response string
+		wantErr  bool
+	}{
+		{args: []string{"--users", "<@U123>", "<@U456|david>"}, users: []string{"U123", "U456"}},
+		{args: []string{"--users", "<@U123>", "Welcome!"}, users: []string{"U123"}, response: "Welcome!"},
+		{args: []string{"--users", "<@U123>", "<#C123|general>", "is", "open"}, users: []string{"U123"}, response: "<#C123|general> is open"},
+		{args: []string{"--users", "<@U123>", "WOW", "welcome"}, users: []string{"U123"}, response: "WOW welcome"},
+		{args: []string{"--users", "<@U123>", "USA", "U0123ABCD"}, users: []string{"U123"}, response: "USA U0123ABCD"},
+		{args: []string{"--users", "WOW"}, wantErr: true},
+		{args: []string{"Hi", "--users", "<@U123>", "--cooldown", "1h"}, users: []string{"U123"}, response: "Hi"},
+		{args: []string{"--users", "@david"}, wantErr: true},
+		{args: []string{"--users", "Welcome!"}, wantErr: true},
+		{args: []string{"--users"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		got, err := parseReactionArgs(test.args)
+
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseReactionArgs(%q) returned users %q, want an error", test.args, got.Users)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseReactionArgs(%q) returned error: %v", test.args, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got.Users, test.users) {
+			t.Errorf("parseReactionArgs(%q) users = %q, want %q", test.args, got.Users, test.users)
+		}
+
+		if got.Response != test.response {
+			t.Errorf("parseReactionArgs(%q) response = %q, want %q", test.args, got.Response, test.response)
+		}
+	}
+}
+
+func TestParseUser(t *testing.T) {
+
+	tests := []struct {
+		user    string
+		want    string
+		wantErr bool
+	}{
+		{user: "<@U123>", want: "U123"},
+		{user: "<@W123|david>", want: "W123"},
+		{user: "U0123ABCD", want: "U0123ABCD"},
+		{user: "W0123ABCDEF", want: "W0123ABCDEF"},
+		{user: "WOW", wantErr: true},
+		{user: "USA", wantErr: true},
+		{user: "U123", wantErr: true},
+		{user: "u0123abcd", wantErr: true},
+		{user: "@david", wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		got, err := parseUser(test.user)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseUser(%q) err = %v, wantErr %t", test.user, err, test.wantErr)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("parseUser(%q) = %q, want %q", test.user, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package skelly
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// auditAdd is recorded when a reaction is added
+	auditAdd = "add"
+	// auditUpdate is recorded when a reaction is updated
+	auditUpdate = "update"
+	// auditPause is recorded when a reaction is paused
+	auditPause = "pause"
+	// auditResume is recorded when a paused reaction is resumed
+	auditResume = "resume"
+	// auditDelete is recorded when a reaction is moved into the trash
+	auditDelete = "delete"
+	// auditRestore is recorded when a reaction is restored from the trash
+	auditRestore = "restore"
+	// auditClear is recorded when a reaction is removed without the trash
+	auditClear = "clear"
+
+	// sourceSlash is a change made with an inline slash command, ex: /skelly update --cooldown 1h
+	sourceSlash = "slash"
+	// sourceModal is a change made with the add, update or delete modals
+	sourceModal = "modal"
+	// sourceHome is a change made with the buttons in skelly's home tab
+	sourceHome = "home"
+	// sourceUndo is a change made with the undo button posted after a delete
+	sourceUndo = "undo"
+	// sourceCLI is a change made with the skelly cli
+	sourceCLI = "cli"
+)
+
+// pausedAction takes whether a reaction is being paused and returns the audit action
+func pausedAction(paused bool) string {
+
+	if paused {
+		return auditPause
+	}
+
+	return auditResume
+}
+
+// cliActor returns who is making changes with the cli, the local user when known
+func cliActor() string {
+
+	u, err := user.Current()
+	if err != nil || len(u.Username) == 0 {
+		return sourceCLI
+	}
+
+	return u.Username
+}
+
+// auditSnapshot takes a reaction and returns its fields for comparing before and after a change
+// returns nil for a reaction that does not exist
+func auditSnapshot(r *types.Reaction) map[string]interface{} {
+
+	if r == nil {
+		return nil
+	}
+
+	snapshot := map[string]interface{}{}
+
+	// the reaction's json is what users see in the api and cli
+	b, err := json.Marshal(r)
+	if err == nil {
+		err = json.Unmarshal(b, &snapshot)
+	}
+
+	if err != nil {
+		return nil
+	}
+
+	// ids do not change, and cooldowns read better formatted than in nanoseconds
+	delete(snapshot, "id")
+	snapshot["cooldown"] = formatCooldown(r.Cooldown)
+
+	return snapshot
+}
+
+// auditChanges takes snapshots of a reaction before and after a change and returns the fields that changed
+func auditChanges(before, after map[string]interface{}) []types.AuditChange {
+
+	fields := []string{}
+	for f := range before {
+		fields = append(fields, f)
+	}
+
+	for f := range after {
+		if _, ok := before[f]; !ok {
+			fields = append(fields, f)
+		}
+	}
+
+	sort.Strings(fields)
+
+	changes := []types.AuditChange{}
+
+	for _, f := range fields {
+
+		b, a := auditValue(before[f]), auditValue(after[f])
+
+		// unset and empty fields are the same to users
+		if b == a {
+			continue
+		}
+
+		changes = append(changes, types.AuditChange{
+			Field:  f,
+			Before: b,
+			After:  a,
+		})
+	}
+
+	return changes
+}
+
+// auditValue takes a field of a reaction snapshot and returns it as text, empty when unset
+func auditValue(v interface{}) string {
+
+	if v == nil {
+		return ""
+	}
+
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(b)
+}
+
+// recordAudit takes snapshots of a reaction before and after a change and records who changed it, and where
+// the change is posted to SKELLY_AUDIT_CHANNEL when set, failures are logged rather than returned
+// since the change was already made
+func recordAudit(ctx context.Context, team, channel, reaction, actor, action, source string, before, after map[string]interface{}) {
+
+	audit := &types.Audit{
+		Team:     team,
+		Channel:  channel,
+		Reaction: reaction,
+		Actor:    actor,
+		Action:   action,
+		Source:   source,
+		Changes:  auditChanges(before, after),
+		Created:  time.Now().Unix(),
+	}
+
+	err := db.AddAudit(ctx, audit)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("could not record audit for channel(%s) action(%s): %v", channel, action, err)
+		return
+	}
+
+	logging.FromContext(ctx).Infof("audit(%s) for channel(%s) action(%s) actor(%s) source(%s)", audit.ID.Hex(), channel, action, actor, source)
+
+	err = postAudit(ctx, audit)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("could not post audit(%s) for channel(%s): %v", audit.ID.Hex(), channel, err)
+	}
+}
+
+// postAudit takes an audit event and posts it to the admin channel in SKELLY_AUDIT_CHANNEL, if set
+func postAudit(ctx context.Context, audit *types.Audit) error {
+
+	channel := os.Getenv("SKELLY_AUDIT_CHANNEL")
+	if len(channel) == 0 {
+		return nil
+	}
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, audit.Team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	// create an api client
+	api := slackapi.For(bToken)
+
+	_, _, err = api.PostMessage(ctx, channel, slack.MsgOptionText(describeAudit(audit), false))
+	if err != nil {
+		err = errors.Wrap(err, "could not post message")
+		return err
+	}
+
+	return nil
+}
+
+// describeAudit takes an audit event and describes who changed what for slack
+func describeAudit(audit *types.Audit) string {
+
+	actor := fmt.Sprintf("<@%s>", audit.Actor)
+	if audit.Source == sourceCLI || len(audit.Actor) == 0 {
+		actor = fmt.Sprintf("`%s`", audit.Actor)
+	}
+
+	lines := []string{
+		fmt.Sprintf("%s used %s to %s the reaction for <#%s>.", actor, describeSource(audit.Source), audit.Action, audit.Channel),
+	}
+
+	for _, c := range audit.Changes {
+		lines = append(lines, fmt.Sprintf("• *%s*: %s → %s", c.Field, describeAuditValue(c.Before), describeAuditValue(c.After)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// describeAuditValue takes a changed value and formats it for slack
+func describeAuditValue(v string) string {
+
+	if len(v) == 0 {
+		return "_none_"
+	}
+
+	return fmt.Sprintf("`%s`", v)
+}
+
+// describeSource takes where a change was made and describes it, ex: a slash command
+func describeSource(source string) string {
+
+	switch source {
+	case sourceSlash:
+		return "a slash command"
+	case sourceModal:
+		return "a modal"
+	case sourceHome:
+		return "the home tab"
+	case sourceUndo:
+		return "the undo button"
+	case sourceCLI:
+		return "the cli"
+	}
+
+	return source
+}
@@ -1,20 +1,24 @@
 package skelly
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/types"
 	"github.com/gosuri/uitable"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 )
 
 // List takes channel and message and adds a reaction to the database.
-func List(bToken, channel string) error {
+func List(ctx context.Context, bToken, team, channel string) error {
 
 	// retrieve the appropriate reaction for the channel
-	reactions, err := db.GetChannelReactions(channel)
+	reactions, err := db.GetChannelReactions(ctx, team, channel)
 	if err != nil {
 		return err
 	}
@@ -25,10 +29,12 @@ func List(bToken, channel string) error {
 	table.Wrap = true // wrap columns
 
 	table.AddRow(fmt.Sprintf("Reactions for channel(%s)", channel))
-	table.AddRow("RESPONSE")
+	table.AddRow("ID", "RESPONSE", "STRATEGY", "TRIGGER")
 
 	for _, r := range *reactions {
-		table.AddRow(r.Response)
+		for _, response := range responses(&r) {
+			table.AddRow(r.ID.Hex(), response, r.Strategy, describeTrigger(&r))
+		}
 	}
 
 	// add a row of space at the bottom
@@ -41,27 +47,40 @@ func List(bToken, channel string) error {
 }
 
 // Clear takes channel and removes reactions from the database.
-func Clear(channel string) error {
+func Clear(ctx context.Context, team, channel string) error {
+
+	// retrieve the reactions being removed for the audit log
+	reactions, err := db.GetChannelReactions(ctx, team, channel)
+	if err != nil {
+		err = errors.Wrap(err, "could not get reactions from db")
+		return err
+	}
 
 	// delete reactions from the database
-	n, err := db.DeleteChannelReactions(channel)
+	n, err := db.DeleteReactions(ctx, team, channel)
 	if err != nil {
 		err = errors.Wrap(err, "could not delete reactions from db")
 		return err
 	}
 
-	logrus.Infof("removed (%v) reactions for channel(%s)", n, channel)
+	logging.FromContext(ctx).Infof("removed (%v) reactions for channel(%s)", n, channel)
+
+	actor := cliActor()
+	for i := range *reactions {
+		r := &(*reactions)[i]
+		recordAudit(ctx, team, channel, r.ID.Hex(), actor, auditClear, sourceCLI, auditSnapshot(r), nil)
+	}
 
 	return nil
 }
 
 // View takes channel and retrieves the appropriate response
-func View(channel string) error {
+// id picks the reaction when the channel has several
+func View(ctx context.Context, team, channel, id string) error {
 
 	// retrieve reaction from db
-	reaction, err := db.GetReaction(channel)
+	reaction, err := cliReaction(ctx, team, channel, id)
 	if err != nil {
-		err = errors.Wrap(err, "could not get reaction from db")
 		return err
 	}
 
@@ -77,57 +96,500 @@ func View(channel string) error {
 }
 
 // Add takes channel and response and adds a reaction to the database.
-func Add(bToken, channel, response string) error {
+// cooldown defaults to a day when empty, users limits the reaction to specific users,
+// schedule limits when the reaction is active, variants adds other responses,
+// trigger sets what the reaction responds to and faq sets the questions it answers.
+func Add(ctx context.Context, bToken, team, channel, response, cooldown string, users []string, schedule *ScheduleOptions, variants *VariantOptions, trigger *TriggerOptions, faq *FAQOptions) error {
+
+	reaction := &types.Reaction{
+		Team:     team,
+		Channel:  channel,
+		Response: response,
+		Cooldown: defaultCooldown,
+	}
+
+	// parse the cooldown, if provided
+	if len(cooldown) > 0 {
+		d, err := parseCooldown(cooldown)
+		if err != nil {
+			return err
+		}
+		reaction.Cooldown = d
+	}
+
+	// parse the users, if provided
+	for _, u := range users {
+		id, err := parseUser(u)
+		if err != nil {
+			return err
+		}
+		reaction.Users = append(reaction.Users, id)
+	}
+
+	// parse the schedule, if provided
+	r, err := applySchedule(nil, schedule)
+	if err != nil {
+		return err
+	}
+	reaction.Schedule = r
+
+	// validate the response
+	err = validateResponse(response)
+	if err != nil {
+		return err
+	}
+
+	// add the other responses, if provided
+	err = applyVariants(reaction, variants)
+	if err != nil {
+		return err
+	}
+
+	// set what the reaction responds to, if provided
+	err = applyTrigger(reaction, trigger)
+	if err != nil {
+		return err
+	}
+
+	// set the questions the reaction answers, if provided
+	err = applyFAQ(reaction, faq)
+	if err != nil {
+		return err
+	}
 
 	// add the appropriate reaction for the channel/msg
-	err := db.AddReaction(channel, response)
+	err = db.AddReaction(ctx, reaction)
 	if err != nil {
 		err = errors.Wrap(err, "could not add reaction to db")
 		return err
 	}
 
-	logrus.Infof("reaction added for channel(%s) response(%s)", channel, response)
+	logging.FromContext(ctx).Infof("reaction added for channel(%s) response(%s)", channel, response)
+
+	recordAudit(ctx, team, channel, reaction.ID.Hex(), cliActor(), auditAdd, sourceCLI, nil, auditSnapshot(reaction))
+
 	return nil
 }
 
-// Update takes channel and response and updates a reaction in the database.
-func Update(bToken, channel, response string) error {
+// Update takes channel and updates a reaction in the database.
+// id picks the reaction when the channel has several.
+// only the provided response, cooldown, users, schedule, variants, trigger and faq are changed.
+func Update(ctx context.Context, bToken, team, channel, id, response, cooldown string, users []string, schedule *ScheduleOptions, variants *VariantOptions, trigger *TriggerOptions, faq *FAQOptions) error {
+
+	// retrieve reaction from db
+	reaction, err := cliReaction(ctx, team, channel, id)
+	if err != nil {
+		return err
+	}
+
+	before := auditSnapshot(reaction)
+
+	if len(response) > 0 {
+
+		// validate the response
+		err = validateResponse(response)
+		if err != nil {
+			return err
+		}
+
+		reaction.Response = response
+	}
+
+	// parse the cooldown, if provided
+	if len(cooldown) > 0 {
+		d, err := parseCooldown(cooldown)
+		if err != nil {
+			return err
+		}
+		reaction.Cooldown = d
+	}
+
+	// parse the users, if provided
+	if len(users) > 0 {
+		reaction.Users = []string{}
+		for _, u := range users {
+			id, err := parseUser(u)
+			if err != nil {
+				return err
+			}
+			reaction.Users = append(reaction.Users, id)
+		}
+	}
+
+	// apply the schedule, if provided
+	if !schedule.Empty() {
+		r, err := applySchedule(reaction.Schedule, schedule)
+		if err != nil {
+			return err
+		}
+		reaction.Schedule = r
+	}
+
+	// apply the other responses, if provided
+	if !variants.Empty() {
+		err = applyVariants(reaction, variants)
+		if err != nil {
+			return err
+		}
+	}
+
+	// apply what the reaction responds to, if provided
+	if !trigger.Empty() {
+		err = applyTrigger(reaction, trigger)
+		if err != nil {
+			return err
+		}
+	}
+
+	// apply the questions the reaction answers, if provided
+	if !faq.Empty() || !trigger.Empty() {
+		err = applyFAQ(reaction, faq)
+		if err != nil {
+			return err
+		}
+	}
+
+	// do not take the trigger of another reaction in the channel
+	exists, other, err := db.ReactionExists(ctx, team, channel, reaction.Key())
+	if err != nil {
+		err = errors.Wrap(err, "could not check for reaction in db")
+		return err
+	}
+
+	if exists && other.ID != reaction.ID {
+		return fmt.Errorf("reaction(%s) already responds to %s in channel(%s)", other.ID.Hex(), describeEvent(reaction), channel)
+	}
 
 	// update the appropriate reaction for the channel
-	err := db.UpdateReaction(channel, response)
+	err = db.UpdateReaction(ctx, reaction)
 	if err != nil {
 		err = errors.Wrap(err, "could not update reaction in db")
 		return err
 	}
 
-	logrus.Infof("reaction updated for channel(%s) response(%s)", channel, response)
+	logging.FromContext(ctx).Infof("reaction updated for channel(%s) response(%s)", channel, reaction.Response)
+
+	recordAudit(ctx, team, channel, reaction.ID.Hex(), cliActor(), auditUpdate, sourceCLI, before, auditSnapshot(reaction))
+
+	return nil
+}
+
+// Pause takes channel and pauses its reaction in the database.
+// id picks the reaction when the channel has several.
+func Pause(ctx context.Context, team, channel, id string) error {
+	return pause(ctx, team, channel, id, true)
+}
+
+// Resume takes channel and resumes its paused reaction in the database.
+// id picks the reaction when the channel has several.
+func Resume(ctx context.Context, team, channel, id string) error {
+	return pause(ctx, team, channel, id, false)
+}
+
+// pause takes channel and pauses or resumes its reaction in the database.
+func pause(ctx context.Context, team, channel, id string, paused bool) error {
+
+	// retrieve reaction from db
+	reaction, err := cliReaction(ctx, team, channel, id)
+	if err != nil {
+		return err
+	}
+
+	err = setPaused(ctx, team, reaction, cliActor(), sourceCLI, paused)
+	if err != nil {
+		err = errors.Wrap(err, "could not pause reaction")
+		return err
+	}
+
 	return nil
 }
 
 // Delete takes channel and deletes reactions from the database.
-func Delete(bToken, channel string) error {
+// only the reaction with id is deleted, unless id is empty.
+func Delete(ctx context.Context, bToken, team, channel, id string) error {
 
-	// delete the appropriate reactions for the channel
-	n, err := db.DeleteReactions(channel)
+	// move the appropriate reactions for the channel into the trash
+	trash, err := trashReactions(ctx, team, channel, id, cliActor(), sourceCLI)
 	if err != nil {
 		err = errors.Wrap(err, "could not delete reaction from db")
 		return err
 	}
 
-	logrus.Infof("(%v) reactions deleted for channel(%s)", n, channel)
+	logging.FromContext(ctx).Infof("(%v) reactions deleted for channel(%s), restore with trash id(%s)", len(trash.Reactions), channel, trash.ID.Hex())
+	return nil
+}
+
+// cliReaction takes a channel and returns its reaction with id, or its only reaction when id is empty
+func cliReaction(ctx context.Context, team, channel, id string) (*types.Reaction, error) {
+
+	reaction, err := findReaction(ctx, team, channel, id)
+	if err != nil {
+		err = errors.Wrap(err, "could not get reaction from db")
+		return nil, err
+	}
+
+	if reaction == nil {
+		return nil, fmt.Errorf("reaction(%s) does not exist for channel(%s)", id, channel)
+	}
+
+	return reaction, nil
+}
+
+// Restore takes a channel and restores its deleted reactions from the trash.
+// restores the most recent delete within the retention window unless a trash id is given
+func Restore(ctx context.Context, team, channel, id string) error {
+
+	var (
+		trash *types.Trash
+		err   error
+	)
+
+	// retrieve the trashed reactions
+	if len(id) > 0 {
+		trash, err = db.GetTrash(ctx, id)
+	} else {
+		trash, err = db.GetLatestTrash(ctx, team, channel, time.Now().Add(-trashRetention()).Unix())
+	}
+	if err != nil {
+		err = errors.Wrap(err, "could not get trash from db")
+		return err
+	}
+
+	if trash == nil || trash.Channel != channel {
+		return fmt.Errorf("no deleted reactions to restore for channel(%s)", channel)
+	}
+
+	msg, err := restoreReactions(ctx, trash, cliActor(), sourceCLI)
+	if err != nil {
+		err = errors.Wrap(err, "could not restore reactions")
+		return err
+	}
+
+	if len(msg) > 0 {
+		return errors.New(msg)
+	}
+
+	logging.FromContext(ctx).Infof("(%v) reactions restored for channel(%s)", len(trash.Reactions), channel)
 	return nil
 }
 
 // Trigger takes post parameters and posts a reaction following any rules specified for that channel.
-func Trigger(bToken, channel, user, ts string) error {
+// trigger is what to simulate, ex: message or join, and text is the message for faq reactions.
+func Trigger(ctx context.Context, bToken, team, channel, user, ts, trigger, text string) error {
 
 	// post the appropriate reactions for the channel/ts
-	err := React(bToken, channel, user, ts)
+	err := React(ctx, bToken, team, channel, user, ts, trigger, text)
+	if err != nil {
+		logging.FromContext(ctx).Infof("could not post reaction for channel(%s) user(%s) ts(%s)", channel, user, ts)
+		return err
+	}
+
+	logging.FromContext(ctx).Infof("reactions posted for channel(%s) user(%s) ts(%s)", channel, user, ts)
+	return nil
+}
+
+// Announce takes channel, response and a cron spec and adds a scheduled announcement to the database.
+// timeZone is the location the cron spec is in, defaulting to UTC when empty.
+func Announce(ctx context.Context, team, channel, response, cron, timeZone string) error {
+
+	// validate and schedule the announcement
+	a, err := newAnnouncement(team, channel, "", response, cron, timeZone)
+	if err != nil {
+		return err
+	}
+
+	// add the announcement for the channel
+	err = db.AddAnnouncement(ctx, a)
+	if err != nil {
+		err = errors.Wrap(err, "could not add announcement to db")
+		return err
+	}
+
+	logging.FromContext(ctx).Infof("announcement(%s) added for channel(%s) next run(%s)", a.ID.Hex(), channel, time.Unix(a.NextRun, 0))
+	return nil
+}
+
+// Announcements takes channel and lists its scheduled announcements.
+func Announcements(ctx context.Context, team, channel string) error {
+
+	// retrieve the announcements for the channel
+	announcements, err := db.GetAnnouncements(ctx, team, channel)
+	if err != nil {
+		err = errors.Wrap(err, "could not get announcements from db")
+		return err
+	}
+
+	// output announcements as a table
+	table := uitable.New()
+	table.MaxColWidth = 200
+	table.Wrap = true // wrap columns
+
+	table.AddRow(fmt.Sprintf("Announcements for channel(%s)", channel))
+	table.AddRow("ID", "CRON", "TIMEZONE", "NEXT RUN", "RESPONSE")
+
+	for _, a := range announcements {
+		table.AddRow(a.ID.Hex(), a.Cron, a.TimeZone, time.Unix(a.NextRun, 0).Format(time.RFC3339), a.Response)
+	}
+
+	// add a row of space at the bottom
+	table.AddRow()
+
+	// print the table
+	fmt.Println(table)
+
+	return nil
+}
+
+// DeleteAnnouncement takes an announcement id and deletes it from the database.
+func DeleteAnnouncement(ctx context.Context, team, id string) error {
+
+	// retrieve the announcement from db
+	a, err := db.GetAnnouncement(ctx, id)
+	if err != nil {
+		err = errors.Wrap(err, "could not get announcement from db")
+		return err
+	}
+
+	if a == nil || (len(team) > 0 && len(a.Team) > 0 && a.Team != team) {
+		return fmt.Errorf("announcement(%s) does not exist", id)
+	}
+
+	// delete the announcement
+	err = db.DeleteAnnouncement(ctx, a)
+	if err != nil {
+		err = errors.Wrap(err, "could not delete announcement from db")
+		return err
+	}
+
+	logging.FromContext(ctx).Infof("announcement(%s) deleted for channel(%s)", id, a.Channel)
+	return nil
+}
+
+// Stats takes channel and prints how often its reactions fired and why they were skipped.
+// prints every channel in the team when channel is empty, since defaults to 30d when empty.
+func Stats(ctx context.Context, team, channel, since string) error {
+
+	window := defaultStatsWindow
+
+	// parse the window, if provided
+	if len(since) > 0 {
+		d, err := ParseStatsWindow(since)
+		if err != nil {
+			return err
+		}
+		window = d
+	}
+
+	start := time.Now().Add(-window)
+
+	stats, err := getActivityStats(ctx, team, channel, start)
+	if err != nil {
+		err = errors.Wrap(err, "could not get activity stats")
+		return err
+	}
+
+	// output stats as tables
+	table := uitable.New()
+	table.MaxColWidth = 200
+
+	table.AddRow(fmt.Sprintf("Stats since %s", start.UTC().Format(time.RFC1123)))
+	table.AddRow("FIRED", "USERS", "SKIPPED")
+	table.AddRow(stats.Fired, stats.Users, stats.Skipped)
+	table.AddRow()
+
+	table.AddRow("SKIP REASON", "COUNT")
+	for _, reason := range sortedCounts(stats.SkipReasons) {
+		table.AddRow(reason, stats.SkipReasons[reason])
+	}
+	table.AddRow()
+
+	table.AddRow("CHANNEL", "REACTION", "FIRED", "USERS", "SKIPPED")
+	for c, s := range stats.Channels {
+		for _, r := range sortedCounts(s.Reactions) {
+			table.AddRow(c, r, s.Reactions[r], s.Users, s.Skipped)
+		}
+
+		if len(s.Reactions) == 0 {
+			table.AddRow(c, "", 0, 0, s.Skipped)
+		}
+	}
+	table.AddRow()
+
+	table.AddRow("DAY", "FIRED", "")
+	for _, day := range trendDays(start, time.Now()) {
+		table.AddRow(day, stats.Daily[day], trendBar(stats.Daily[day], stats.Daily))
+	}
+	table.AddRow()
+
+	// print the table
+	fmt.Println(table)
+
+	return nil
+}
+
+// Audit takes channel and prints the changes made to its reactions, newest first.
+// prints every channel when channel is empty, since defaults to 30d when empty
+// and limit is the most changes to print, 0 for all of them.
+func Audit(ctx context.Context, team, channel, since string, limit int) error {
+
+	window := defaultStatsWindow
+
+	// parse the window, if provided
+	if len(since) > 0 {
+		d, err := ParseStatsWindow(since)
+		if err != nil {
+			return err
+		}
+		window = d
+	}
+
+	// retrieve the changes from the db
+	audits, err := db.GetAudits(ctx, team, channel, time.Now().Add(-window).Unix(), limit)
+	if err != nil {
+		err = errors.Wrap(err, "could not get audits from db")
+		return err
+	}
+
+	// output the changes as a table
+	table := uitable.New()
+	table.MaxColWidth = 200
+	table.Wrap = true // wrap columns
+
+	table.AddRow("TIME", "CHANNEL", "ACTOR", "SOURCE", "ACTION", "CHANGES")
+
+	for _, a := range audits {
+
+		changes := []string{}
+		for _, c := range a.Changes {
+			changes = append(changes, fmt.Sprintf("%s: %q -> %q", c.Field, c.Before, c.After))
+		}
+
+		table.AddRow(time.Unix(a.Created, 0).UTC().Format(time.RFC3339), a.Channel, a.Actor, a.Source, a.Action, strings.Join(changes, "\n"))
+	}
+
+	// add a row of space at the bottom
+	table.AddRow()
+
+	// print the table
+	fmt.Println(table)
+
+	return nil
+}
+
+// Migrate takes a team and assigns it the reactions, trash and announcements
+// created before multi-workspace support, which otherwise match no team.
+func Migrate(ctx context.Context, team string) error {
+
+	if len(team) == 0 {
+		return errors.New("no team provided to backfill, set --team or SKELLY_DEFAULT_TEAM")
+	}
+
+	n, err := db.BackfillTeam(ctx, team)
 	if err != nil {
-		logrus.Infof("could not post reaction for channel(%s) user(%s) ts(%s)", channel, user, ts)
+		err = errors.Wrap(err, "could not backfill team in db")
 		return err
 	}
 
-	logrus.Infof("reactions posted for channel(%s) user(%s) ts(%s)", channel, user, ts)
+	logging.FromContext(ctx).Infof("backfilled team(%s) for (%v) documents", team, n)
 	return nil
 }
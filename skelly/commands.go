@@ -1,11 +1,17 @@
 package skelly
 
 import (
+	"context"
+	"fmt"
 	"strings"
 
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/util"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -14,44 +20,80 @@ var (
 	updateSubCommand = "update"
 	deleteSubCommand = "delete"
 	listSubCommand   = "list"
+	pauseSubCommand  = "pause"
+	resumeSubCommand = "resume"
+
+	// allArg lists reactions for every channel, ex: /skelly list all
+	allArg = "all"
 )
 
 // HandleSlashCommand takes slack slash command configuration and executes it
-func HandleSlashCommand(s *slack.SlashCommand) error {
+func HandleSlashCommand(ctx context.Context, s *slack.SlashCommand) error {
 
-	// parse slash command input
-	args := strings.Fields(strings.TrimSpace(strings.ToLower(s.Text)))
+	ctx, span := tracing.Start(ctx, "skelly.HandleSlashCommand",
+		attribute.String("skelly.command", s.Command),
+		attribute.String("skelly.channel", s.ChannelID),
+		attribute.String("skelly.user", s.UserID))
+	defer span.End()
 
 	// join the command and the input
-	command := strings.Join([]string{s.Command, strings.Join(args, " ")}, " ")
+	command := strings.Join([]string{s.Command, strings.TrimSpace(s.Text)}, " ")
+
+	logging.FromContext(ctx).Infof("handling command(%s)", command)
 
-	logrus.Infof("handling command(%s)", command)
+	// parse slash command input, respecting quotes
+	args, err := tokenize(strings.TrimSpace(s.Text))
+	if err != nil {
+
+		// invalid input, tell the user why
+		err = util.Respond(ctx, s.ResponseURL, slack.Msg{Text: fmt.Sprintf("Sorry, I could not read that command: %s.", err)})
+		if err != nil {
+			err = errors.Wrap(err, "could not respond")
+			return tracing.Error(span, err)
+		}
+		return nil
+	}
 
 	// validate input
 	if len(args) == 0 {
 
 		// unsupported command, send help
-		err := sendHelp(command, s.ResponseURL)
+		err := sendHelp(ctx, command, s.ResponseURL)
 		if err != nil {
 			err = errors.Wrap(err, "could not send help")
-			return err
+			return tracing.Error(span, err)
 		}
 		return nil
 	}
 
 	// execute the subcommand
-	err := handleSubCommand(s, command, args)
+	err = handleSubCommand(ctx, s, command, args)
 	if err != nil {
 		err = errors.Wrap(err, "could not send help")
-		return err
+		return tracing.Error(span, err)
 	}
 	return nil
 }
 
 // handleSubCommand takes slash command arguments and executes the appropriate subcommand
-func handleSubCommand(s *slack.SlashCommand, command string, args []string) error {
+func handleSubCommand(ctx context.Context, s *slack.SlashCommand, command string, args []string) error {
+
+	subcommand := strings.ToLower(args[0])
 
-	subcommand := args[0]
+	// check that the user may manage reactions in the channel
+	switch subcommand {
+	case addSubCommand, updateSubCommand, deleteSubCommand, pauseSubCommand, resumeSubCommand:
+
+		allowed, err := checkPermission(ctx, s.TeamID, s.ChannelID, s.ChannelID, s.UserID, subcommand)
+		if err != nil {
+			err = errors.Wrap(err, "could not check permission")
+			return err
+		}
+
+		if !allowed {
+			return nil
+		}
+	}
 
 	// execute the command
 	switch subcommand {
@@ -60,7 +102,7 @@ func handleSubCommand(s *slack.SlashCommand, command string, args []string) erro
 	case helpSubCommand:
 
 		// send help message
-		err := sendHelp(command, s.ResponseURL)
+		err := sendHelp(ctx, command, s.ResponseURL)
 		if err != nil {
 			err = errors.Wrap(err, "could not send help")
 			return err
@@ -71,8 +113,20 @@ func handleSubCommand(s *slack.SlashCommand, command string, args []string) erro
 	// /skelly add
 	case addSubCommand:
 
+		// add the reaction inline when arguments are provided
+		// ex: /skelly add "Welcome!" --cooldown 1d
+		if len(args) > 1 {
+			err := addInline(ctx, s, args[1:])
+			if err != nil {
+				err = errors.Wrap(err, "could not add reaction")
+				return err
+			}
+
+			return nil
+		}
+
 		// open add reaction modal
-		err := openAddModal(s, command, args)
+		err := openAddModal(ctx, s, command, args)
 		if err != nil {
 			err = errors.Wrap(err, "could not open add modal")
 			return err
@@ -83,8 +137,20 @@ func handleSubCommand(s *slack.SlashCommand, command string, args []string) erro
 	// /skelly update
 	case updateSubCommand:
 
+		// update the reaction inline when arguments other than the reaction's id are provided
+		// ex: /skelly update --users @david
+		if _, err := parseIDArg(args[1:]); err != nil {
+			err := updateInline(ctx, s, args[1:])
+			if err != nil {
+				err = errors.Wrap(err, "could not update reaction")
+				return err
+			}
+
+			return nil
+		}
+
 		// open update reaction modal
-		err := openUpdateModal(s, command, args)
+		err := openUpdateModal(ctx, s, command, args)
 		if err != nil {
 			err = errors.Wrap(err, "could not open update modal")
 			return err
@@ -96,7 +162,7 @@ func handleSubCommand(s *slack.SlashCommand, command string, args []string) erro
 	case deleteSubCommand:
 
 		// open delete reaction modal
-		err := openDeleteModal(s, command, args)
+		err := openDeleteModal(ctx, s, command, args)
 		if err != nil {
 			err = errors.Wrap(err, "could not open delete modal")
 			return err
@@ -104,11 +170,56 @@ func handleSubCommand(s *slack.SlashCommand, command string, args []string) erro
 
 		return nil
 
+	// /skelly pause
+	// /skelly resume
+	case pauseSubCommand, resumeSubCommand:
+
+		err := pauseInline(ctx, s, args[1:], subcommand == pauseSubCommand)
+		if err != nil {
+			err = errors.Wrap(err, "could not pause reaction")
+			return err
+		}
+
+		return nil
+
+	// /skelly announce
+	case announceSubCommand:
+
+		err := handleAnnounce(ctx, s, args[1:])
+		if err != nil {
+			err = errors.Wrap(err, "could not handle announcement")
+			return err
+		}
+
+		return nil
+
+	// /skelly stats
+	case statsSubCommand:
+
+		err := handleStats(ctx, s, args[1:])
+		if err != nil {
+			err = errors.Wrap(err, "could not handle stats")
+			return err
+		}
+
+		return nil
+
+	// /skelly ooo
+	case oooSubCommand:
+
+		err := handleOutOfOffice(ctx, s, args[1:])
+		if err != nil {
+			err = errors.Wrap(err, "could not handle out of office")
+			return err
+		}
+
+		return nil
+
 	// /skelly list
 	case listSubCommand:
 
 		// open delete reaction modal
-		err := listReactions(s, command, args)
+		err := listReactions(ctx, s, command, args)
 		if err != nil {
 			err = errors.Wrap(err, "could not list reactions")
 			return err
@@ -120,7 +231,7 @@ func handleSubCommand(s *slack.SlashCommand, command string, args []string) erro
 	default:
 
 		// unsupported command, send help
-		err := sendHelp(command, s.ResponseURL)
+		err := sendHelp(ctx, command, s.ResponseURL)
 		if err != nil {
 			err = errors.Wrap(err, "could not send help")
 			return err
@@ -129,14 +240,47 @@ func handleSubCommand(s *slack.SlashCommand, command string, args []string) erro
 	}
 }
 
-// parseListSubCommandArgs takes input args and checks for no args
-// returns error if input is not valid
-func parseListSubCommandArgs(args []string) error {
+// postConfirmation takes text and posts it as an ephemeral message to the user
+func postConfirmation(ctx context.Context, bToken, text, channel, user string) error {
 
-	// validate input
-	if len(args) != 1 {
-		return errors.New("invalid number of args")
+	section := slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil)
+
+	// create an api client
+	api := slackapi.For(bToken)
+
+	// post the confirmation
+	_, err := api.PostEphemeral(ctx, channel, user, slack.MsgOptionBlocks(section))
+	if err != nil {
+		err = errors.Wrap(err, "could not post response")
+		return err
+	}
+	return nil
+}
+
+// notifyUser takes msg and sends it to the user as an ephemeral error in channel
+// returns nil once the user is notified, so handlers can return it when they stop
+func notifyUser(ctx context.Context, bToken, msg, channel, user string) error {
+
+	err := util.SendError(ctx, bToken, msg, channel, user)
+	if err != nil {
+		err = errors.Wrap(err, "could not send error")
+		return err
 	}
 
 	return nil
 }
+
+// parseListSubCommandArgs takes input args and checks for no args or all
+// returns true when listing all channels and error if input is not valid
+func parseListSubCommandArgs(args []string) (bool, error) {
+
+	// validate input
+	switch {
+	case len(args) == 1:
+		return false, nil
+	case len(args) == 2 && strings.ToLower(args[1]) == allArg:
+		return true, nil
+	}
+
+	return false, errors.New("invalid number of args")
+}
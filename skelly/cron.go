@@ -0,0 +1,233 @@
+package skelly
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronDescriptors are the shorthands accepted in place of a cron spec
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronMonths are the names accepted in the month field
+var cronMonths = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+// cronSpec is a parsed cron spec, each field holds the values it matches
+// ex: 0 9 * * mon-fri
+type cronSpec struct {
+	minute map[int]bool
+	hour   map[int]bool
+	dom    map[int]bool
+	month  map[int]bool
+	dow    map[int]bool
+
+	// domAny and dowAny record a * day of month or week, when both are
+	// restricted a day matching either runs, the same as cron
+	domAny bool
+	dowAny bool
+}
+
+// parseCron takes a five field cron spec (minute hour day-of-month month day-of-week)
+// or a descriptor such as @daily and parses it
+func parseCron(spec string) (*cronSpec, error) {
+
+	value := strings.ToLower(strings.TrimSpace(spec))
+
+	if d, ok := cronDescriptors[value]; ok {
+		value = d
+	}
+
+	fields := strings.Fields(value)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid cron %s, expected five fields, ex: 0 9 * * mon-fri", spec)
+	}
+
+	s := new(cronSpec)
+
+	var err error
+
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron minute %s", fields[0])
+	}
+
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron hour %s", fields[1])
+	}
+
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid cron day of month %s", fields[2])
+	}
+
+	if s.month, err = parseCronField(fields[3], 1, 12, cronMonths); err != nil {
+		return nil, fmt.Errorf("invalid cron month %s", fields[3])
+	}
+
+	if s.dow, err = parseCronField(fields[4], 0, 7, weekdays); err != nil {
+		return nil, fmt.Errorf("invalid cron day of week %s", fields[4])
+	}
+
+	// 7 is also sunday
+	if s.dow[7] {
+		s.dow[0] = true
+	}
+
+	s.domAny = strings.HasPrefix(fields[2], "*")
+	s.dowAny = strings.HasPrefix(fields[4], "*")
+
+	return s, nil
+}
+
+// parseCronField takes a cron field such as *, */15, 1-5 or mon,wed,fri and returns the values it matches
+// names are matched by their index offset by min, ex: jan is 1
+func parseCronField(field string, min, max int, names []string) (map[int]bool, error) {
+
+	values := map[int]bool{}
+
+	for _, part := range strings.Split(field, ",") {
+
+		step := 1
+
+		// step values, ex: */15
+		if i := strings.Index(part, "/"); i >= 0 {
+
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step %s", part)
+			}
+
+			step = n
+			part = part[:i]
+		}
+
+		start, end := min, max
+
+		if part != "*" {
+
+			bounds := strings.SplitN(part, "-", 2)
+
+			n, err := parseCronValue(bounds[0], min, names)
+			if err != nil {
+				return nil, err
+			}
+
+			start, end = n, n
+
+			if len(bounds) == 2 {
+
+				end, err = parseCronValue(bounds[1], min, names)
+				if err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				// a single value with a step runs to the end, ex: 5/15
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("value out of range %s", part)
+		}
+
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
+
+// parseCronValue takes a number or name from a cron field and returns its value
+func parseCronValue(value string, min int, names []string) (int, error) {
+
+	for i, name := range names {
+		if value == name {
+			return i + min, nil
+		}
+	}
+
+	return strconv.Atoi(value)
+}
+
+// next returns the first time matching the spec after t, in t's location
+// returns the zero time if nothing matches within five years, ex: 0 0 30 2 *
+// the spec is matched against the wall clock, so when clocks fall back the
+// repeated hour does not run twice, and times skipped when clocks spring
+// forward run at the same time after the change, the same as cron
+func (s *cronSpec) next(t time.Time) time.Time {
+
+	loc := t.Location()
+
+	// search the wall clock in utc, which has no daylight saving changes
+	// cron runs on whole minutes
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC).Add(time.Minute)
+
+	limit := wall.AddDate(5, 0, 0)
+
+	for wall.Before(limit) {
+
+		if !s.month[int(wall.Month())] {
+			wall = time.Date(wall.Year(), wall.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !s.dayMatches(wall) {
+			wall = time.Date(wall.Year(), wall.Month(), wall.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !s.hour[wall.Hour()] {
+			wall = time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !s.minute[wall.Minute()] {
+			wall = wall.Add(time.Minute)
+			continue
+		}
+
+		next := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, loc)
+
+		// wall clock times skipped by clocks springing forward resolve to
+		// a different wall clock, move them to the same time after the change
+		if next.Hour() != wall.Hour() || next.Minute() != wall.Minute() {
+			next = next.Add(wall.Sub(time.Date(next.Year(), next.Month(), next.Day(), next.Hour(), next.Minute(), 0, 0, time.UTC)))
+		}
+
+		// do not run again at or before t
+		if !next.After(t) {
+			wall = wall.Add(time.Minute)
+			continue
+		}
+
+		return next
+	}
+
+	return time.Time{}
+}
+
+// dayMatches returns true if the day of t matches the day of month and day of week fields
+func (s *cronSpec) dayMatches(t time.Time) bool {
+
+	dom := s.dom[t.Day()]
+	dow := s.dow[int(t.Weekday())]
+
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	}
+
+	return dom || dow
+}
@@ -0,0 +1,167 @@
+package skelly
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+
+	tests := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{spec: "0 9 * * mon-fri"},
+		{spec: "*/15 * * * *"},
+		{spec: "5/20 8-17 1,15 jan-jun 7"},
+		{spec: "0 0 30 2 *"},
+		{spec: "@daily"},
+		{spec: " @Weekly "},
+		{spec: "0 9 * *", wantErr: true},
+		{spec: "0 9 * * * *", wantErr: true},
+		{spec: "60 * * * *", wantErr: true},
+		{spec: "0 24 * * *", wantErr: true},
+		{spec: "0 0 0 * *", wantErr: true},
+		{spec: "0 0 * 13 *", wantErr: true},
+		{spec: "0 0 * * 8", wantErr: true},
+		{spec: "0 0 * * fri-mon", wantErr: true},
+		{spec: "*/0 * * * *", wantErr: true},
+		{spec: "0 0 * * someday", wantErr: true},
+		{spec: "@often", wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		_, err := parseCron(test.spec)
+
+		if test.wantErr && err == nil {
+			t.Errorf("parseCron(%q) returned no error", test.spec)
+		}
+
+		if !test.wantErr && err != nil {
+			t.Errorf("parseCron(%q) returned error: %v", test.spec, err)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Fatalf("could not load time zone: %v", err)
+	}
+
+	// central daylight and standard time
+	cdt := time.FixedZone("CDT", -5*60*60)
+	cst := time.FixedZone("CST", -6*60*60)
+
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "next minute",
+			spec: "* * * * *",
+			from: time.Date(2021, 1, 4, 9, 0, 30, 0, time.UTC),
+			want: time.Date(2021, 1, 4, 9, 1, 0, 0, time.UTC),
+		},
+		{
+			name: "weekdays skip the weekend",
+			spec: "0 9 * * mon-fri",
+			from: time.Date(2021, 1, 8, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2021, 1, 11, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "steps",
+			spec: "*/15 * * * *",
+			from: time.Date(2021, 1, 4, 9, 50, 0, 0, time.UTC),
+			want: time.Date(2021, 1, 4, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "leap day",
+			spec: "0 0 29 2 *",
+			from: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "never",
+			spec: "0 0 30 2 *",
+			from: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: time.Time{},
+		},
+		{
+			name: "day of month or week matches the day of week",
+			spec: "0 0 13 * fri",
+			from: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2021, 1, 8, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month or week matches the day of month",
+			spec: "0 0 13 * fri",
+			from: time.Date(2021, 1, 9, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2021, 1, 13, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "any day of month only matches the day of week",
+			spec: "0 0 * * fri",
+			from: time.Date(2021, 1, 9, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2021, 1, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "sunday as 7",
+			spec: "0 0 * * 7",
+			from: time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2021, 1, 10, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "fall back does not repeat the hour",
+			spec: "0 1 * * *",
+			from: time.Date(2021, 11, 7, 1, 0, 0, 0, cdt).In(chicago),
+			want: time.Date(2021, 11, 8, 1, 0, 0, 0, cst),
+		},
+		{
+			name: "fall back does not repeat the minutes",
+			spec: "*/30 * * * *",
+			from: time.Date(2021, 11, 7, 1, 30, 0, 0, cdt).In(chicago),
+			want: time.Date(2021, 11, 7, 2, 0, 0, 0, cst),
+		},
+		{
+			name: "fall back from within the repeated hour",
+			spec: "0 1 * * *",
+			from: time.Date(2021, 11, 7, 1, 10, 0, 0, cst).In(chicago),
+			want: time.Date(2021, 11, 8, 1, 0, 0, 0, cst),
+		},
+		{
+			name: "spring forward runs skipped times after the change",
+			spec: "30 2 * * *",
+			from: time.Date(2021, 3, 14, 0, 0, 0, 0, cst).In(chicago),
+			want: time.Date(2021, 3, 14, 3, 30, 0, 0, cdt),
+		},
+		{
+			name: "spring forward",
+			spec: "0 9 * * *",
+			from: time.Date(2021, 3, 13, 9, 0, 0, 0, cst).In(chicago),
+			want: time.Date(2021, 3, 14, 9, 0, 0, 0, cdt),
+		},
+	}
+
+	for _, test := range tests {
+
+		spec, err := parseCron(test.spec)
+		if err != nil {
+			t.Fatalf("%s: parseCron(%q) returned error: %v", test.name, test.spec, err)
+		}
+
+		got := spec.next(test.from)
+
+		if !got.Equal(test.want) {
+			t.Errorf("%s: next(%q, %s) = %s, want %s", test.name, test.spec, test.from, got, test.want)
+		}
+
+		if !got.IsZero() && got.Location() != test.from.Location() {
+			t.Errorf("%s: next(%q) location = %s, want %s", test.name, test.spec, got.Location(), test.from.Location())
+		}
+	}
+}
@@ -1,63 +1,112 @@
 package skelly
 
 import (
-	"os"
-	"strings"
+	"context"
+	"fmt"
 
-	"github.com/davidvader/skelly/db"
-	"github.com/davidvader/skelly/util"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/types"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 )
 
 // openDeleteModal takes slash command configuration and responds
 // to the triggering user with a dialog window for deleting an existing
 // reaction from the skelly database
-func openDeleteModal(s *slack.SlashCommand, command string, args []string) error {
+// ex: /skelly delete --id 5f1d7a3c9e1b2a0001a1b2c3
+func openDeleteModal(ctx context.Context, s *slack.SlashCommand, command string, args []string) error {
 
-	channel := s.ChannelID
-	user := s.UserID
-	triggerID := s.TriggerID
+	// parse the reaction to delete, if picked
+	id, err := parseIDArg(args[1:])
+	if err != nil {
+
+		text := fmt.Sprintf("Sorry, %s.", err)
+
+		// retrieve the bot token for the workspace
+		bToken, err := botToken(ctx, s.TeamID)
+		if err != nil {
+			err = errors.Wrap(err, "could not get bot token")
+			return err
+		}
+
+		// notify user
+		return notifyUser(ctx, bToken, text, s.ChannelID, s.UserID)
+	}
+
+	return showDeleteModal(ctx, s.TeamID, s.ChannelID, id, s.ChannelID, s.UserID, s.TriggerID)
+}
+
+// showDeleteModal opens the modal for deleting the reaction with id from channel, selected by default
+// the channel's only reaction is deleted when id is empty
+// errors are sent to the user in origin, the channel or direct message the modal was opened from
+func showDeleteModal(ctx context.Context, team, channel, id, origin, user, triggerID string) error {
 
-	// attempt to retrieve an existing reaction
-	exists, _, err := db.ReactionExists(channel)
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
 	if err != nil {
-		err = errors.Wrap(err, "could not check for reaction")
+		err = errors.Wrap(err, "could not get bot token")
 		return err
 	}
 
-	// if reaction does not exist
-	if !exists {
+	// attempt to retrieve an existing reaction, unless opened from a direct message
+	reactionID, reactions := "", []types.Reaction{}
+	if !isDirectMessage(channel) {
 
-		logrus.Infof("reaction does not exist for channel(%s)", channel)
+		reaction, err := findReaction(ctx, team, channel, id)
+
+		// the user needs to pick one of the channel's reactions
+		if several, ok := err.(*severalReactionsError); ok {
+
+			logging.FromContext(ctx).Infof("several reactions exist for channel(%s)", channel)
+
+			// notify user
+			return notifyUser(ctx, bToken, several.Message("/skelly "+deleteSubCommand), origin, user)
+		}
 
-		// notify user
-		err = util.SendError("Sorry, that reaction does not exist for this channel. Did you mean to add?", channel, user)
 		if err != nil {
-			err = errors.Wrap(err, "could not send error")
+			err = errors.Wrap(err, "could not find reaction")
 			return err
 		}
 
-		return nil
+		// if reaction does not exist
+		if reaction == nil {
+
+			logging.FromContext(ctx).Infof("reaction(%s) does not exist for channel(%s)", id, channel)
+
+			// notify user
+			return notifyUser(ctx, bToken, missingReaction(channel, id), origin, user)
+		}
+
+		// show the reaction that will be deleted
+		reactionID = reaction.ID.Hex()
+		reactions = append(reactions, *reaction)
 	}
 
 	// build default modal
-	// uses channel and slash command as metadata
-	metadata := strings.Join([]string{deleteSubCommand, channel}, " ")
+	// uses signed subcommand, team, channel and user as metadata
+	metadata, err := encodeViewMetadata(&viewMetadata{
+		SubCommand: deleteSubCommand,
+		Team:       team,
+		Channel:    channel,
+		Reaction:   reactionID,
+		User:       user,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "could not encode metadata")
+		return err
+	}
 
 	modal := deleteModal(deleteSubCommand,
-		metadata)
+		metadata, channel, reactions)
 
-	logrus.Infof("opening delete modal for channel(%s) trigger_id(%s)", channel, triggerID)
+	logging.FromContext(ctx).Debugf("opening delete modal for channel(%s) trigger_id(%s)", channel, triggerID)
 
 	// create an api client
-	bToken := os.Getenv("SKELLY_BOT_TOKEN")
-
-	api := slack.New(bToken)
+	api := slackapi.For(bToken)
 
 	// open modal view
-	_, err = api.OpenView(triggerID, modal)
+	_, err = api.OpenView(ctx, triggerID, modal)
 	if err != nil {
 		err = errors.Wrap(err, "could not open view")
 		return err
@@ -66,67 +115,131 @@ func openDeleteModal(s *slack.SlashCommand, command string, args []string) error
 }
 
 // handleDeleteSubmission takes slack view, extracts args, and attempts to delete a reaction from the database
-func handleDeleteSubmission(view *slack.View, user, responseURL string) error {
+// invalid input is returned as errors to show in the modal, otherwise a confirmation replaces the modal
+func handleDeleteSubmission(ctx context.Context, view *slack.View, team, user string) (*slack.ViewSubmissionResponse, error) {
 
-	// parse out args from private metadata
-	// ex: META:CHANNEL_ID
-	channel, err := parseViewMetadata(view)
+	// parse and verify the private metadata
+	metadata, err := parseViewMetadata(view, team, user)
 	if err != nil {
 		err = errors.Wrap(err, "could not parse metadata")
-		return err
+		return nil, err
+	}
+
+	// parse the selected channel, defaulting to the channel the modal was opened for
+	channel, err := parseViewChannel(view, metadata.Channel)
+	if err != nil {
+		err = errors.Wrap(err, "could not parse channel")
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debugf("parsed metadata channel(%s) selected channel(%s)", metadata.Channel, channel)
+
+	// validate input, keeping the modal open with the errors
+	invalid := map[string]string{}
+
+	// reactions belong to channels, not direct messages
+	if isDirectMessage(channel) {
+		invalid["Channel"] = "Reactions can only be managed for channels. Please select a channel."
 	}
 
-	logrus.Infof("parsed metadata channel(%s)", channel)
+	if len(invalid) > 0 {
+		logging.FromContext(ctx).Debugf("invalid delete submission for channel(%s): %v", channel, invalid)
+		return slack.NewErrorsViewSubmissionResponse(invalid), nil
+	}
 
-	// check for reaction in the database
-	exists, _, err := db.ReactionExists(channel)
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
 	if err != nil {
-		err = errors.Wrap(err, "could not check for reaction in db")
-		return err
+		err = errors.Wrap(err, "could not get bot token")
+		return nil, err
 	}
 
-	if !exists {
+	// check that the user may still manage reactions in the channel
+	allowed, err := authorize(ctx, bToken, team, channel, user, deleteSubCommand)
+	if err != nil {
+		err = errors.Wrap(err, "could not authorize user")
+		return nil, err
+	}
 
-		logrus.Infof("reaction does not exist for channel(%s)", channel)
+	if !allowed {
 
-		// notify user
-		err = util.SendError("Sorry, that reaction does not exist for this channel. Did you mean to add?", channel, user)
-		if err != nil {
-			err = errors.Wrap(err, "could not send error")
-			return err
-		}
+		logging.FromContext(ctx).Infof("user(%s) is not allowed to delete reactions in channel(%s)", user, channel)
+
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			"Channel": "You are not allowed to delete reactions in this channel. Ask a workspace admin or a skelly admin for help.",
+		}), nil
+	}
 
-		return nil
+	// delete the reaction shown in the modal, unless another channel was selected
+	id := ""
+	if channel == metadata.Channel {
+		id = metadata.Reaction
+	}
+
+	// retrieve the reaction from the database
+	reaction, err := findReaction(ctx, team, channel, id)
+
+	// the user needs to pick one of the channel's reactions
+	if _, ok := err.(*severalReactionsError); ok {
+
+		logging.FromContext(ctx).Infof("several reactions exist for channel(%s)", channel)
+
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			"Channel": "This channel has several reactions. Use `/skelly delete --id <id>` in it to pick one.",
+		}), nil
 	}
 
-	// delete reaction in the database
-	n, err := db.DeleteReactions(channel)
 	if err != nil {
-		err = errors.Wrap(err, "could not delete reactions from db")
-		return err
+		err = errors.Wrap(err, "could not find reaction in db")
+		return nil, err
 	}
 
-	logrus.Infof("removed (%v) reactions for channel(%s)", n, channel)
+	if reaction == nil {
 
-	text := slack.NewTextBlockObject("mrkdwn", "I've deleted the reaction for this channel!", false, false)
+		logging.FromContext(ctx).Infof("reaction(%s) does not exist for channel(%s)", id, channel)
 
-	section := slack.NewSectionBlock(text, nil, nil)
+		// the reaction shown in the modal was deleted after it was opened
+		if len(id) > 0 {
+			return slack.NewErrorsViewSubmissionResponse(map[string]string{
+				"Channel": "This reaction was already deleted. Please close the modal.",
+			}), nil
+		}
 
-	// create default msg options
-	options := []slack.MsgOption{
-		slack.MsgOptionBlocks(section),
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			"Channel": "A reaction does not exist for this channel.",
+		}), nil
 	}
 
-	// create an api client
-	bToken := os.Getenv("SKELLY_BOT_TOKEN")
+	// check that the user may change the reaction
+	allowed, err = canChange(ctx, bToken, user, reaction)
+	if err != nil {
+		err = errors.Wrap(err, "could not authorize user")
+		return nil, err
+	}
 
-	api := slack.New(bToken)
+	if !allowed {
 
-	// post the confirmation
-	_, err = api.PostEphemeral(channel, user, options...)
+		logging.FromContext(ctx).Infof("user(%s) is not allowed to delete reaction(%s)", user, reaction.ID.Hex())
+
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			"Channel": "Only the creator of this reaction or an admin may delete it.",
+		}), nil
+	}
+
+	// move the reaction into the trash
+	trash, err := trashReactions(ctx, team, channel, reaction.ID.Hex(), user, sourceModal)
 	if err != nil {
-		err = errors.Wrap(err, "could not post response")
-		return err
+		err = errors.Wrap(err, "could not trash reactions")
+		return nil, err
 	}
-	return nil
+
+	// offer to undo the delete in the channel, the modal is still confirmed if this fails
+	err = postUndo(ctx, bToken, trash, user)
+	if err != nil {
+		logging.FromContext(ctx).Infof("could not post undo for channel(%s): %v", channel, err)
+	}
+
+	// show the confirmation
+	return slack.NewUpdateViewSubmissionResponse(confirmationModal(deleteSubCommand,
+		fmt.Sprintf("I've deleted the reaction for <#%s>! You can undo this for %s.", channel, formatCooldown(trashRetention())))), nil
 }
@@ -1,14 +1,20 @@
 package skelly
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/tracing"
 	"github.com/davidvader/skelly/util"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack/slackevents"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -17,24 +23,33 @@ const (
 
 // HandleEvent takes gin context and checks if request is a slack api url challenge
 // if required, responds with the provided challenge string
-func HandleEvent(c *gin.Context, body []byte, e *slackevents.EventsAPIEvent, bToken string) error {
+func HandleEvent(ctx context.Context, c *gin.Context, body []byte, e *slackevents.EventsAPIEvent) error {
+
+	ctx, span := tracing.Start(ctx, "skelly.HandleEvent",
+		attribute.String("skelly.event", e.Type),
+		attribute.String("skelly.inner_event", e.InnerEvent.Type))
+	defer span.End()
 
 	// verify the router url with the slack api, if needed
-	verification, err := verifyURL(c, body, e.Type)
+	verification, err := verifyURL(ctx, c, body, e.Type)
 	if err != nil {
 		err = errors.Wrap(err, "could not verify url")
-		return err
+		return tracing.Error(span, err)
 	}
 
 	// if the request is a url verification, exit
 	if verification {
-		logrus.Info("url verified")
+		logging.FromContext(ctx).Info("url verified")
 		return nil
 	}
 
 	// acknowledge request
 	util.RespondOK(c)
 
+	// detach from the request so the event can be handled
+	// after the http connection is closed
+	ctx = tracing.Detach(ctx)
+
 	go func() {
 
 		// handle the inner callback event
@@ -44,44 +59,256 @@ func HandleEvent(c *gin.Context, body []byte, e *slackevents.EventsAPIEvent, bTo
 			// extract inner event
 			innerEvent := e.InnerEvent
 
-			switch innerEvent.Data.(type) {
+			switch ev := innerEvent.Data.(type) {
+
+			// message posted in a channel
+			case *slackevents.MessageEvent:
+
+				// ignore bots, including skelly, and message subtypes such as edits and joins
+				if len(ev.BotID) > 0 || len(ev.SubType) > 0 {
+					return
+				}
+
+				ctx := logging.WithFields(ctx, logrus.Fields{
+					"channel": ev.Channel,
+					"user":    ev.User,
+				})
+
+				logging.FromContext(ctx).Debugf("received message event for channel(%s) user(%s) ts(%s)", ev.Channel, ev.User, ev.TimeStamp)
+
+				// react to the message
+				err := handleMessage(ctx, e.TeamID, ev)
+				if err != nil {
+					err = errors.Wrap(err, "could not handle message")
+					logging.FromContext(ctx).Error(err)
+					return
+				}
+				return
+
+			// skelly mentioned in a channel
+			case *slackevents.AppMentionEvent:
+
+				// ignore bots, including skelly
+				if len(ev.BotID) > 0 {
+					return
+				}
+
+				ctx := logging.WithFields(ctx, logrus.Fields{
+					"channel": ev.Channel,
+					"user":    ev.User,
+				})
+
+				logging.FromContext(ctx).Debugf("received app mention event for channel(%s) user(%s) ts(%s)", ev.Channel, ev.User, ev.TimeStamp)
+
+				// react to the mention
+				err := handleMention(ctx, e.TeamID, ev)
+				if err != nil {
+					err = errors.Wrap(err, "could not handle mention")
+					logging.FromContext(ctx).Error(err)
+					return
+				}
+				return
+
+			// member joined a channel
+			case *slackevents.MemberJoinedChannelEvent:
+
+				ctx := logging.WithFields(ctx, logrus.Fields{
+					"channel": ev.Channel,
+					"user":    ev.User,
+				})
+
+				// the event id identifies the join, retries share it
+				id := ""
+				if cb, ok := e.Data.(*slackevents.EventsAPICallbackEvent); ok {
+					id = cb.EventID
+				}
+
+				logging.FromContext(ctx).Debugf("received member joined channel event for channel(%s) user(%s) event(%s)", ev.Channel, ev.User, id)
+
+				// welcome the member
+				err := handleMemberJoined(ctx, e.TeamID, id, ev)
+				if err != nil {
+					err = errors.Wrap(err, "could not handle member joined")
+					logging.FromContext(ctx).Error(err)
+					return
+				}
+				return
+
+			// app home opened by a user
+			case *slackevents.AppHomeOpenedEvent:
+
+				ctx := logging.WithFields(ctx, logrus.Fields{
+					"channel": ev.Channel,
+					"user":    ev.User,
+				})
+
+				logging.FromContext(ctx).Debugf("received app home opened event for user(%s) tab(%s)", ev.User, ev.Tab)
+
+				// publish the home view
+				err := handleAppHomeOpened(ctx, e.TeamID, ev)
+				if err != nil {
+					err = errors.Wrap(err, "could not handle app home opened")
+					logging.FromContext(ctx).Error(err)
+					return
+				}
+				return
 
-			// reaction added event
-			// case *slackevents.ReactionAddedEvent:
+			// emoji added to an item
+			case *slackevents.ReactionAddedEvent:
 
-			// 	logrus.Infof("received reaction added event for event_ts(%s) item_type(%s) item_ts(%s)", ev.EventTimestamp, ev.Item.Type, ev.Item.Timestamp)
+				// only messages have a thread to respond in
+				if ev.Item.Type != "message" {
+					return
+				}
 
-			// 	// react to the emoji
-			// 	err := React(bToken, ev.Item.Channel, ev.Reaction, ev.User, ev.Item.Timestamp)
-			// 	if err != nil {
-			// 		err = errors.Wrap(err, "could not react")
-			// 		logrus.Error(err)
-			// 		return
-			// 	}
-			// 	return
+				ctx := logging.WithFields(ctx, logrus.Fields{
+					"channel": ev.Item.Channel,
+					"user":    ev.User,
+				})
+
+				logging.FromContext(ctx).Debugf("received reaction added event for channel(%s) user(%s) emoji(%s) item_ts(%s)", ev.Item.Channel, ev.User, ev.Reaction, ev.Item.Timestamp)
+
+				// react to the emoji
+				err := handleReactionAdded(ctx, e.TeamID, ev)
+				if err != nil {
+					err = errors.Wrap(err, "could not handle reaction added")
+					logging.FromContext(ctx).Error(err)
+					return
+				}
+				return
 
 			// unsupported inner event type
 			default:
-				logrus.Warn("received unsupported inner event callback type: ", e.Type)
+				logging.FromContext(ctx).Debugf("received unsupported inner event type(%s)", innerEvent.Type)
 				break
 			}
 
 		// unsupported outer event type
 		default:
-			logrus.Warn("received unsupported outer event type: ", e.Type)
+			logging.FromContext(ctx).Debugf("received unsupported outer event type(%s)", e.Type)
 			break
 		}
 	}()
 	return nil
 }
 
+// handleMessage takes a message event and reacts to it in the message's thread
+func handleMessage(ctx context.Context, team string, ev *slackevents.MessageEvent) error {
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	// respond in the thread the message belongs to
+	ts := ev.ThreadTimeStamp
+	if len(ts) == 0 {
+		ts = ev.TimeStamp
+	}
+
+	// answer for mentioned users that are out of office, the reactions still respond if this fails
+	err = respondOutOfOffice(ctx, bToken, team, ev.Channel, ev.User, ts, ev.Text)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("could not respond out of office: %v", err)
+	}
+
+	err = React(ctx, bToken, team, ev.Channel, ev.User, ts, triggerMessage, ev.Text)
+	if err != nil {
+		err = errors.Wrap(err, "could not react")
+		return err
+	}
+
+	return nil
+}
+
+// handleMention takes an app mention event and reacts to it in the message's thread
+func handleMention(ctx context.Context, team string, ev *slackevents.AppMentionEvent) error {
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	// respond in the thread the message belongs to
+	ts := ev.ThreadTimeStamp
+	if len(ts) == 0 {
+		ts = ev.TimeStamp
+	}
+
+	err = React(ctx, bToken, team, ev.Channel, ev.User, ts, triggerMention, ev.Text)
+	if err != nil {
+		err = errors.Wrap(err, "could not react")
+		return err
+	}
+
+	return nil
+}
+
+// handleReactionAdded takes a reaction added event and reacts to the emoji in the message's thread
+func handleReactionAdded(ctx context.Context, team string, ev *slackevents.ReactionAddedEvent) error {
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	// skin tones are the same emoji, ex: thumbsup::skin-tone-2
+	emoji := strings.SplitN(ev.Reaction, "::", 2)[0]
+
+	err = React(ctx, bToken, team, ev.Item.Channel, ev.User, ev.Item.Timestamp, emojiTrigger(emoji), "")
+	if err != nil {
+		err = errors.Wrap(err, "could not react")
+		return err
+	}
+
+	return nil
+}
+
+// handleMemberJoined takes a member joined channel event and reacts to it in the channel
+// id identifies the join so it is only responded to once
+func handleMemberJoined(ctx context.Context, team, id string, ev *slackevents.MemberJoinedChannelEvent) error {
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	// do not welcome skelly to the channel
+	auth, err := slackapi.For(bToken).AuthTest(ctx)
+	if err != nil {
+		err = errors.Wrap(err, "could not authenticate bot token")
+		return err
+	}
+
+	if ev.User == auth.UserID {
+		logging.FromContext(ctx).Debugf("skipping, skelly joined channel(%s)", ev.Channel)
+		return nil
+	}
+
+	err = React(ctx, bToken, team, ev.Channel, ev.User, id, triggerJoin, "")
+	if err != nil {
+		err = errors.Wrap(err, "could not react")
+		return err
+	}
+
+	return nil
+}
+
 // verifyURL takes gin context and request body and verifies the challenge presented by the Slack API
-func verifyURL(c *gin.Context, body []byte, eventType string) (bool, error) {
+func verifyURL(ctx context.Context, c *gin.Context, body []byte, eventType string) (bool, error) {
 
 	// check if request is the slack api verifying the events url
 	if eventType == slackevents.URLVerification {
 
-		logrus.Info("verifying url")
+		logging.FromContext(ctx).Debug("verifying url")
 
 		// read the slack api's url challenge
 		r := new(slackevents.ChallengeResponse)
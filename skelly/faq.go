@@ -0,0 +1,399 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/types"
+	"github.com/davidvader/skelly/util"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// kindFAQ answers messages similar to one of the reaction's questions
+	kindFAQ = "faq"
+
+	// defaultFAQThreshold is how similar a message must be to a question to be answered
+	defaultFAQThreshold = 0.5
+
+	// faqHelpfulAction records that an faq answer answered the question
+	faqHelpfulAction = "faq_helpful"
+	// faqUnhelpfulAction records that an faq answer did not answer the question
+	faqUnhelpfulAction = "faq_unhelpful"
+)
+
+// faqMarkup matches slack markup such as mentions, channels and links, ex: <@U123>
+var faqMarkup = regexp.MustCompile(`<[^>]*>`)
+
+// faqStopWords are common words that do not tell questions apart
+var faqStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "any": true, "anyone": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "can": true, "could": true, "do": true, "does": true, "for": true, "from": true,
+	"get": true, "hello": true, "hey": true, "hi": true, "how": true, "i": true, "if": true, "in": true,
+	"is": true, "it": true, "know": true, "me": true, "my": true, "of": true, "on": true, "or": true,
+	"please": true, "should": true, "so": true, "someone": true, "that": true, "the": true, "there": true,
+	"this": true, "to": true, "we": true, "what": true, "when": true, "where": true, "which": true,
+	"who": true, "why": true, "will": true, "with": true, "would": true, "you": true, "your": true,
+}
+
+// FAQOptions are the raw values for answering questions
+// ex: --faq "How do I reset my password?" --faq "forgot password" --threshold 0.4
+type FAQOptions struct {
+	// Questions are the phrasings of the question, replacing existing questions
+	Questions []string
+	// Threshold is how similar a message must be to a question to be answered, from 0 to 1
+	Threshold string
+	// Clear turns the reaction back into a typing reaction before applying the other values
+	Clear bool
+}
+
+// Empty returns true if no faq options were provided
+func (o *FAQOptions) Empty() bool {
+	return len(o.Questions) == 0 && len(o.Threshold) == 0 && !o.Clear
+}
+
+// applyFAQ takes a reaction and applies the faq options to it
+// returns an error meant for showing to the user if the options cannot be used
+func applyFAQ(r *types.Reaction, o *FAQOptions) error {
+
+	if o.Clear {
+		r.Kind = ""
+		r.Questions = nil
+		r.Threshold = 0
+	}
+
+	// replace the questions
+	if len(o.Questions) > 0 {
+
+		for _, q := range o.Questions {
+			if len(faqTokens(q)) == 0 {
+				return fmt.Errorf("the question %q has no words to match", q)
+			}
+		}
+
+		r.Kind = kindFAQ
+		r.Questions = o.Questions
+	}
+
+	// parse the threshold
+	if len(o.Threshold) > 0 {
+
+		t, err := strconv.ParseFloat(o.Threshold, 64)
+		if err != nil || t <= 0 || t > 1 {
+			return fmt.Errorf("invalid threshold %s, ex: 0.4", o.Threshold)
+		}
+
+		r.Threshold = t
+	}
+
+	if r.Kind != kindFAQ {
+
+		if r.Threshold > 0 {
+			return errors.New("a threshold requires questions, ex: --faq \"How do I reset my password?\"")
+		}
+
+		return nil
+	}
+
+	// questions are asked in messages
+	switch reactionTrigger(r) {
+	case triggerMessage, triggerMention:
+	default:
+		return errors.New("faq reactions answer messages, use the message or mention trigger")
+	}
+
+	return nil
+}
+
+// faqThreshold takes an faq reaction and returns how similar a message must be to a question to be answered
+func faqThreshold(r *types.Reaction) float64 {
+
+	if r.Threshold > 0 {
+		return r.Threshold
+	}
+
+	return defaultFAQThreshold
+}
+
+// faqTokens takes text and returns its words for matching, lowercased, without stop words and naively stemmed
+func faqTokens(text string) []string {
+
+	text = faqMarkup.ReplaceAllString(strings.ToLower(text), " ")
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+
+	tokens := []string{}
+
+	for _, w := range words {
+
+		w = strings.TrimSuffix(strings.Trim(w, "'"), "'s")
+
+		if len(w) < 2 || faqStopWords[w] {
+			continue
+		}
+
+		tokens = append(tokens, faqStem(w))
+	}
+
+	return tokens
+}
+
+// faqStem takes a word and strips common suffixes so forms of a word match, ex: resetting is reset
+func faqStem(w string) string {
+
+	stem := w
+
+	switch {
+	case len(w) > 5 && strings.HasSuffix(w, "ing"):
+		stem = strings.TrimSuffix(w, "ing")
+	case len(w) > 4 && strings.HasSuffix(w, "ed"):
+		stem = strings.TrimSuffix(w, "ed")
+	case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		stem = strings.TrimSuffix(w, "s")
+	}
+
+	// doubled consonants left by a suffix, ex: resett, words without one keep theirs, ex: class
+	if n := len(stem); stem != w && n > 3 && stem[n-1] == stem[n-2] && !strings.ContainsRune("aeiou", rune(stem[n-1])) {
+		stem = stem[:n-1]
+	}
+
+	return stem
+}
+
+// faqScore takes the phrasings of a question and a message and returns the highest
+// tf-idf cosine similarity between the message and a phrasing, from 0 to 1
+func faqScore(questions []string, text string) float64 {
+
+	query := faqTokens(text)
+	if len(query) == 0 {
+		return 0
+	}
+
+	docs := [][]string{}
+	for _, q := range questions {
+		docs = append(docs, faqTokens(q))
+	}
+
+	// count the documents each word appears in, including the message
+	df := map[string]int{}
+	for _, doc := range append(docs, query) {
+
+		seen := map[string]bool{}
+
+		for _, t := range doc {
+			if !seen[t] {
+				df[t]++
+				seen[t] = true
+			}
+		}
+	}
+
+	n := float64(len(docs) + 1)
+
+	// weigh words by how rarely they appear, smoothed so shared words still count
+	idf := func(t string) float64 {
+		return math.Log((1+n)/(1+float64(df[t]))) + 1
+	}
+
+	q := faqVector(query, idf)
+
+	best := 0.0
+	for _, doc := range docs {
+		if s := faqCosine(q, faqVector(doc, idf)); s > best {
+			best = s
+		}
+	}
+
+	return best
+}
+
+// bestFAQ takes a channel's reactions and a message and returns the id of the faq reaction that
+// answers it, the one most similar to the message above its threshold, along with every faq's score
+// only faq reactions responding to trigger are scored, the id is empty when none answer
+func bestFAQ(reactions []*types.Reaction, trigger, text string) (string, map[string]float64) {
+
+	best := ""
+	bestScore := 0.0
+	scores := map[string]float64{}
+
+	for _, r := range reactions {
+
+		if r.Kind != kindFAQ || len(r.Response) == 0 || reactionTrigger(r) != trigger {
+			continue
+		}
+
+		score := faqScore(r.Questions, text)
+		scores[r.ID.Hex()] = score
+
+		if score >= faqThreshold(r) && score > bestScore {
+			best = r.ID.Hex()
+			bestScore = score
+		}
+	}
+
+	return best, scores
+}
+
+// faqVector takes words and returns their tf-idf weights
+func faqVector(tokens []string, idf func(string) float64) map[string]float64 {
+
+	v := map[string]float64{}
+
+	for _, t := range tokens {
+		v[t]++
+	}
+
+	for t, tf := range v {
+		v[t] = tf * idf(t)
+	}
+
+	return v
+}
+
+// faqCosine returns the cosine similarity of two weighted word vectors
+func faqCosine(a, b map[string]float64) float64 {
+
+	dot, na, nb := 0.0, 0.0, 0.0
+
+	for t, w := range a {
+		dot += w * b[t]
+		na += w * w
+	}
+
+	for _, w := range b {
+		nb += w * w
+	}
+
+	if na == 0 || nb == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// faqFeedback takes an faq answer about to be posted and records it awaiting feedback
+// returns the blocks asking the user whether it answered their question
+func faqFeedback(ctx context.Context, r *types.Reaction, channel, user, ts, text string, score float64) ([]slack.Block, error) {
+
+	feedback := &types.Feedback{
+		Team:      r.Team,
+		Channel:   channel,
+		Reaction:  r.ID.Hex(),
+		User:      user,
+		Question:  text,
+		Score:     score,
+		Timestamp: ts,
+		Created:   time.Now().Unix(),
+	}
+
+	err := db.AddFeedback(ctx, feedback)
+	if err != nil {
+		err = errors.Wrap(err, "could not add feedback")
+		return nil, err
+	}
+
+	id := feedback.ID.Hex()
+
+	yes := slack.NewButtonBlockElement(faqHelpfulAction, id, slack.NewTextBlockObject("plain_text", "Yes", false, false)).WithStyle(slack.StylePrimary)
+	no := slack.NewButtonBlockElement(faqUnhelpfulAction, id, slack.NewTextBlockObject("plain_text", "No", false, false))
+
+	return []slack.Block{
+		slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", "Did this answer your question?", false, false)),
+		slack.NewActionBlock("faq_"+id, yes, no),
+	}, nil
+}
+
+// handleFAQFeedback takes a click on an faq answer's feedback buttons and records whether it answered the question
+func handleFAQFeedback(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error {
+
+	team := callback.Team.ID
+	user := callback.User.ID
+
+	logging.FromContext(ctx).Debugf("handling feedback(%s) action(%s)", action.Value, action.ActionID)
+
+	// retrieve the answer awaiting feedback
+	feedback, err := db.GetFeedback(ctx, action.Value)
+	if err != nil {
+		err = errors.Wrap(err, "could not get feedback")
+		return err
+	}
+
+	switch {
+	case feedback == nil:
+		return fmt.Errorf("feedback(%s) does not exist", action.Value)
+
+	case feedback.Team != team:
+		return fmt.Errorf("feedback(%s) does not belong to team(%s)", action.Value, team)
+
+	// only the user that asked knows if it was answered
+	case feedback.User != user:
+
+		err = util.Respond(ctx, callback.ResponseURL, slack.Msg{
+			ResponseType: slack.ResponseTypeEphemeral,
+			Text:         fmt.Sprintf("Sorry, only <@%s> can say whether this answered their question.", feedback.User),
+		})
+		if err != nil {
+			err = errors.Wrap(err, "could not respond")
+			return err
+		}
+
+		return nil
+	}
+
+	helpful := action.ActionID == faqHelpfulAction
+
+	feedback.Helpful = &helpful
+	feedback.Rated = time.Now().Unix()
+
+	err = db.UpdateFeedback(ctx, feedback)
+	if err != nil {
+		err = errors.Wrap(err, "could not update feedback")
+		return err
+	}
+
+	logging.FromContext(ctx).Infof("feedback(%s) for reaction(%s) helpful(%t) score(%.2f)", feedback.ID.Hex(), feedback.Reaction, helpful, feedback.Score)
+
+	thanks := "Thanks, glad that helped!"
+	if !helpful {
+		thanks = "Thanks for letting me know, someone in the channel should be able to help."
+	}
+
+	// replace the buttons with the thanks, keeping the answer
+	err = util.Respond(ctx, callback.ResponseURL, slack.Msg{
+		ResponseType:    slack.ResponseTypeInChannel,
+		ReplaceOriginal: true,
+		Text:            callback.Message.Text,
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", callback.Message.Text, false, false), nil, nil),
+			slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", thanks, false, false)),
+		}},
+	})
+	if err != nil {
+		err = errors.Wrap(err, "could not respond")
+		return err
+	}
+
+	return nil
+}
+
+// describeQuestions takes an faq reaction and describes the questions it answers
+func describeQuestions(r *types.Reaction) string {
+
+	quoted := []string{}
+	for _, q := range r.Questions {
+		quoted = append(quoted, fmt.Sprintf("“%s”", q))
+	}
+
+	return fmt.Sprintf("%s _(threshold %.2f)_", strings.Join(quoted, ", "), faqThreshold(r))
+}
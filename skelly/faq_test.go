@@ -0,0 +1,104 @@
+package skelly
+
+import (
+	"math"
+	"testing"
+
+	"github.com/davidvader/skelly/types"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestFAQStem(t *testing.T) {
+
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "reset", want: "reset"},
+		{word: "resetting", want: "reset"},
+		{word: "running", want: "run"},
+		{word: "sing", want: "sing"},
+		{word: "locked", want: "lock"},
+		{word: "stopped", want: "stop"},
+		{word: "added", want: "add"},
+		{word: "bed", want: "bed"},
+		{word: "passwords", want: "password"},
+		{word: "class", want: "class"},
+		{word: "classes", want: "classe"},
+		{word: "fall", want: "fall"},
+		{word: "bus", want: "bus"},
+	}
+
+	for _, test := range tests {
+
+		got := faqStem(test.word)
+
+		if got != test.want {
+			t.Errorf("faqStem(%q) = %q, want %q", test.word, got, test.want)
+		}
+	}
+}
+
+func TestFAQScore(t *testing.T) {
+
+	password := []string{"How do I reset my password?", "forgot password"}
+
+	tests := []struct {
+		name      string
+		questions []string
+		text      string
+		min       float64
+		max       float64
+	}{
+		{name: "same question", questions: password, text: "How do I reset my password?", min: 1, max: 1},
+		{name: "other forms of the words", questions: password, text: "resetting passwords", min: 1, max: 1},
+		{name: "markup is ignored", questions: password, text: "<@U123> reset my password <#C123|general>", min: 1, max: 1},
+		{name: "best phrasing", questions: password, text: "I forgot my password", min: 1, max: 1},
+		{name: "similar question", questions: password, text: "how can I reset the vpn password", min: defaultFAQThreshold, max: 1},
+		{name: "shares a word", questions: password, text: "where is the password policy for the wiki", min: 0.01, max: defaultFAQThreshold},
+		{name: "unrelated", questions: password, text: "lunch is in the kitchen", min: 0, max: 0},
+		{name: "only stop words", questions: password, text: "how do I?", min: 0, max: 0},
+		{name: "empty message", questions: password, text: "", min: 0, max: 0},
+		{name: "no questions", questions: []string{}, text: "reset my password", min: 0, max: 0},
+	}
+
+	for _, test := range tests {
+
+		got := faqScore(test.questions, test.text)
+
+		// scores are cosine similarities, allow for rounding
+		if got < test.min-1e-9 || got > test.max+1e-9 || math.IsNaN(got) {
+			t.Errorf("%s: faqScore(%q) = %.3f, want between %.3f and %.3f", test.name, test.text, got, test.min, test.max)
+		}
+	}
+}
+
+func TestBestFAQ(t *testing.T) {
+
+	password := &types.Reaction{ID: bson.NewObjectId(), Kind: kindFAQ, Response: "Reset it.", Questions: []string{"How do I reset my password?"}}
+	vpn := &types.Reaction{ID: bson.NewObjectId(), Kind: kindFAQ, Response: "Use the vpn.", Questions: []string{"How do I reset my vpn password?"}}
+	mention := &types.Reaction{ID: bson.NewObjectId(), Kind: kindFAQ, Trigger: triggerMention, Response: "Ask me.", Questions: []string{"How do I reset my password?"}}
+	typing := &types.Reaction{ID: bson.NewObjectId(), Response: "Hello!"}
+
+	tests := []struct {
+		name      string
+		reactions []*types.Reaction
+		text      string
+		want      string
+	}{
+		{name: "most similar", reactions: []*types.Reaction{vpn, password}, text: "how do I reset my password", want: password.ID.Hex()},
+		{name: "most similar first", reactions: []*types.Reaction{password, vpn}, text: "reset my vpn password", want: vpn.ID.Hex()},
+		{name: "below the threshold", reactions: []*types.Reaction{password, vpn}, text: "where is lunch", want: ""},
+		{name: "other trigger", reactions: []*types.Reaction{mention}, text: "how do I reset my password", want: ""},
+		{name: "not an faq", reactions: []*types.Reaction{typing}, text: "hello", want: ""},
+	}
+
+	for _, test := range tests {
+
+		got, scores := bestFAQ(test.reactions, triggerMessage, test.text)
+
+		if got != test.want {
+			t.Errorf("%s: bestFAQ() = %q, want %q, scores %v", test.name, got, test.want, scores)
+		}
+	}
+}
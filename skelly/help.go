@@ -1,13 +1,14 @@
 package skelly
 
 import (
+	"context"
 	"github.com/davidvader/skelly/util"
 	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 )
 
 // sendHelp responds to /skelly help with details on how to use Skelly via Slack
-func sendHelp(command, responseURL string) error {
+func sendHelp(ctx context.Context, command, responseURL string) error {
 
 	// echo the given command
 	given := slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", command, false, false), nil, nil)
@@ -22,22 +23,40 @@ func sendHelp(command, responseURL string) error {
 		slack.NewTextBlockObject("mrkdwn", "*Action*", false, false),
 		slack.NewTextBlockObject("mrkdwn", "/skelly help", false, false),
 		slack.NewTextBlockObject("mrkdwn", "prints commands and helpful information", false, false),
-		slack.NewTextBlockObject("mrkdwn", "/skelly add", false, false),
-		slack.NewTextBlockObject("mrkdwn", "trigger a response when users type in the channel", false, false),
+		slack.NewTextBlockObject("mrkdwn", "/skelly add [\"response\"] [--cooldown 1d] [--users @a @b] [--days mon-fri] [--hours 09:00-17:00] [--tz America/Chicago] [--from 2021-01-04] [--until 2021-01-08] [--trigger join] [--emoji ticket] [--faq \"question\"] [--dm]", false, false),
+		slack.NewTextBlockObject("mrkdwn", "trigger a response when users type in, join, mention skelly or add an emoji in the channel, opens a modal when no response is given", false, false),
 	}
 	commandsA := slack.NewSectionBlock(nil, t, nil)
 
 	// split commands due to field limit
 	t = []*slack.TextBlockObject{
-		slack.NewTextBlockObject("mrkdwn", "/skelly update", false, false),
-		slack.NewTextBlockObject("mrkdwn", "update a reaction in this channel", false, false),
-		slack.NewTextBlockObject("mrkdwn", "/skelly delete", false, false),
+		slack.NewTextBlockObject("mrkdwn", "/skelly update [--id id] [\"response\"] [--cooldown 1d] [--users @a @b] [--days ...] [--always]", false, false),
+		slack.NewTextBlockObject("mrkdwn", "update a reaction in this channel, only the given fields are changed, --id picks one when the channel has several", false, false),
+		slack.NewTextBlockObject("mrkdwn", "/skelly delete [--id id]", false, false),
 		slack.NewTextBlockObject("mrkdwn", "delete a reaction in this channel", false, false),
+		slack.NewTextBlockObject("mrkdwn", "/skelly pause | resume [--id id]", false, false),
+		slack.NewTextBlockObject("mrkdwn", "turn the reaction in this channel off or back on", false, false),
 		slack.NewTextBlockObject("mrkdwn", "/skelly list", false, false),
-		slack.NewTextBlockObject("mrkdwn", "lists all reactions in this channel", false, false),
+		slack.NewTextBlockObject("mrkdwn", "lists all reactions in this channel and their ids", false, false),
+		slack.NewTextBlockObject("mrkdwn", "/skelly list all", false, false),
+		slack.NewTextBlockObject("mrkdwn", "lists reactions for every channel in this workspace", false, false),
 	}
 	commandsB := slack.NewSectionBlock(nil, t, nil)
 
+	t = []*slack.TextBlockObject{
+		slack.NewTextBlockObject("mrkdwn", "/skelly announce \"message\" --cron \"0 9 * * mon-fri\" [--tz America/Chicago]", false, false),
+		slack.NewTextBlockObject("mrkdwn", "post a message in this channel on a schedule", false, false),
+		slack.NewTextBlockObject("mrkdwn", "/skelly announce list | delete <id>", false, false),
+		slack.NewTextBlockObject("mrkdwn", "list or delete the announcements in this channel", false, false),
+		slack.NewTextBlockObject("mrkdwn", "/skelly stats [all] [--since 7d]", false, false),
+		slack.NewTextBlockObject("mrkdwn", "how often reactions in this channel, or every channel, fired and why they were skipped", false, false),
+		slack.NewTextBlockObject("mrkdwn", "/skelly ooo on [\"message\"] | off | hours --days mon-fri --hours 09:00-17:00", false, false),
+		slack.NewTextBlockObject("mrkdwn", "reply for you when you're mentioned while out of office or outside your working hours", false, false),
+		slack.NewTextBlockObject("mrkdwn", "/skelly ooo channel [\"message\"] --days mon-fri --hours 09:00-17:00 | off", false, false),
+		slack.NewTextBlockObject("mrkdwn", "reply to messages in this channel outside of its working hours", false, false),
+	}
+	commandsC := slack.NewSectionBlock(nil, t, nil)
+
 	// footer
 	source := slack.NewTextBlockObject("mrkdwn", "Visit the <https://github.com/davidvader/skelly|GitHub  :github:>  repo for more info", false, false)
 	footer := slack.NewContextBlock("help_footer", source)
@@ -48,6 +67,7 @@ func sendHelp(command, responseURL string) error {
 		header,
 		commandsA,
 		commandsB,
+		commandsC,
 		footer,
 	}
 
@@ -55,7 +75,7 @@ func sendHelp(command, responseURL string) error {
 	msg := slack.NewBlockMessage(blocks...)
 
 	// respond using response url
-	err := util.Respond(responseURL, msg)
+	err := util.Respond(ctx, responseURL, msg)
 	if err != nil {
 		err = errors.Wrap(err, "could not respond")
 		return err
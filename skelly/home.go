@@ -0,0 +1,322 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+const (
+	// homeTab is the app home tab that shows the home view
+	homeTab = "home"
+
+	// homeEditAction opens the update modal for a reaction
+	homeEditAction = "home_edit"
+	// homePauseAction pauses or resumes a reaction
+	homePauseAction = "home_pause"
+	// homeDeleteAction opens the delete modal for a reaction
+	homeDeleteAction = "home_delete"
+
+	// homeReactionLimit adheres to the home view blocks limit, each reaction uses three blocks
+	homeReactionLimit = 30
+)
+
+// handleAppHomeOpened takes an app_home_opened event and publishes the home view for the user
+func handleAppHomeOpened(ctx context.Context, team string, ev *slackevents.AppHomeOpenedEvent) error {
+
+	// only the home tab shows the home view
+	if ev.Tab != homeTab {
+		return nil
+	}
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	err = publishHome(ctx, bToken, team, ev.User, ev.Channel)
+	if err != nil {
+		err = errors.Wrap(err, "could not publish home")
+		return err
+	}
+
+	return nil
+}
+
+// publishHome builds the home view listing every channel with reactions and publishes it for the user
+// origin is the direct message with skelly, stored as metadata for reporting errors from the view
+// users who may not list every reaction, the same as /skelly list all, are shown no channels
+func publishHome(ctx context.Context, bToken, team, user, origin string) error {
+
+	// check that the user may see reactions for every channel, including private channels
+	allowed, err := authorize(ctx, bToken, team, origin, user, listSubCommand)
+	if err != nil {
+		err = errors.Wrap(err, "could not authorize user")
+		return err
+	}
+
+	// show no channels, without summarizing them
+	if !allowed {
+
+		logging.FromContext(ctx).Infof("user(%s) is not allowed to list reactions in the home tab", user)
+
+		return publishHomeView(ctx, bToken, user, homeView(origin, false, &types.SkellyStats{}, nil, nil))
+	}
+
+	// summarize the channels with reactions
+	stats, err := getStats(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get stats")
+		return err
+	}
+
+	// list channels in a stable order
+	channels := []string{}
+	for c := range stats.Channels {
+		channels = append(channels, c)
+	}
+	sort.Strings(channels)
+
+	// every channel has at least one reaction
+	if len(channels) > homeReactionLimit {
+		channels = channels[:homeReactionLimit]
+	}
+
+	reactions := []types.Reaction{}
+	responses := map[string]int{}
+
+	for _, c := range channels {
+
+		// retrieve the reactions for the channel
+		r, err := db.GetChannelReactions(ctx, team, c)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("could not get reactions for channel(%s)", c))
+			return err
+		}
+
+		reactions = append(reactions, *r...)
+
+		// count the responses sent in the channel
+		n, err := db.CountResponses(ctx, team, c)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("could not count responses for channel(%s)", c))
+			return err
+		}
+
+		responses[c] = n
+	}
+
+	if len(reactions) > homeReactionLimit {
+		reactions = reactions[:homeReactionLimit]
+	}
+
+	logging.FromContext(ctx).Debugf("publishing home with (%v) reactions for user(%s)", len(reactions), user)
+
+	return publishHomeView(ctx, bToken, user, homeView(origin, allowed, stats, reactions, responses))
+}
+
+// publishHomeView takes a home view and publishes it for the user
+func publishHomeView(ctx context.Context, bToken, user string, view slack.HomeTabViewRequest) error {
+
+	// create an api client
+	api := slackapi.For(bToken)
+
+	// publish the home view
+	_, err := api.PublishView(ctx, user, view)
+	if err != nil {
+		err = errors.Wrap(err, "could not publish view")
+		return err
+	}
+
+	return nil
+}
+
+// homeView takes the reactions for a team and builds the home tab view
+// allowed is whether the user may list every reaction
+func homeView(origin string, allowed bool, stats *types.SkellyStats, reactions []types.Reaction, responses map[string]int) slack.HomeTabViewRequest {
+
+	// header
+	header := fmt.Sprintf("*Skelly* reacts to typing in %d channels. Use `/skelly add` in a channel to add a reaction.", stats.TotalChannels)
+	if !allowed {
+		header = "*Skelly* reacts to typing in channels. Only workspace admins and skelly admins may see every reaction here, use `/skelly list` in a channel to see its reactions."
+	}
+
+	t := slack.NewTextBlockObject("mrkdwn", header, false, false)
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(t, nil, nil),
+		slack.NewDividerBlock(),
+	}
+
+	// build blocks
+	for _, r := range reactions {
+
+		status := ""
+		if r.Paused {
+			status = " _(paused)_"
+		}
+
+		// build a view for the reaction
+		t := slack.NewTextBlockObject("mrkdwn",
+			fmt.Sprintf("<#%s>%s\n*Response*: %s\n*Trigger*: %s\n*Users*: %s\n*Cooldown*: %s\n*Schedule*: %s\n*Responses sent*: %d",
+				r.Channel, status, describeResponses(&r), describeTrigger(&r), describeUsers(r.Users), describeCooldown(r.Cooldown), describeSchedule(r.Schedule), responses[r.Channel]),
+			false, false)
+
+		// build the buttons for managing the reaction
+		pause := "Pause"
+		if r.Paused {
+			pause = "Resume"
+		}
+
+		// the buttons carry the channel and the reaction, a channel may have several reactions
+		value := homeActionValue(r.Channel, r.ID.Hex())
+
+		edit := slack.NewButtonBlockElement(homeEditAction, value, slack.NewTextBlockObject("plain_text", "Edit", false, false))
+		toggle := slack.NewButtonBlockElement(homePauseAction, value, slack.NewTextBlockObject("plain_text", pause, false, false))
+		remove := slack.NewButtonBlockElement(homeDeleteAction, value, slack.NewTextBlockObject("plain_text", "Delete", false, false)).
+			WithStyle(slack.StyleDanger)
+
+		blocks = append(blocks,
+			slack.NewSectionBlock(t, nil, nil),
+			slack.NewActionBlock("home_actions_"+r.ID.Hex(), edit, toggle, remove),
+			slack.NewDividerBlock(),
+		)
+	}
+
+	return slack.HomeTabViewRequest{
+		Type:            slack.VTHomeTab,
+		Blocks:          slack.Blocks{BlockSet: blocks},
+		PrivateMetadata: origin,
+	}
+}
+
+// handleHomeAction takes a button clicked in the home view and manages the reaction it is for
+func handleHomeAction(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error {
+
+	team := callback.Team.ID
+	user := callback.User.ID
+	channel, id := parseHomeActionValue(action.Value)
+
+	// errors are sent to the direct message with skelly
+	origin := callback.View.PrivateMetadata
+
+	logging.FromContext(ctx).Debugf("handling home action(%s) for channel(%s)", action.ActionID, channel)
+
+	// editing and pausing change the reaction
+	subcommand := updateSubCommand
+	if action.ActionID == homeDeleteAction {
+		subcommand = deleteSubCommand
+	}
+
+	// check that the user may manage the reaction
+	allowed, err := checkPermission(ctx, team, channel, origin, user, subcommand)
+	if err != nil {
+		err = errors.Wrap(err, "could not check permission")
+		return err
+	}
+
+	if !allowed {
+		return nil
+	}
+
+	switch action.ActionID {
+	case homeEditAction:
+		return showUpdateModal(ctx, team, channel, id, origin, user, callback.TriggerID)
+
+	case homeDeleteAction:
+		return showDeleteModal(ctx, team, channel, id, origin, user, callback.TriggerID)
+
+	case homePauseAction:
+		return togglePause(ctx, team, channel, id, origin, user)
+	}
+
+	return fmt.Errorf("unsupported home action(%s)", action.ActionID)
+}
+
+// togglePause takes a channel, pauses or resumes its reaction with id and refreshes the user's home view
+func togglePause(ctx context.Context, team, channel, id, origin, user string) error {
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	// retrieve the reaction from the database
+	reaction, err := findReaction(ctx, team, channel, id)
+
+	// buttons published before reactions were picked by id only carry the channel
+	if several, ok := err.(*severalReactionsError); ok {
+
+		logging.FromContext(ctx).Infof("several reactions exist for channel(%s)", channel)
+
+		// notify user
+		return notifyUser(ctx, bToken, several.Message("/skelly "+pauseSubCommand), origin, user)
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, "could not find reaction in db")
+		return err
+	}
+
+	if reaction == nil {
+
+		logging.FromContext(ctx).Infof("reaction(%s) does not exist for channel(%s)", id, channel)
+
+		// notify user
+		return notifyUser(ctx, bToken, missingReaction(channel, id), origin, user)
+	}
+
+	// check that the user may change the reaction
+	allowed, err := canChange(ctx, bToken, user, reaction)
+	if err != nil {
+		err = errors.Wrap(err, "could not authorize user")
+		return err
+	}
+
+	if !allowed {
+
+		logging.FromContext(ctx).Infof("user(%s) is not allowed to pause reaction(%s)", user, reaction.ID.Hex())
+
+		// notify user
+		return notifyUser(ctx, bToken, "Sorry, only the creator of this reaction or an admin may pause it.", origin, user)
+	}
+
+	err = setPaused(ctx, team, reaction, user, sourceHome, !reaction.Paused)
+	if err != nil {
+		err = errors.Wrap(err, "could not pause reaction")
+		return err
+	}
+
+	return publishHome(ctx, bToken, team, user, origin)
+}
+
+// homeActionValue takes a channel and a reaction id and returns the value for a home view button
+// ex: C123:5f1d7a3c9e1b2a0001a1b2c3
+func homeActionValue(channel, id string) string {
+	return channel + ":" + id
+}
+
+// parseHomeActionValue takes the value of a home view button and returns its channel and reaction id
+// the id is empty for buttons that only carry the channel
+func parseHomeActionValue(value string) (string, string) {
+
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
@@ -1,53 +1,160 @@
 package skelly
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/tracing"
 	"github.com/davidvader/skelly/util"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // HandleInteraction takes request body and interaction callback and executes the appropriate interaction
-func HandleInteraction(c *gin.Context, body string) error {
+func HandleInteraction(ctx context.Context, c *gin.Context, body string) error {
+
+	ctx, span := tracing.Start(ctx, "skelly.HandleInteraction")
+	defer span.End()
 
 	// parse the main interaction callback
 	callback, err := parseInteraction(body)
 	if err != nil {
 		err = errors.Wrap(err, "could not parse interaction")
-		return err
+		return tracing.Error(span, err)
 	}
 
-	// execute interaction
-	switch callback.Type {
+	span.SetAttributes(
+		attribute.String("skelly.interaction", string(callback.Type)),
+		attribute.String("skelly.user", callback.User.ID))
 
-	case slack.InteractionTypeViewSubmission:
+	// attach the interaction to every log line for this request
+	ctx = logging.WithFields(ctx, logrus.Fields{
+		"team":        callback.Team.ID,
+		"channel":     callback.Channel.ID,
+		"user":        callback.User.ID,
+		"trigger_id":  callback.TriggerID,
+		"interaction": callback.Type,
+	})
 
-		// execute async to allow http connection to close
-		go func() {
+	// respond to view submissions synchronously so
+	// slack can show errors or update the modal
+	if callback.Type == slack.InteractionTypeViewSubmission {
 
-			// handle the view submission
-			err := handleViewSubmission(&callback.View, callback.User.ID, callback.ResponseURL)
-			if err != nil {
-				err = errors.Wrap(err, "could not handle submission")
-				logrus.Error(err)
-				return
-			}
-		}()
+		resp, err := handleViewSubmission(ctx, &callback.View, callback.Team.ID, callback.User.ID)
+		if err != nil {
+			err = errors.Wrap(err, "could not handle submission")
+			return tracing.Error(span, err)
+		}
 
-		// acknowledge the submission
-		util.RespondOK(c)
+		// close the modal when there is nothing to show
+		if resp == nil {
+			util.RespondOK(c)
+			return nil
+		}
+
+		c.JSON(http.StatusOK, resp)
 
 		return nil
+	}
+
+	// look up the handler for the interaction
+	handler, err := lookupInteraction(callback)
+	if err != nil {
+		return tracing.Error(span, err)
+	}
+
+	// detach from the request so the interaction can continue
+	// after the http connection is closed
+	ctx = tracing.Detach(ctx)
+
+	// execute async to allow http connection to close
+	go func() {
+
+		// handle the interaction
+		err := handler(ctx, callback)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("could not handle %s", callback.Type))
+			logging.FromContext(ctx).Error(err)
+			return
+		}
+	}()
+
+	// acknowledge the interaction
+	util.RespondOK(c)
+
+	return nil
+}
+
+// lookupInteraction takes an interaction callback and returns the registered handler for it
+func lookupInteraction(callback *slack.InteractionCallback) (interactionHandler, error) {
+
+	switch callback.Type {
+
+	case slack.InteractionTypeBlockActions:
+		return handleBlockActions, nil
+
+	case slack.InteractionTypeShortcut:
+
+		handler, ok := shortcuts[callback.CallbackID]
+		if !ok {
+			return nil, fmt.Errorf("unsupported shortcut(%s)", callback.CallbackID)
+		}
+
+		return handler, nil
+
+	case slack.InteractionTypeMessageAction:
+
+		handler, ok := messageActions[callback.CallbackID]
+		if !ok {
+			return nil, fmt.Errorf("unsupported message shortcut(%s)", callback.CallbackID)
+		}
+
+		return handler, nil
+
+	case slack.InteractionTypeViewClosed:
+
+		handler, ok := viewClosed[callback.View.CallbackID]
+		if !ok {
+			return ignoreInteraction, nil
+		}
+
+		return handler, nil
+	}
+
+	return nil, fmt.Errorf("unsupported interaction type(%s)", callback.Type)
+}
+
+// handleBlockActions takes an interaction callback and executes each of its block actions
+func handleBlockActions(ctx context.Context, callback *slack.InteractionCallback) error {
+
+	ctx, span := tracing.Start(ctx, "skelly.handleBlockActions")
+	defer span.End()
 
-	default:
-		err := fmt.Errorf("unsupported interaction type(%s)", callback.Type)
-		return err
+	for _, action := range callback.ActionCallback.BlockActions {
+
+		// look up the handler for the action
+		handler, ok := blockActions[action.ActionID]
+		if !ok {
+			err := fmt.Errorf("unsupported block action(%s)", action.ActionID)
+			return tracing.Error(span, err)
+		}
+
+		// execute the action
+		err := handler(ctx, callback, action)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("could not handle block action(%s)", action.ActionID))
+			return tracing.Error(span, err)
+		}
 	}
+
+	return nil
 }
 
 // parseInteraction takes request body and parses it into an interaction callback
@@ -63,52 +170,33 @@ func parseInteraction(body string) (*slack.InteractionCallback, error) {
 }
 
 // handleViewSubmission takes slack view, extracts callback id, and executes a view submission
-func handleViewSubmission(view *slack.View, user, responseURL string) error {
+// returns the response_action for the modal
+func handleViewSubmission(ctx context.Context, view *slack.View, team, user string) (*slack.ViewSubmissionResponse, error) {
+
+	ctx, span := tracing.Start(ctx, "skelly.handleViewSubmission",
+		attribute.String("skelly.callback_id", view.CallbackID))
+	defer span.End()
 
 	// extract callbackID
 	callbackID := strings.Split(view.CallbackID, ":")
 	if len(callbackID) == 0 {
 		err := fmt.Errorf("invalid callback id(%s)", callbackID)
-		return err
+		return nil, tracing.Error(span, err)
 	}
 
-	// execute submission action
-	switch callbackID[0] {
-	case addSubCommand:
-
-		// handle view submission for /skelly add
-		err := handleAddSubmission(view, user, responseURL)
-		if err != nil {
-			err = errors.Wrap(err, "could not handle add submission")
-			return err
-		}
-
-		return nil
-
-	case updateSubCommand:
-
-		// handle view submission for /skelly update
-		err := handleUpdateSubmission(view, user, responseURL)
-		if err != nil {
-			err = errors.Wrap(err, "could not handle update submission")
-			return err
-		}
-
-		return nil
-
-	case deleteSubCommand:
-
-		// handle view submission for /skelly delete
-		err := handleDeleteSubmission(view, user, responseURL)
-		if err != nil {
-			err = errors.Wrap(err, "could not handle delete submission")
-			return err
-		}
-
-		return nil
-
-	default:
+	// look up the handler for the submission
+	handler, ok := viewSubmissions[callbackID[0]]
+	if !ok {
 		err := fmt.Errorf("unsupported submission action(%s)", callbackID[0])
-		return err
+		return nil, tracing.Error(span, err)
 	}
+
+	// handle the view submission
+	resp, err := handler(ctx, view, team, user)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("could not handle %s submission", callbackID[0]))
+		return nil, tracing.Error(span, err)
+	}
+
+	return resp, nil
 }
@@ -1,26 +1,30 @@
 package skelly
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
 	"github.com/davidvader/skelly/types"
 	"github.com/davidvader/skelly/util"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 )
 
 // listReactions takes slash command configuration and responds
 // to the triggering user with a list of the reactions for that channel
-func listReactions(s *slack.SlashCommand, command string, args []string) error {
+func listReactions(ctx context.Context, s *slack.SlashCommand, command string, args []string) error {
 
 	// parse and validate input
-	err := parseListSubCommandArgs(args)
+	all, err := parseListSubCommandArgs(args)
 	if err != nil {
 
 		// invalid command args, send help
-		err := sendHelp(command, s.ResponseURL)
+		err := sendHelp(ctx, command, s.ResponseURL)
 		if err != nil {
 			err = errors.Wrap(err, "could not send help")
 		}
@@ -28,11 +32,24 @@ func listReactions(s *slack.SlashCommand, command string, args []string) error {
 		return err
 	}
 
+	// list reactions for every channel
+	if all {
+		return listAllReactions(ctx, s)
+	}
+
+	team := s.TeamID
 	channel := s.ChannelID
 	user := s.UserID
 
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
 	// attempt to retrieve an existing reaction
-	reactions, err := db.GetChannelReactions(channel)
+	reactions, err := db.GetChannelReactions(ctx, team, channel)
 	if err != nil {
 		err = errors.Wrap(err, "could not check for reaction")
 		return err
@@ -41,25 +58,96 @@ func listReactions(s *slack.SlashCommand, command string, args []string) error {
 	// if reaction exists
 	if len(*reactions) == 0 {
 
-		logrus.Infof("no reactions exist for channel(%s)", channel)
+		logging.FromContext(ctx).Infof("no reactions exist for channel(%s)", channel)
+
+		// notify user
+		return notifyUser(ctx, bToken, "Sorry, no reactions exist for this channel.", channel, user)
+	}
+
+	logging.FromContext(ctx).Infof("listing (%v) reactions for channel(%s)", len(*reactions), channel)
+
+	// build slack response
+	response := listResponse("Here are all of the reactions for this channel.", *reactions)
+
+	// send response
+	err = util.Respond(ctx, s.ResponseURL, response)
+	if err != nil {
+		err = errors.Wrap(err, "could not respond with reaction list")
+		return err
+	}
+	return nil
+}
+
+// listAllReactions takes slash command configuration and responds to the
+// triggering user with a list of the reactions for every channel in the workspace
+// meant for managing reactions from anywhere, including a direct message with skelly
+func listAllReactions(ctx context.Context, s *slack.SlashCommand) error {
+
+	team := s.TeamID
+	channel := s.ChannelID
+	user := s.UserID
+
+	// check that the user may see reactions for every channel
+	allowed, err := checkPermission(ctx, team, channel, channel, user, listSubCommand)
+	if err != nil {
+		err = errors.Wrap(err, "could not check permission")
+		return err
+	}
+
+	if !allowed {
+		return nil
+	}
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	// summarize the channels with reactions
+	stats, err := getStats(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get stats")
+		return err
+	}
+
+	if stats.TotalChannels == 0 {
+
+		logging.FromContext(ctx).Infof("no reactions exist for team(%s)", team)
 
 		// notify user
-		err = util.SendError("Sorry, no reactions exist for this channel.", channel, user)
+		return notifyUser(ctx, bToken, "Sorry, no reactions exist in this workspace.", channel, user)
+	}
+
+	// list channels in a stable order
+	channels := []string{}
+	for c := range stats.Channels {
+		channels = append(channels, c)
+	}
+	sort.Strings(channels)
+
+	reactions := []types.Reaction{}
+
+	for _, c := range channels {
+
+		// retrieve the reactions for the channel
+		r, err := db.GetChannelReactions(ctx, team, c)
 		if err != nil {
-			err = errors.Wrap(err, "could not send error")
+			err = errors.Wrap(err, fmt.Sprintf("could not get reactions for channel(%s)", c))
 			return err
 		}
 
-		return nil
+		reactions = append(reactions, *r...)
 	}
 
-	logrus.Infof("listing (%v) reactions for channel(%s)", len(*reactions), channel)
+	logging.FromContext(ctx).Infof("listing (%v) reactions for (%v) channels", len(reactions), stats.TotalChannels)
 
 	// build slack response
-	response := listResponse(*reactions)
+	response := listResponse(fmt.Sprintf("Here are all of the reactions in this workspace, across %d channels.", stats.TotalChannels), reactions)
 
 	// send response
-	err = util.Respond(s.ResponseURL, response)
+	err = util.Respond(ctx, s.ResponseURL, response)
 	if err != nil {
 		err = errors.Wrap(err, "could not respond with reaction list")
 		return err
@@ -67,18 +155,41 @@ func listReactions(s *slack.SlashCommand, command string, args []string) error {
 	return nil
 }
 
+// getStats takes a team and summarizes the channels that have reactions
+func getStats(ctx context.Context, team string) (*types.SkellyStats, error) {
+
+	// retrieve the number of reactions per channel
+	channels, err := db.GetChannels(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get channels from db")
+		return nil, err
+	}
+
+	stats := &types.SkellyStats{
+		Channels: map[string]*types.ChannelStats{},
+	}
+
+	for c, n := range *channels {
+		stats.Channels[c] = &types.ChannelStats{TotalRules: n}
+	}
+
+	stats.TotalChannels = len(stats.Channels)
+
+	return stats, nil
+}
+
 // listResponse takes list of reactions and builds a slack message for listing them
-func listResponse(reactions []types.Reaction) slack.Message {
+func listResponse(header string, reactions []types.Reaction) slack.Message {
 
 	// header
 	t := slack.NewTextBlockObject("mrkdwn",
-		"Here are all of the reactions for this channel.",
+		header,
 		false, false)
 
-	header := slack.NewSectionBlock(t, nil, nil)
+	headerSection := slack.NewSectionBlock(t, nil, nil)
 
 	// init blocks for building the response
-	blocks := []slack.Block{header}
+	blocks := []slack.Block{headerSection}
 
 	// adhere to the blocks limit
 	if len(reactions) > 49 {
@@ -88,10 +199,15 @@ func listResponse(reactions []types.Reaction) slack.Message {
 	// build blocks
 	for _, r := range reactions {
 
+		status := "active"
+		if r.Paused {
+			status = "paused"
+		}
+
 		// build a view for the reaction
 		t := slack.NewTextBlockObject("mrkdwn",
-			fmt.Sprintf("\n*Response*: %s",
-				r.Response),
+			fmt.Sprintf("\n*Channel*: <#%s>\n*ID*: `%s`\n*Response*: %s\n*Trigger*: %s\n*Users*: %s\n*Cooldown*: %s\n*Schedule*: %s\n*Status*: %s",
+				r.Channel, r.ID.Hex(), describeResponses(&r), describeTrigger(&r), describeUsers(r.Users), describeCooldown(r.Cooldown), describeSchedule(r.Schedule), status),
 			false, false)
 
 		block := slack.NewSectionBlock(t, nil, nil)
@@ -105,3 +221,49 @@ func listResponse(reactions []types.Reaction) slack.Message {
 
 	return msg
 }
+
+// describeReaction takes a reaction and describes who it responds to, how often and when
+// ex: all users that type in <#C123>, once a day (mon,tue,wed,thu,fri 09:00-17:00)
+func describeReaction(r *types.Reaction) string {
+
+	description := fmt.Sprintf("%s that %s <#%s>, %s", describeUsers(r.Users), describeAction(r), r.Channel, describeCooldown(r.Cooldown))
+
+	if r.DirectMessage {
+		description += ", in a direct message"
+	}
+
+	// the schedule of working hours reactions is when they do not respond
+	if r.Schedule != nil && r.Kind != kindHours {
+		description += fmt.Sprintf(" (%s)", describeSchedule(r.Schedule))
+	}
+
+	return description
+}
+
+// describeUsers takes the users a reaction is limited to and describes them as mentions
+func describeUsers(users []string) string {
+
+	if len(users) == 0 {
+		return "all users"
+	}
+
+	mentions := []string{}
+	for _, u := range users {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", u))
+	}
+
+	return strings.Join(mentions, ", ")
+}
+
+// describeCooldown takes a reaction cooldown and describes how often it responds
+func describeCooldown(d time.Duration) string {
+
+	switch d {
+	case 0:
+		return "once per thread"
+	case defaultCooldown:
+		return "once a day"
+	}
+
+	return fmt.Sprintf("at most once every %s", formatCooldown(d))
+}
@@ -0,0 +1,303 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// localeCacheTTL is how long a user's locale is reused
+	// to avoid calling users.info on every message
+	localeCacheTTL = time.Hour
+	// localeFailureTTL is how long a failure to look up a user's locale is reused
+	// so a failing users.info is not called again on every message
+	localeFailureTTL = time.Minute
+	// maxLocales is how many user locales are cached, the oldest is evicted beyond it
+	maxLocales = 10000
+)
+
+// localeCode matches a locale such as es, es-ES or pt_BR
+var localeCode = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[-_]([a-zA-Z]{2}))?$`)
+
+// localeHeader matches the line starting a localized response in the modal, ex: [es-ES]
+var localeHeader = regexp.MustCompile(`^\[([^\]]+)\]$`)
+
+// localeCache caches user locales by team and user
+// keys are the cached keys, oldest first
+var localeCache = struct {
+	sync.Mutex
+	entries map[string]localeEntry
+	keys    []string
+}{entries: map[string]localeEntry{}}
+
+// localeEntry is a cached user locale, or the failure to look it up
+type localeEntry struct {
+	locale  string
+	err     error
+	fetched time.Time
+}
+
+// fresh returns true if the entry may still be reused
+func (e localeEntry) fresh() bool {
+
+	if e.err != nil {
+		return time.Since(e.fetched) < localeFailureTTL
+	}
+
+	return time.Since(e.fetched) < localeCacheTTL
+}
+
+// userLocale takes a user and returns their slack locale, ex: en-US
+// results are cached for localeCacheTTL and failures for localeFailureTTL
+func userLocale(ctx context.Context, api slackapi.Client, team, user string) (string, error) {
+
+	key := team + "/" + user
+
+	localeCache.Lock()
+	entry, ok := localeCache.entries[key]
+	localeCache.Unlock()
+
+	// reuse the previous locale if it is still fresh
+	if ok && entry.fresh() {
+		return entry.locale, entry.err
+	}
+
+	entry = localeEntry{fetched: time.Now()}
+
+	info, err := api.GetUserInfo(ctx, user)
+	if err != nil {
+		entry.err = errors.Wrap(err, "could not get user info")
+	} else {
+		entry.locale = info.Locale
+	}
+
+	cacheLocale(key, entry)
+
+	return entry.locale, entry.err
+}
+
+// cacheLocale takes a user's key and caches their locale, evicting the oldest beyond maxLocales
+func cacheLocale(key string, entry localeEntry) {
+
+	localeCache.Lock()
+	defer localeCache.Unlock()
+
+	if _, ok := localeCache.entries[key]; !ok {
+		localeCache.keys = append(localeCache.keys, key)
+	}
+
+	localeCache.entries[key] = entry
+
+	// evict the oldest locale
+	if len(localeCache.keys) > maxLocales {
+		delete(localeCache.entries, localeCache.keys[0])
+		localeCache.keys = localeCache.keys[1:]
+	}
+}
+
+// normalizeLocale takes a locale and returns it as language-REGION, ex: pt_br is pt-BR
+func normalizeLocale(locale string) (string, error) {
+
+	m := localeCode.FindStringSubmatch(strings.TrimSpace(locale))
+	if m == nil {
+		return "", fmt.Errorf("invalid locale %s, ex: es or es-ES", locale)
+	}
+
+	if len(m[2]) == 0 {
+		return strings.ToLower(m[1]), nil
+	}
+
+	return strings.ToLower(m[1]) + "-" + strings.ToUpper(m[2]), nil
+}
+
+// matchLocale takes localized responses and a user's locale and returns the best match
+// an exact match wins, followed by the language alone, then any region of the language
+func matchLocale(locales map[string]string, locale string) (string, bool) {
+
+	locale, err := normalizeLocale(locale)
+	if err != nil {
+		return "", false
+	}
+
+	// exact match, ex: es-ES
+	if r, ok := locales[locale]; ok {
+		return r, true
+	}
+
+	// the language alone, ex: es
+	language := strings.Split(locale, "-")[0]
+
+	if r, ok := locales[language]; ok {
+		return r, true
+	}
+
+	// another region of the language, ex: es-MX, chosen in a stable order
+	codes := sortedLocales(locales)
+
+	for _, code := range codes {
+		if strings.HasPrefix(code, language+"-") {
+			return locales[code], true
+		}
+	}
+
+	return "", false
+}
+
+// localizedResponse takes a reaction and picks the response for the user
+// uses the response for the user's locale when the reaction has one, falling back to its default responses
+func localizedResponse(ctx context.Context, api slackapi.Client, team string, r *types.Reaction, user string) (string, error) {
+
+	if len(r.Locales) > 0 {
+
+		locale, err := userLocale(ctx, api, team, user)
+		if err != nil {
+			// the default response is still useful
+			logging.FromContext(ctx).Infof("could not get locale for user(%s): %v", user, err)
+		}
+
+		if response, ok := matchLocale(r.Locales, locale); ok {
+			logging.FromContext(ctx).Debugf("using locale(%s) response for user(%s)", locale, user)
+			return response, nil
+		}
+	}
+
+	return selectResponse(ctx, r, user)
+}
+
+// applyLocales takes a reaction and localized responses such as es=¡Hola! and adds them to it
+// returns an error meant for showing to the user if a localized response cannot be used
+func applyLocales(r *types.Reaction, values []string) error {
+
+	for _, v := range values {
+
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid locale %s, ex: es=¡Hola!", v)
+		}
+
+		code, err := normalizeLocale(parts[0])
+		if err != nil {
+			return err
+		}
+
+		err = validateResponse(parts[1])
+		if err != nil {
+			return fmt.Errorf("the %s response cannot be used. %s", code, err)
+		}
+
+		if r.Locales == nil {
+			r.Locales = map[string]string{}
+		}
+
+		r.Locales[code] = parts[1]
+	}
+
+	return nil
+}
+
+// parseLocales takes the localized responses entered in the modal and parses them
+// each response follows a line with its locale in brackets, ex:
+// [es]
+// ¡Bienvenido!
+func parseLocales(text string) (map[string]string, error) {
+
+	locales := map[string]string{}
+
+	code := ""
+	lines := []string{}
+
+	// store the response collected for the current locale
+	flush := func() error {
+
+		if len(code) == 0 {
+			return nil
+		}
+
+		response := strings.TrimSpace(strings.Join(lines, "\n"))
+
+		err := validateResponse(response)
+		if err != nil {
+			return fmt.Errorf("The %s response cannot be used. %s", code, err)
+		}
+
+		locales[code] = response
+
+		return nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+
+		m := localeHeader.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+
+			if len(code) == 0 && len(strings.TrimSpace(line)) > 0 {
+				return nil, errors.New("Start each response with its locale in brackets, ex: [es]")
+			}
+
+			lines = append(lines, line)
+			continue
+		}
+
+		err := flush()
+		if err != nil {
+			return nil, err
+		}
+
+		code, err = normalizeLocale(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a locale, ex: [es] or [es-ES].", m[1])
+		}
+
+		if _, ok := locales[code]; ok {
+			return nil, fmt.Errorf("The %s locale is entered more than once.", code)
+		}
+
+		lines = []string{}
+	}
+
+	err := flush()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(locales) == 0 {
+		return nil, nil
+	}
+
+	return locales, nil
+}
+
+// formatLocales takes localized responses and formats them for editing in the modal
+func formatLocales(locales map[string]string) string {
+
+	entries := []string{}
+
+	for _, code := range sortedLocales(locales) {
+		entries = append(entries, fmt.Sprintf("[%s]\n%s", code, locales[code]))
+	}
+
+	return strings.Join(entries, "\n")
+}
+
+// sortedLocales takes localized responses and returns their locales in order
+func sortedLocales(locales map[string]string) []string {
+
+	codes := []string{}
+	for code := range locales {
+		codes = append(codes, code)
+	}
+
+	sort.Strings(codes)
+
+	return codes
+}
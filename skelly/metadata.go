@@ -0,0 +1,136 @@
+package skelly
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// metadataVersion is the current version of modal private metadata
+	// bump it when viewMetadata changes in a way older modals cannot be read
+	metadataVersion = 2
+
+	// metadataMaxAge is how long after a modal is opened it may be submitted
+	metadataMaxAge = 24 * time.Hour
+	// metadataSkew is how far in the future a modal may appear to be opened, for clock differences between replicas
+	metadataSkew = time.Minute
+
+	// metadataKeyLabel derives the metadata signing key from the signing secret,
+	// so metadata signatures are never valid as slack request signatures or the other way around
+	metadataKeyLabel = "skelly view metadata v1"
+)
+
+// viewMetadata is the private metadata stored in skelly's modals
+// it is signed when the modal is opened so submissions cannot be forged
+type viewMetadata struct {
+	// Version is the metadata version the modal was opened with
+	Version int `json:"v"`
+	// SubCommand is the subcommand that opened the modal, ex: add
+	SubCommand string `json:"subcommand"`
+	// Team is the team the modal was opened in
+	Team string `json:"team"`
+	// Channel is the channel the modal was opened for, selected by default
+	Channel string `json:"channel"`
+	// Reaction is the id of the reaction shown in the modal, if any
+	Reaction string `json:"reaction,omitempty"`
+	// User is the user that opened the modal
+	User string `json:"user"`
+	// Issued is when the modal was opened, as a unix timestamp
+	Issued int64 `json:"iat"`
+}
+
+// encodeViewMetadata takes metadata and returns it json encoded and signed
+// ex: base64(json).hex(hmac)
+func encodeViewMetadata(m *viewMetadata) (string, error) {
+
+	m.Version = metadataVersion
+	m.Issued = time.Now().Unix()
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		err = errors.Wrap(err, "could not marshal metadata")
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(b)
+
+	return payload + "." + signMetadata(payload), nil
+}
+
+// parseViewMetadata takes view and extracts its signed metadata
+// verifies the metadata was opened by skelly for the submitting team and user
+func parseViewMetadata(view *slack.View, team, user string) (*viewMetadata, error) {
+
+	parts := strings.SplitN(view.PrivateMetadata, ".", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid view submission metadata(%s)", view.PrivateMetadata)
+	}
+
+	// compare signatures in constant time
+	if !hmac.Equal([]byte(parts[1]), []byte(signMetadata(parts[0]))) {
+		return nil, errors.New("metadata signature does not match")
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		err = errors.Wrap(err, "could not decode metadata")
+		return nil, err
+	}
+
+	m := new(viewMetadata)
+
+	err = json.Unmarshal(b, m)
+	if err != nil {
+		err = errors.Wrap(err, "could not unmarshal metadata")
+		return nil, err
+	}
+
+	if m.Version != metadataVersion {
+		return nil, fmt.Errorf("unsupported metadata version(%d)", m.Version)
+	}
+
+	// the modal must have been opened recently
+	issued := time.Unix(m.Issued, 0)
+	if time.Since(issued) > metadataMaxAge || time.Until(issued) > metadataSkew {
+		return nil, fmt.Errorf("metadata issued at %s has expired", issued.UTC().Format(time.RFC3339))
+	}
+
+	// the metadata must belong to this modal and submission
+	if m.SubCommand != strings.Split(view.CallbackID, ":")[0] {
+		return nil, fmt.Errorf("metadata subcommand(%s) does not match callback id(%s)", m.SubCommand, view.CallbackID)
+	}
+
+	if m.Team != team || m.User != user {
+		return nil, fmt.Errorf("metadata team(%s) user(%s) does not match submission team(%s) user(%s)", m.Team, m.User, team, user)
+	}
+
+	return m, nil
+}
+
+// signMetadata returns the hex encoded hmac sha256 of value using the metadata key
+func signMetadata(value string) string {
+
+	mac := hmac.New(sha256.New, metadataKey())
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// metadataKey returns the key for signing metadata, derived from the signing secret with a fixed label
+func metadataKey() []byte {
+
+	mac := hmac.New(sha256.New, []byte(os.Getenv("SKELLY_SIGNING_SECRET")))
+	mac.Write([]byte(metadataKeyLabel))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,110 @@
+package skelly
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func TestParseViewMetadata(t *testing.T) {
+
+	t.Setenv("SKELLY_SIGNING_SECRET", "secret")
+
+	valid := &viewMetadata{SubCommand: addSubCommand, Team: "T123", Channel: "C123", User: "U123"}
+
+	encoded, err := encodeViewMetadata(valid)
+	if err != nil {
+		t.Fatalf("encodeViewMetadata returned error: %v", err)
+	}
+
+	now := time.Now().Unix()
+
+	// signed metadata at an older version
+	outdated := signedMetadata(t, fmt.Sprintf(`{"v":1,"subcommand":"add","team":"T123","channel":"C123","user":"U123","iat":%d}`, now))
+
+	// signed metadata opened too long ago, or in the future
+	expired := signedMetadata(t, fmt.Sprintf(`{"v":2,"subcommand":"add","team":"T123","channel":"C123","user":"U123","iat":%d}`, now-int64(metadataMaxAge/time.Second)-60))
+	future := signedMetadata(t, fmt.Sprintf(`{"v":2,"subcommand":"add","team":"T123","channel":"C123","user":"U123","iat":%d}`, now+3600))
+	unissued := signedMetadata(t, `{"v":2,"subcommand":"add","team":"T123","channel":"C123","user":"U123"}`)
+
+	// metadata signed with the signing secret itself rather than the derived key
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"v":2,"subcommand":"add","team":"T123","channel":"C123","user":"U123","iat":%d}`, now)))
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(payload))
+	underived := payload + "." + hex.EncodeToString(mac.Sum(nil))
+
+	// a signature made with another secret
+	t.Setenv("SKELLY_SIGNING_SECRET", "other")
+	forged, err := encodeViewMetadata(&viewMetadata{SubCommand: addSubCommand, Team: "T123", Channel: "C123", User: "U123"})
+	if err != nil {
+		t.Fatalf("encodeViewMetadata returned error: %v", err)
+	}
+	t.Setenv("SKELLY_SIGNING_SECRET", "secret")
+
+	tests := []struct {
+		name     string
+		metadata string
+		callback string
+		team     string
+		user     string
+		wantErr  bool
+	}{
+		{name: "valid", metadata: encoded, callback: addSubCommand, team: "T123", user: "U123"},
+		{name: "valid with callback suffix", metadata: encoded, callback: addSubCommand + ":C123", team: "T123", user: "U123"},
+		{name: "empty", metadata: "", callback: addSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "unsigned", metadata: "C123", callback: addSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "bad signature", metadata: encoded + "0", callback: addSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "other secret", metadata: forged, callback: addSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "tampered payload", metadata: "x" + encoded, callback: addSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "outdated version", metadata: outdated, callback: addSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "expired", metadata: expired, callback: addSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "issued in the future", metadata: future, callback: addSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "not issued", metadata: unissued, callback: addSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "signed with the secret", metadata: underived, callback: addSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "other subcommand", metadata: encoded, callback: deleteSubCommand, team: "T123", user: "U123", wantErr: true},
+		{name: "other team", metadata: encoded, callback: addSubCommand, team: "T456", user: "U123", wantErr: true},
+		{name: "other user", metadata: encoded, callback: addSubCommand, team: "T123", user: "U456", wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		view := &slack.View{PrivateMetadata: test.metadata, CallbackID: test.callback}
+
+		got, err := parseViewMetadata(view, test.team, test.user)
+
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: parseViewMetadata returned no error", test.name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: parseViewMetadata returned error: %v", test.name, err)
+			continue
+		}
+
+		if *got != *valid {
+			t.Errorf("%s: parseViewMetadata = %+v, want %+v", test.name, got, valid)
+		}
+	}
+}
+
+// signedMetadata takes raw metadata json and signs it with the current signing secret
+func signedMetadata(t *testing.T, raw string) string {
+
+	if !json.Valid([]byte(raw)) {
+		t.Fatalf("invalid metadata json %s", raw)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(raw))
+
+	return payload + "." + signMetadata(payload)
+}
@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/davidvader/skelly/types"
 	"github.com/pkg/errors"
 	"github.com/slack-go/slack"
 )
 
 // modal builds the default view modal for managing a reaction
-func modal(callback, header, metadata, response string) slack.ModalViewRequest {
+// channel is the initially selected channel, left empty when opened outside of a channel
+// locales are the reaction's localized responses formatted for editing
+func modal(callback, header, metadata, channel, response, locales string) slack.ModalViewRequest {
 
 	// header section
 	headerText := slack.NewTextBlockObject("mrkdwn", header+" A reaction will trigger a response once a day for all users that type in a channel.", false, false)
@@ -25,11 +28,26 @@ func modal(callback, header, metadata, response string) slack.ModalViewRequest {
 
 	responseInput := slack.NewInputBlock("Response", responseText, responseElement)
 
+	// localized responses input
+	localesText := slack.NewTextBlockObject("plain_text", "Localized responses", false, false)
+	localesPlaceholder := slack.NewTextBlockObject("plain_text", "[es]\n¡Bienvenido!\n[fr-FR]\nBienvenue !", false, false)
+	localesHint := slack.NewTextBlockObject("plain_text", "Users whose Slack language matches a locale get its response instead. Start each response with its locale in brackets.", false, false)
+
+	localesElement := slack.NewPlainTextInputBlockElement(localesPlaceholder, "locales")
+	localesElement.Multiline = true
+	localesElement.InitialValue = locales
+
+	localesInput := slack.NewInputBlock("Locales", localesText, localesElement)
+	localesInput.Hint = localesHint
+	localesInput.Optional = true
+
 	// build message from blocks
 	blocks := slack.Blocks{
 		BlockSet: []slack.Block{
 			headerSection,
+			channelInput(channel),
 			responseInput,
+			localesInput,
 		},
 	}
 
@@ -48,17 +66,31 @@ func modal(callback, header, metadata, response string) slack.ModalViewRequest {
 }
 
 // deleteModal builds the view modal for deleting a reaction
-func deleteModal(callback, metadata string) slack.ModalViewRequest {
+// lists the reactions that will be deleted for the channel, if known
+func deleteModal(callback, metadata, channel string, reactions []types.Reaction) slack.ModalViewRequest {
 
 	// header section
-	headerText := slack.NewTextBlockObject("mrkdwn", "Delete a reaction.", false, false)
+	headerText := slack.NewTextBlockObject("mrkdwn", "Delete a reaction. Deleted reactions can be restored with *Undo*.", false, false)
 	headerSection := slack.NewSectionBlock(headerText, nil, nil)
 
+	blockSet := []slack.Block{
+		headerSection,
+		channelInput(channel),
+	}
+
+	// show what is about to be deleted
+	for _, r := range reactions {
+
+		t := slack.NewTextBlockObject("mrkdwn",
+			fmt.Sprintf("*Response*: %s\n*Users*: %s\n*Cooldown*: %s", describeResponses(&r), describeUsers(r.Users), describeCooldown(r.Cooldown)),
+			false, false)
+
+		blockSet = append(blockSet, slack.NewSectionBlock(t, nil, nil))
+	}
+
 	// build message from blocks
 	blocks := slack.Blocks{
-		BlockSet: []slack.Block{
-			headerSection,
-		},
+		BlockSet: blockSet,
 	}
 
 	// configure modal view request
@@ -75,6 +107,59 @@ func deleteModal(callback, metadata string) slack.ModalViewRequest {
 	return request
 }
 
+// confirmationModal builds the view shown in place of a modal once its submission succeeds
+func confirmationModal(callback, text string) *slack.ModalViewRequest {
+
+	section := slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil)
+
+	return &slack.ModalViewRequest{
+		Type:       slack.ViewType("modal"),
+		Title:      slack.NewTextBlockObject("plain_text", "Skelly", false, false),
+		Close:      slack.NewTextBlockObject("plain_text", "Done", false, false),
+		Blocks:     slack.Blocks{BlockSet: []slack.Block{section}},
+		CallbackID: callback,
+	}
+}
+
+// channelInput builds the channel picker for a modal
+func channelInput(channel string) *slack.InputBlock {
+
+	channelText := slack.NewTextBlockObject("plain_text", "Channel", false, false)
+	channelPlaceholder := slack.NewTextBlockObject("plain_text", "Select a channel", false, false)
+
+	channelElement := slack.NewOptionsSelectBlockElement(slack.OptTypeConversations, channelPlaceholder, "channel")
+
+	// default to the channel the modal was opened from
+	if len(channel) > 0 && !isDirectMessage(channel) {
+		channelElement.InitialConversation = channel
+	}
+
+	return slack.NewInputBlock("Channel", channelText, channelElement)
+}
+
+// parseViewChannel takes view and extracts the selected channel
+// falls back to the provided channel when the view has no channel picker
+func parseViewChannel(view *slack.View, fallback string) (string, error) {
+
+	channel := fallback
+
+	// extract channel view state value, if present
+	if v, ok := view.State.Values["Channel"]["channel"]; ok && len(v.SelectedConversation) > 0 {
+		channel = v.SelectedConversation
+	}
+
+	if len(channel) == 0 {
+		return "", errors.New("no Channel.channel value")
+	}
+
+	return channel, nil
+}
+
+// isDirectMessage takes a channel id and returns true if it is a direct message
+func isDirectMessage(channel string) bool {
+	return strings.HasPrefix(channel, "D")
+}
+
 // parseViewResponse takes view and extracts response
 func parseViewResponse(view *slack.View) (string, error) {
 
@@ -91,32 +176,18 @@ func parseViewResponse(view *slack.View) (string, error) {
 		return "", err
 	}
 
-	// extract response view state value
-	response := view.State.Values["Response"]["response"].Value
-	if len(response) == 0 {
-		err := errors.New("no Response.response value")
-		return "", err
-	}
-
-	return response, nil
+	// extract response view state value, validated by the caller
+	return view.State.Values["Response"]["response"].Value, nil
 }
 
-// parseViewMetadata takes view and extracts args from metadata
-func parseViewMetadata(view *slack.View) (string, error) {
+// parseViewLocales takes view and extracts the localized responses
+// returns nil when none were entered
+func parseViewLocales(view *slack.View) (map[string]string, error) {
 
-	// split metadata by delimiter :
-	metadata := strings.Split(view.PrivateMetadata, " ")
-	if len(metadata) < 4 {
-		err := fmt.Errorf("invalid view submission metadata(%v)", metadata)
-		return "", err
-	}
-
-	// extract args
-
-	channel := metadata[1]
-	if len(channel) == 0 {
-		return "", errors.New("bad metadata no channel")
+	v, ok := view.State.Values["Locales"]["locales"]
+	if !ok {
+		return nil, nil
 	}
 
-	return channel, nil
+	return parseLocales(v.Value)
 }
@@ -0,0 +1,482 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/tracing"
+	"github.com/davidvader/skelly/types"
+	"github.com/davidvader/skelly/util"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// oooSubCommand manages the user's out of office settings, ex: /skelly ooo on "Back Monday"
+	oooSubCommand = "ooo"
+
+	// defaultOOOMessage is the reply used when a user turns out of office on without a message
+	defaultOOOMessage = "I'm out of the office and will reply when I'm back."
+	// oooCooldown is how long to wait before replying for the same user in a channel again
+	oooCooldown = time.Hour
+	// oooResponsePrefix keeps out of office replies apart from reaction responses,
+	// so they do not count towards the cooldown of the away user's own reactions
+	oooResponsePrefix = "ooo:"
+
+	// kindHours answers messages that arrive in a channel outside of its working hours,
+	// the reaction's schedule is the channel's working hours
+	kindHours = "hours"
+	// defaultHoursMessage is the reply used when a channel's working hours are set without a message
+	defaultHoursMessage = "Thanks for your message! We're outside of our working hours and will reply when we're back."
+)
+
+// mentionedUser matches a user mention anywhere in message text, ex: <@U123>
+var mentionedUser = regexp.MustCompile(`<@([UW][A-Z0-9]+)(?:\|[^>]*)?>`)
+
+// mentionedUsers takes message text and returns the users it mentions, excluding author
+func mentionedUsers(text, author string) []string {
+
+	users := []string{}
+
+	for _, m := range mentionedUser.FindAllStringSubmatch(text, -1) {
+		if m[1] != author && !contains(users, m[1]) {
+			users = append(users, m[1])
+		}
+	}
+
+	return users
+}
+
+// outOfOffice takes a user's out of office settings and returns true if they are away at now
+// users are away while out of office is on, or outside of their working hours
+func outOfOffice(o *types.OutOfOffice, now time.Time) (bool, error) {
+
+	if o.Away {
+		return true, nil
+	}
+
+	if o.Hours == nil {
+		return false, nil
+	}
+
+	working, err := scheduleActive(o.Hours, now)
+	if err != nil {
+		return false, err
+	}
+
+	return !working, nil
+}
+
+// reactionActive takes a reaction and returns true if it may respond at now
+// reactions respond within their schedule, except working hours reactions, which respond outside of it
+func reactionActive(r *types.Reaction, now time.Time) (bool, error) {
+
+	active, err := scheduleActive(r.Schedule, now)
+	if err != nil {
+		return false, err
+	}
+
+	if r.Kind == kindHours {
+		return r.Schedule != nil && !active, nil
+	}
+
+	return active, nil
+}
+
+// respondOutOfOffice takes a message and replies in its thread for every mentioned user that is away
+// runs before the channel's reactions so away users are answered for first
+func respondOutOfOffice(ctx context.Context, bToken, team, channel, user, ts, text string) error {
+
+	// only messages mentioning someone else can be answered for
+	users := mentionedUsers(text, user)
+	if len(users) == 0 {
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "skelly.respondOutOfOffice",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel),
+		attribute.String("skelly.ts", ts))
+	defer span.End()
+
+	// retrieve the settings for the mentioned users
+	settings, err := db.GetOutOfOffices(ctx, team, users)
+	if err != nil {
+		err = errors.Wrap(err, "could not get out of office")
+		return tracing.Error(span, err)
+	}
+
+	// create an api client
+	api := slackapi.For(bToken)
+
+	for i := range settings {
+
+		o := &settings[i]
+
+		away, err := outOfOffice(o, time.Now())
+		if err != nil {
+			err = errors.Wrap(err, "could not check out of office")
+			return tracing.Error(span, err)
+		}
+
+		if !away {
+			continue
+		}
+
+		key := oooResponsePrefix + o.User
+
+		// do not repeat the reply in a busy channel
+		last, err := db.GetLastResponse(ctx, channel, key, key)
+		if err != nil {
+			err = errors.Wrap(err, "could not get last response")
+			return tracing.Error(span, err)
+		}
+
+		if last != nil && time.Since(time.Unix(last.Created, 0)) < oooCooldown {
+			logging.FromContext(ctx).Debugf("skipping, out of office for user(%s) in channel(%s) is within the cooldown(%s)", o.User, channel, oooCooldown)
+			continue
+		}
+
+		// reply on behalf of the away user, {{.User}} mentions the author
+		response := fmt.Sprintf("<@%s> is out of office: %s", o.User, o.Message)
+
+		mts, err := postResponse(ctx, api, response, user, channel, ts, false)
+		if err != nil {
+			err = errors.Wrap(err, "could not post response")
+			return tracing.Error(span, err)
+		}
+
+		err = db.StoreResponse(ctx, channel, key, ts, key)
+		if err != nil {
+			err = errors.Wrap(err, "response posted, but could not remember the response")
+			return tracing.Error(span, err)
+		}
+
+		logging.FromContext(ctx).Infof("out of office posted for user(%s) in channel(%s) ts(%s) at msg_ts(%s)", o.User, channel, ts, mts)
+	}
+
+	return nil
+}
+
+// handleOutOfOffice takes slash command configuration and arguments and manages the user's out of office settings
+// ex: /skelly ooo on "Back Monday"
+// ex: /skelly ooo off
+// ex: /skelly ooo hours --days mon-fri --hours 09:00-17:00 --tz America/Chicago
+func handleOutOfOffice(ctx context.Context, s *slack.SlashCommand, args []string) error {
+
+	team := s.TeamID
+	user := s.UserID
+
+	// retrieve the existing settings
+	o, err := db.GetOutOfOffice(ctx, team, user)
+	if err != nil {
+		err = errors.Wrap(err, "could not get out of office")
+		return err
+	}
+
+	if o == nil {
+		o = &types.OutOfOffice{
+			Team:    team,
+			User:    user,
+			Message: defaultOOOMessage,
+		}
+	}
+
+	action := ""
+	if len(args) > 0 {
+		action = strings.ToLower(args[0])
+	}
+
+	text := ""
+
+	switch action {
+	// /skelly ooo
+	case "", "status":
+
+		text = describeOutOfOffice(o)
+
+	// /skelly ooo on "message"
+	// /skelly ooo off
+	case "on", "off":
+
+		message := strings.Join(args[1:], " ")
+
+		if len(message) > 0 {
+
+			err = validateResponse(message)
+			if err != nil {
+				text = fmt.Sprintf("Sorry, that message cannot be used. %s", err)
+				break
+			}
+
+			o.Message = message
+		}
+
+		o.Away = action == "on"
+
+		err = saveOutOfOffice(ctx, o)
+		if err != nil {
+			return err
+		}
+
+		text = describeOutOfOffice(o)
+
+	// /skelly ooo channel "message" --days mon-fri --hours 09:00-17:00
+	// /skelly ooo channel off
+	case "channel":
+
+		text, err = channelHours(ctx, s, args[1:])
+		if err != nil {
+			err = errors.Wrap(err, "could not manage channel hours")
+			return err
+		}
+
+		// the user was already told they are not allowed
+		if len(text) == 0 {
+			return nil
+		}
+
+	// /skelly ooo hours --days mon-fri --hours 09:00-17:00
+	case "hours":
+
+		parsed, err := parseReactionArgs(args[1:])
+		if err == nil && (parsed.Cooldown != nil || parsed.Users != nil || !parsed.Variants.Empty() || !parsed.Trigger.Empty() || !parsed.FAQ.Empty()) {
+			err = errors.New("only --days, --hours, --tz, --from, --until and --always can be used")
+		}
+
+		if err == nil && len(parsed.Response) > 0 {
+
+			err = validateResponse(parsed.Response)
+			if err == nil {
+				o.Message = parsed.Response
+			}
+		}
+
+		if err == nil {
+			o.Hours, err = applySchedule(o.Hours, &parsed.Schedule)
+		}
+
+		if err != nil {
+			text = fmt.Sprintf("Sorry, %s.", err)
+			break
+		}
+
+		err = saveOutOfOffice(ctx, o)
+		if err != nil {
+			return err
+		}
+
+		text = describeOutOfOffice(o)
+
+	default:
+
+		text = fmt.Sprintf("Sorry, I don't know `%s`. Try `%s %s on \"Back Monday\"`, `%s %s off`, `%s %s hours --days mon-fri --hours 09:00-17:00` or `%s %s channel --days mon-fri --hours 09:00-17:00`.",
+			args[0], s.Command, oooSubCommand, s.Command, oooSubCommand, s.Command, oooSubCommand, s.Command, oooSubCommand)
+	}
+
+	// respond to the user, out of office may be managed from any conversation
+	err = util.Respond(ctx, s.ResponseURL, slack.Msg{Text: text})
+	if err != nil {
+		err = errors.Wrap(err, "could not respond")
+		return err
+	}
+
+	return nil
+}
+
+// saveOutOfOffice takes out of office settings and saves them in the database
+func saveOutOfOffice(ctx context.Context, o *types.OutOfOffice) error {
+
+	o.Updated = time.Now().Unix()
+
+	err := db.SaveOutOfOffice(ctx, o)
+	if err != nil {
+		err = errors.Wrap(err, "could not save out of office")
+		return err
+	}
+
+	logging.FromContext(ctx).Infof("out of office for user(%s) away(%t)", o.User, o.Away)
+
+	return nil
+}
+
+// describeOutOfOffice takes out of office settings and describes when skelly answers for the user
+func describeOutOfOffice(o *types.OutOfOffice) string {
+
+	status := "Out of office is *off*"
+	if o.Away {
+		status = "Out of office is *on*"
+	}
+
+	if o.Hours != nil {
+		status += fmt.Sprintf(", and you're also away outside of your working hours (%s)", describeSchedule(o.Hours))
+	}
+
+	return fmt.Sprintf("%s. When someone mentions you while you're away, I'll reply:\n>%s", status, o.Message)
+}
+
+// channelHours takes slash command configuration and arguments and manages the working hours of the current channel
+// the working hours are a reaction of the channel, answering messages that arrive outside of them
+// returns the text to respond with, empty when the user was already told they are not allowed
+// ex: /skelly ooo channel "We're closed, back at 9am" --days mon-fri --hours 09:00-17:00 --tz America/Chicago
+// ex: /skelly ooo channel off
+func channelHours(ctx context.Context, s *slack.SlashCommand, args []string) (string, error) {
+
+	team := s.TeamID
+	channel := s.ChannelID
+	user := s.UserID
+
+	// working hours belong to channels, not direct messages
+	if isDirectMessage(channel) {
+		return "Sorry, run this in the channel to manage its working hours.", nil
+	}
+
+	// retrieve the existing working hours
+	_, reaction, err := db.ReactionExists(ctx, team, channel, (&types.Reaction{Kind: kindHours}).Key())
+	if err != nil {
+		err = errors.Wrap(err, "could not check for working hours in db")
+		return "", err
+	}
+
+	action := ""
+	if len(args) > 0 {
+		action = strings.ToLower(args[0])
+	}
+
+	switch action {
+	// /skelly ooo channel
+	case "", "status":
+
+		if reaction == nil {
+			return fmt.Sprintf("<#%s> has no working hours. Set them with `%s %s channel --days mon-fri --hours 09:00-17:00`.", channel, s.Command, oooSubCommand), nil
+		}
+
+		return describeChannelHours(reaction), nil
+
+	// /skelly ooo channel off
+	case "off":
+
+		if reaction == nil {
+			return fmt.Sprintf("<#%s> has no working hours.", channel), nil
+		}
+
+		allowed, err := checkPermission(ctx, team, channel, channel, user, deleteSubCommand)
+		if err != nil {
+			err = errors.Wrap(err, "could not check permission")
+			return "", err
+		}
+
+		if !allowed {
+			return "", nil
+		}
+
+		_, err = trashReactions(ctx, team, channel, reaction.ID.Hex(), user, sourceSlash)
+		if err != nil {
+			err = errors.Wrap(err, "could not trash working hours")
+			return "", err
+		}
+
+		return fmt.Sprintf("Okay, I've removed the working hours for <#%s>.", channel), nil
+	}
+
+	// parse and validate input
+	parsed, err := parseReactionArgs(args)
+	if err == nil && (parsed.Users != nil || !parsed.Variants.Empty() || !parsed.Trigger.Empty() || !parsed.FAQ.Empty() || len(parsed.ID) > 0) {
+		err = errors.New("only a message, --cooldown, --days, --hours, --tz, --from and --until can be used")
+	}
+
+	if err == nil && len(parsed.Response) > 0 {
+		err = validateResponse(parsed.Response)
+	}
+
+	if err != nil {
+		return fmt.Sprintf("Sorry, %s.", err), nil
+	}
+
+	subcommand := updateSubCommand
+	if reaction == nil {
+		subcommand = addSubCommand
+	}
+
+	allowed, err := checkPermission(ctx, team, channel, channel, user, subcommand)
+	if err != nil {
+		err = errors.Wrap(err, "could not check permission")
+		return "", err
+	}
+
+	if !allowed {
+		return "", nil
+	}
+
+	exists := reaction != nil
+
+	var before map[string]interface{}
+	if !exists {
+		reaction = &types.Reaction{
+			Team:      team,
+			Channel:   channel,
+			CreatedBy: user,
+			Kind:      kindHours,
+			Response:  defaultHoursMessage,
+			Cooldown:  oooCooldown,
+		}
+	} else {
+		before = auditSnapshot(reaction)
+	}
+
+	if len(parsed.Response) > 0 {
+		reaction.Response = parsed.Response
+	}
+
+	if parsed.Cooldown != nil {
+		reaction.Cooldown = *parsed.Cooldown
+	}
+
+	reaction.Schedule, err = applySchedule(reaction.Schedule, &parsed.Schedule)
+	if err == nil && reaction.Schedule == nil {
+		err = errors.New("working hours require --days or --hours, ex: --days mon-fri --hours 09:00-17:00")
+	}
+
+	if err != nil {
+		return fmt.Sprintf("Sorry, %s.", err), nil
+	}
+
+	// add or update the working hours in the database
+	change := auditAdd
+	if exists {
+		change = auditUpdate
+		err = db.UpdateReaction(ctx, reaction)
+	} else {
+		err = db.AddReaction(ctx, reaction)
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, "could not save working hours in db")
+		return "", err
+	}
+
+	logging.FromContext(ctx).Infof("working hours for channel(%s) set to (%s)", channel, describeSchedule(reaction.Schedule))
+
+	recordAudit(ctx, team, channel, reaction.ID.Hex(), user, change, sourceSlash, before, auditSnapshot(reaction))
+
+	return describeChannelHours(reaction), nil
+}
+
+// describeChannelHours takes a channel's working hours and describes when skelly answers in the channel
+func describeChannelHours(r *types.Reaction) string {
+
+	status := ""
+	if r.Paused {
+		status = " The working hours are paused."
+	}
+
+	return fmt.Sprintf("Messages in <#%s> outside of its working hours (%s) get this reply, %s per person:%s\n>%s",
+		r.Channel, describeSchedule(r.Schedule), describeCooldown(r.Cooldown), status, r.Response)
+}
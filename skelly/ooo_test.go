@@ -0,0 +1,59 @@
+package skelly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/davidvader/skelly/types"
+)
+
+func TestReactionActive(t *testing.T) {
+
+	workingHours := &types.Schedule{Weekdays: []string{"mon", "tue", "wed", "thu", "fri"}, From: "09:00", To: "17:00"}
+
+	// 2021-01-04 is a monday
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2021, 1, 4, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		reaction *types.Reaction
+		now      time.Time
+		want     bool
+		wantErr  bool
+	}{
+		{name: "no schedule", reaction: &types.Reaction{}, now: monday(3, 0), want: true},
+		{name: "within schedule", reaction: &types.Reaction{Schedule: workingHours}, now: monday(12, 0), want: true},
+		{name: "outside of schedule", reaction: &types.Reaction{Schedule: workingHours}, now: monday(18, 0), want: false},
+
+		{name: "within working hours", reaction: &types.Reaction{Kind: kindHours, Schedule: workingHours}, now: monday(12, 0), want: false},
+		{name: "after working hours", reaction: &types.Reaction{Kind: kindHours, Schedule: workingHours}, now: monday(18, 0), want: true},
+		{name: "before working hours", reaction: &types.Reaction{Kind: kindHours, Schedule: workingHours}, now: monday(8, 59), want: true},
+		{name: "weekend", reaction: &types.Reaction{Kind: kindHours, Schedule: workingHours}, now: monday(12, 0).AddDate(0, 0, -1), want: true},
+		{name: "no working hours", reaction: &types.Reaction{Kind: kindHours}, now: monday(3, 0), want: false},
+
+		{name: "invalid time zone", reaction: &types.Reaction{Kind: kindHours, Schedule: &types.Schedule{TimeZone: "Nowhere/Else"}}, now: monday(3, 0), wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		got, err := reactionActive(test.reaction, test.now)
+
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: reactionActive returned no error", test.name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: reactionActive returned error: %v", test.name, err)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("%s: reactionActive = %t, want %t", test.name, got, test.want)
+		}
+	}
+}
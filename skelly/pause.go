@@ -0,0 +1,137 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+// setPaused takes a reaction and pauses or resumes it in the database
+// actor and source record who made the change, and where
+func setPaused(ctx context.Context, team string, reaction *types.Reaction, actor, source string, paused bool) error {
+
+	before := auditSnapshot(reaction)
+
+	reaction.Paused = paused
+
+	// update reaction in the database
+	err := db.UpdateReaction(ctx, reaction)
+	if err != nil {
+		err = errors.Wrap(err, "could not update reaction in db")
+		return err
+	}
+
+	logging.FromContext(ctx).Infof("reaction(%s) for channel(%s) paused(%t)", reaction.ID.Hex(), reaction.Channel, paused)
+
+	recordAudit(ctx, team, reaction.Channel, reaction.ID.Hex(), actor, pausedAction(paused), source, before, auditSnapshot(reaction))
+
+	return nil
+}
+
+// pauseInline takes slash command configuration and pauses or resumes
+// the reaction in the current channel, picked with --id when the channel has several
+// ex: /skelly pause
+func pauseInline(ctx context.Context, s *slack.SlashCommand, args []string, paused bool) error {
+
+	team := s.TeamID
+	channel := s.ChannelID
+	user := s.UserID
+
+	subcommand := resumeSubCommand
+	if paused {
+		subcommand = pauseSubCommand
+	}
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return err
+	}
+
+	// reactions belong to channels, not direct messages
+	if isDirectMessage(channel) {
+
+		// notify user
+		return notifyUser(ctx, bToken, "Sorry, run this in the channel the reaction is for, or use the Home tab.", channel, user)
+	}
+
+	// parse the reaction to pause, if picked
+	id, err := parseIDArg(args)
+	if err != nil {
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
+	}
+
+	// retrieve the picked reaction from the database
+	reaction, err := findReaction(ctx, team, channel, id)
+
+	// the user needs to pick one of the channel's reactions
+	if several, ok := err.(*severalReactionsError); ok {
+
+		logging.FromContext(ctx).Infof("several reactions exist for channel(%s)", channel)
+
+		// notify user
+		return notifyUser(ctx, bToken, several.Message(s.Command+" "+subcommand), channel, user)
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, "could not find reaction in db")
+		return err
+	}
+
+	if reaction == nil {
+
+		logging.FromContext(ctx).Infof("reaction(%s) does not exist for channel(%s)", id, channel)
+
+		// notify user
+		return notifyUser(ctx, bToken, missingReaction(channel, id), channel, user)
+	}
+
+	// check that the user may change the reaction
+	allowed, err := canChange(ctx, bToken, user, reaction)
+	if err != nil {
+		err = errors.Wrap(err, "could not authorize user")
+		return err
+	}
+
+	if !allowed {
+
+		logging.FromContext(ctx).Infof("user(%s) is not allowed to %s reaction(%s)", user, subcommand, reaction.ID.Hex())
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, only the creator of this reaction or an admin may %s it.", subcommand), channel, user)
+	}
+
+	err = setPaused(ctx, team, reaction, user, sourceSlash, paused)
+	if err != nil {
+		err = errors.Wrap(err, "could not pause reaction")
+		return err
+	}
+
+	// resume the same reaction
+	resume := s.Command + " " + resumeSubCommand
+	if len(id) > 0 {
+		resume += " --id " + id
+	}
+
+	text := fmt.Sprintf("Okay, I've paused the reaction for <#%s>. Use `%s` to turn it back on.", channel, resume)
+	if !paused {
+		text = fmt.Sprintf("Okay, I've resumed the reaction for <#%s>. I will respond to %s.", channel, describeReaction(reaction))
+	}
+
+	// post the confirmation
+	err = postConfirmation(ctx, bToken, text, channel, user)
+	if err != nil {
+		err = errors.Wrap(err, "could not post confirmation")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+)
+
+// policy is the configuration for who may manage reactions
+type policy struct {
+	// admins are user ids allowed to manage every reaction, from SKELLY_ADMINS
+	admins []string
+	// groups are user group ids whose members are allowed to manage every reaction, from SKELLY_ADMIN_GROUPS
+	groups []string
+	// creatorOnly limits updating and deleting a reaction to its creator, from SKELLY_CREATOR_ONLY
+	creatorOnly bool
+}
+
+// getPolicy returns the authorization policy configured in the environment
+func getPolicy() *policy {
+
+	creatorOnly, _ := strconv.ParseBool(os.Getenv("SKELLY_CREATOR_ONLY"))
+
+	return &policy{
+		admins:      splitList(os.Getenv("SKELLY_ADMINS")),
+		groups:      splitList(os.Getenv("SKELLY_ADMIN_GROUPS")),
+		creatorOnly: creatorOnly,
+	}
+}
+
+// restricted returns true if managing reactions is limited to admins
+func (p *policy) restricted() bool {
+	return len(p.admins) > 0 || len(p.groups) > 0
+}
+
+// authorize takes a user and the subcommand they are running and returns true
+// if the user may run it against the reactions in the channel
+// workspace admins and owners are always allowed, followed by skelly admins,
+// members of skelly admin groups and, with creatorOnly, the creator of a reaction
+// in the channel, see canChange for checking the reaction they picked
+// when no admins are configured every user may add, update and delete reactions
+func authorize(ctx context.Context, bToken, team, channel, user, action string) (bool, error) {
+
+	p := getPolicy()
+
+	// everyone may manage reactions unless a policy is configured
+	if !p.restricted() && !p.creatorOnly {
+		return true, nil
+	}
+
+	admin, err := isAdmin(ctx, bToken, p, user)
+	if err != nil {
+		err = errors.Wrap(err, "could not check for admin")
+		return false, err
+	}
+
+	if admin {
+		return true, nil
+	}
+
+	// only creators may change existing reactions
+	if p.creatorOnly && (action == updateSubCommand || action == deleteSubCommand || action == pauseSubCommand || action == resumeSubCommand) {
+
+		reactions, err := db.GetReactions(ctx, team, channel)
+		if err != nil {
+			err = errors.Wrap(err, "could not get reactions")
+			return false, err
+		}
+
+		// let the subcommand report the missing reaction
+		if len(reactions) == 0 {
+			return !p.restricted(), nil
+		}
+
+		for _, r := range reactions {
+			if r.CreatedBy == user {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	return !p.restricted(), nil
+}
+
+// canChange takes the reaction a user picked to update, delete, pause or resume and
+// returns true unless only its creator and admins may change it and the user is neither
+func canChange(ctx context.Context, bToken, user string, r *types.Reaction) (bool, error) {
+
+	p := getPolicy()
+
+	if !p.creatorOnly || r.CreatedBy == user {
+		return true, nil
+	}
+
+	return isAdmin(ctx, bToken, p, user)
+}
+
+// isAdmin takes a user and returns true if they are a skelly admin, a member of
+// a skelly admin group or a workspace admin or owner
+func isAdmin(ctx context.Context, bToken string, p *policy, user string) (bool, error) {
+
+	// skelly admins
+	if contains(p.admins, user) {
+		return true, nil
+	}
+
+	// create an api client
+	api := slackapi.For(bToken)
+
+	// workspace admins and owners
+	info, err := api.GetUserInfo(ctx, user)
+	if err != nil {
+		err = errors.Wrap(err, "could not get user info")
+		return false, err
+	}
+
+	if info.IsAdmin || info.IsOwner || info.IsPrimaryOwner {
+		return true, nil
+	}
+
+	// members of skelly admin groups
+	for _, group := range p.groups {
+
+		members, err := api.GetUserGroupMembers(ctx, group)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("could not get members of user group(%s)", group))
+			return false, err
+		}
+
+		if contains(members, user) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// checkPermission takes a user and the subcommand they are running against a channel and returns
+// true if they are authorized, otherwise the user is told they are not allowed in the origin channel
+func checkPermission(ctx context.Context, team, channel, origin, user, action string) (bool, error) {
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return false, err
+	}
+
+	allowed, err := authorize(ctx, bToken, team, channel, user, action)
+	if err != nil {
+		err = errors.Wrap(err, "could not authorize user")
+		return false, err
+	}
+
+	if allowed {
+		return true, nil
+	}
+
+	logging.FromContext(ctx).Infof("user(%s) is not allowed to %s in channel(%s)", user, action, channel)
+
+	// describe what the user tried to do
+	description := action + " reactions"
+	if action == announceSubCommand {
+		description = "manage announcements"
+	}
+
+	// notify user
+	return false, notifyUser(ctx, bToken, fmt.Sprintf("Sorry, you are not allowed to %s in <#%s>. Ask a workspace admin or a skelly admin for help.", description, channel), origin, user)
+}
+
+// splitList takes a comma separated list and returns its non-empty elements
+func splitList(s string) []string {
+
+	list := []string{}
+
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 {
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,97 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+)
+
+// severalReactionsError is returned when a channel has several reactions and none was picked by id
+type severalReactionsError struct {
+	channel   string
+	reactions []*types.Reaction
+}
+
+// Error lists the reactions in the channel to pick from
+func (e *severalReactionsError) Error() string {
+
+	choices := []string{}
+	for _, r := range e.reactions {
+		choices = append(choices, fmt.Sprintf("%s (%s)", r.ID.Hex(), describeTrigger(r)))
+	}
+
+	return fmt.Sprintf("channel(%s) has several reactions, pick one with --id: %s", e.channel, strings.Join(choices, ", "))
+}
+
+// Message describes the reactions in the channel to pick from for showing to the user
+// command is the slash command the user should run again with --id, ex: /skelly update
+func (e *severalReactionsError) Message(command string) string {
+
+	lines := []string{fmt.Sprintf("Sorry, <#%s> has several reactions. Pick one with `%s --id <id>`:", e.channel, command)}
+	for _, r := range e.reactions {
+		lines = append(lines, fmt.Sprintf("• `%s` responds to %s", r.ID.Hex(), describeTrigger(r)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// findReaction takes a channel and returns its reaction with id, or its only reaction when id is empty
+// returns nil if the reaction does not exist and a *severalReactionsError when an id is needed to pick one
+func findReaction(ctx context.Context, team, channel, id string) (*types.Reaction, error) {
+
+	// retrieve all of the reactions for the channel
+	reactions, err := db.GetReactions(ctx, team, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(id) > 0 {
+
+		for _, r := range reactions {
+			if r.ID.Hex() == id {
+				return r, nil
+			}
+		}
+
+		return nil, nil
+	}
+
+	switch len(reactions) {
+	case 0:
+		return nil, nil
+	case 1:
+		return reactions[0], nil
+	}
+
+	return nil, &severalReactionsError{channel: channel, reactions: reactions}
+}
+
+// missingReaction describes the reaction picked by id, or any reaction when id is empty,
+// not existing for the channel for showing to the user
+func missingReaction(channel, id string) string {
+
+	if len(id) > 0 {
+		return fmt.Sprintf("Sorry, reaction `%s` does not exist for <#%s>.", id, channel)
+	}
+
+	return fmt.Sprintf("Sorry, a reaction does not exist for <#%s>. Did you mean to add?", channel)
+}
+
+// parseIDArg takes the arguments following a subcommand that only picks a reaction and returns its id
+// returns an empty id when no arguments are provided
+// ex: /skelly delete --id 5f1d7a3c9e1b2a0001a1b2c3
+func parseIDArg(args []string) (string, error) {
+
+	switch {
+	case len(args) == 0:
+		return "", nil
+	case len(args) == 2 && strings.ToLower(args[0]) == "--id" && len(args[1]) > 0:
+		return args[1], nil
+	}
+
+	return "", errors.New("only --id may be provided, ex: --id 5f1d7a3c9e1b2a0001a1b2c3")
+}
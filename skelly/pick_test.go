@@ -0,0 +1,65 @@
+package skelly
+
+import "testing"
+
+func TestParseIDArg(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "no arguments", args: []string{}, want: ""},
+		{name: "id", args: []string{"--id", "5f1d7a3c9e1b2a0001a1b2c3"}, want: "5f1d7a3c9e1b2a0001a1b2c3"},
+		{name: "id in upper case", args: []string{"--ID", "5f1d7a3c9e1b2a0001a1b2c3"}, want: "5f1d7a3c9e1b2a0001a1b2c3"},
+		{name: "missing id", args: []string{"--id"}, wantErr: true},
+		{name: "empty id", args: []string{"--id", ""}, wantErr: true},
+		{name: "other flag", args: []string{"--cooldown", "1d"}, wantErr: true},
+		{name: "id and other flag", args: []string{"--id", "5f1d7a3c9e1b2a0001a1b2c3", "--cooldown", "1d"}, wantErr: true},
+		{name: "response", args: []string{"Hello!"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		got, err := parseIDArg(test.args)
+
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: parseIDArg(%q) returned no error", test.name, test.args)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: parseIDArg(%q) returned error: %v", test.name, test.args, err)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("%s: parseIDArg(%q) = %q, want %q", test.name, test.args, got, test.want)
+		}
+	}
+}
+
+func TestParseHomeActionValue(t *testing.T) {
+
+	tests := []struct {
+		value   string
+		channel string
+		id      string
+	}{
+		{value: homeActionValue("C123", "5f1d7a3c9e1b2a0001a1b2c3"), channel: "C123", id: "5f1d7a3c9e1b2a0001a1b2c3"},
+		{value: "C123", channel: "C123", id: ""},
+		{value: "C123:", channel: "C123", id: ""},
+	}
+
+	for _, test := range tests {
+
+		channel, id := parseHomeActionValue(test.value)
+
+		if channel != test.channel || id != test.id {
+			t.Errorf("parseHomeActionValue(%q) = %q, %q, want %q, %q", test.value, channel, id, test.channel, test.id)
+		}
+	}
+}
@@ -1,31 +1,67 @@
 package skelly
 
 import (
+	"context"
+	"sort"
+	"time"
+
 	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/tracing"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // React takes channel and reacts with the appropriate response based on application configuration.
-func React(bToken, channel, user, ts string) error {
+// trigger is what happened, ex: message or join, only reactions with that trigger respond.
+// text is the message that was posted, if any, for matching faq reactions.
+func React(ctx context.Context, bToken, team, channel, user, ts, trigger, text string) error {
+
+	ctx, span := tracing.Start(ctx, "skelly.React",
+		attribute.String("skelly.team", team),
+		attribute.String("skelly.channel", channel),
+		attribute.String("skelly.user", user),
+		attribute.String("skelly.ts", ts),
+		attribute.String("skelly.trigger", trigger))
+	defer span.End()
+
+	// typing reactions respond to messages
+	if len(trigger) == 0 || trigger == triggerTyping {
+		trigger = triggerMessage
+	}
+
+	// joins are not a message to thread on, ts only identifies the join
+	thread := ts
+	if trigger == triggerJoin {
+		thread = "none"
+	}
 
 	// retrieve all of the reactions for the channel
-	reactions, err := db.GetReactions(channel)
+	reactions, err := db.GetReactions(ctx, team, channel)
 	if err != nil {
 		err = errors.Wrap(err, "could not get reaction from db")
-		return err
+		return tracing.Error(span, err)
 	}
 
-	logrus.Infof("retreived (%v) reactions for channel(%s)", len(reactions), channel)
+	logging.FromContext(ctx).Debugf("retreived (%v) reactions for channel(%s)", len(reactions), channel)
+
+	// answer for the channel's working hours before its other reactions
+	sort.SliceStable(reactions, func(i, j int) bool {
+		return reactions[i].Kind == kindHours && reactions[j].Kind != kindHours
+	})
+
+	// only the faq most similar to the message answers it
+	answer, scores := bestFAQ(reactions, trigger, text)
 
 	// create an api client
-	api := slack.New(bToken)
+	api := slackapi.For(bToken)
 
 	// filter the reactions based on user id and channel
-	logrus.Infof("filtering reactions for channel(%s) user(%s)", channel, user)
+	logging.FromContext(ctx).Debugf("filtering reactions for channel(%s) user(%s)", channel, user)
 
-	logrus.Infof("reacting to (%v) reactions for channel(%s)", len(reactions), channel)
+	logging.FromContext(ctx).Debugf("reacting to (%v) reactions for channel(%s)", len(reactions), channel)
 
 	// respond to possibly multiple reactions
 	for _, r := range reactions {
@@ -35,50 +71,188 @@ func React(bToken, channel, user, ts string) error {
 			continue
 		}
 
-		// check database for existing response
-		exists, err := db.CheckResponse(channel, user, ts)
+		// do not react to other triggers
+		if reactionTrigger(r) != trigger {
+			logging.FromContext(ctx).Debugf("skipping, reaction does not respond to trigger(%s) in channel(%s)", trigger, channel)
+			continue
+		}
+
+		// do not answer messages unlike the faq reaction's questions
+		score := 0.0
+		if r.Kind == kindFAQ {
+
+			score = scores[r.ID.Hex()]
+			if score < faqThreshold(r) {
+				logging.FromContext(ctx).Debugf("skipping, message scored(%.2f) below the faq threshold(%.2f) in channel(%s)", score, faqThreshold(r), channel)
+				recordActivity(ctx, r, channel, user, trigger, skipNoMatch)
+				continue
+			}
+
+			if r.ID.Hex() != answer {
+				logging.FromContext(ctx).Debugf("skipping, message scored(%.2f) higher for faq(%s) in channel(%s)", score, answer, channel)
+				recordActivity(ctx, r, channel, user, trigger, skipOutscored)
+				continue
+			}
+		}
+
+		// do not react while the reaction is paused
+		if r.Paused {
+			logging.FromContext(ctx).Debugf("skipping, reaction is paused for channel(%s)", channel)
+			recordActivity(ctx, r, channel, user, trigger, skipPaused)
+			continue
+		}
+
+		// do not react outside of the reaction's schedule, or within the channel's working hours
+		active, err := reactionActive(r, time.Now())
+		if err != nil {
+			err = errors.Wrap(err, "could not check schedule")
+			return tracing.Error(span, err)
+		}
+
+		if !active {
+			logging.FromContext(ctx).Debugf("skipping, reaction is outside of its schedule for channel(%s)", channel)
+			recordActivity(ctx, r, channel, user, trigger, skipSchedule)
+			continue
+		}
+
+		// do not react if the reaction is limited to other users
+		if len(r.Users) > 0 && !contains(r.Users, user) {
+			logging.FromContext(ctx).Debugf("skipping, reaction is not for user(%s) in channel(%s)", user, channel)
+			recordActivity(ctx, r, channel, user, trigger, skipUsers)
+			continue
+		}
+
+		// do not react if the user was responded to within the cooldown
+		if r.Cooldown > 0 {
+
+			last, err := db.GetLastResponse(ctx, channel, user, r.ID.Hex())
+			if err != nil {
+				err = errors.Wrap(err, "could not get last response")
+				return tracing.Error(span, err)
+			}
+
+			if last != nil && time.Since(time.Unix(last.Created, 0)) < r.Cooldown {
+				logging.FromContext(ctx).Debugf("skipping, user(%s) in channel(%s) is within the cooldown(%s)", user, channel, r.Cooldown)
+				recordActivity(ctx, r, channel, user, trigger, skipCooldown)
+				continue
+			}
+		}
+
+		// check database for an existing response by this reaction, an emoji
+		// added to a message again by anyone does not fire again
+		exists := false
+		if isEmojiTrigger(trigger) {
+			exists, err = db.CheckMessageResponse(ctx, channel, ts, r.ID.Hex())
+		} else {
+			exists, err = db.CheckResponse(ctx, channel, user, ts, r.ID.Hex())
+		}
+
 		if err != nil {
 			err = errors.Wrap(err, "could not check for existing response")
-			return err
+			return tracing.Error(span, err)
 		}
 
 		// do not react if response already exists
 		if exists {
-			logrus.Infof("skipping, reaction response exists for channel(%s) user(%s) ts(%s)", channel, user, ts)
+			logging.FromContext(ctx).Debugf("skipping, reaction response exists for channel(%s) user(%s) ts(%s)", channel, user, ts)
+			recordActivity(ctx, r, channel, user, trigger, skipDuplicate)
 			continue
 		}
 
-		// create default msg options
-		options := []slack.MsgOption{
-			slack.MsgOptionText(r.Response, false),
-			slack.MsgOptionPostMessageParameters(
-				slack.PostMessageParameters{
-					LinkNames: 1, UnfurlMedia: true,
-				}),
-			slack.MsgOptionEnableLinkUnfurl(),
-		}
-
-		// add timestamp if applicable
-		if ts != "none" {
-			options = append(options, slack.MsgOptionTS(ts))
+		// pick one of the reaction's responses, preferring the user's locale
+		response, err := localizedResponse(ctx, api, team, r, user)
+		if err != nil {
+			err = errors.Wrap(err, "could not select response")
+			return tracing.Error(span, err)
 		}
 
 		// post the reaction
-		logrus.Infof("posting reaction for channel(%s) user(%s) ts(%s)", channel, user, ts)
+		logging.FromContext(ctx).Debugf("posting reaction for channel(%s) user(%s) ts(%s)", channel, user, ts)
+
+		// ask whether faq answers answered the question
+		var blocks []slack.Block
+		if r.Kind == kindFAQ {
+
+			blocks, err = faqFeedback(ctx, r, channel, user, ts, text, score)
+			if err != nil {
+				err = errors.Wrap(err, "could not ask for feedback")
+				return tracing.Error(span, err)
+			}
+		}
 
-		_, mts, err := api.PostMessage(channel, options...)
+		mts, err := postResponse(ctx, api, response, user, channel, thread, r.DirectMessage, blocks...)
 		if err != nil {
 			err = errors.Wrap(err, "could not post response")
-			return err
+			return tracing.Error(span, err)
 		}
 
-		err = db.StoreResponse(channel, user, ts)
+		err = db.StoreResponse(ctx, channel, user, ts, r.ID.Hex())
 		if err != nil {
 			err = errors.Wrap(err, "response posted, but could not remember the response")
-			return err
+			return tracing.Error(span, err)
 		}
 
-		logrus.Infof("reaction posted for channel(%s) user(%s) ts(%s) at msg_ts(%s)", channel, user, ts, mts)
+		logging.FromContext(ctx).Infof("reaction posted for channel(%s) user(%s) ts(%s) at msg_ts(%s)", channel, user, ts, mts)
+
+		recordActivity(ctx, r, channel, user, trigger, "")
 	}
 	return nil
 }
+
+// postResponse takes a response, renders it for the user and channel and posts it
+// replies in the thread of ts unless it is none, or to the user in a direct message when dm is set
+// blocks are shown after the response, returns the posted message's timestamp
+func postResponse(ctx context.Context, api slackapi.Client, response, user, channel, ts string, dm bool, blocks ...slack.Block) (string, error) {
+
+	// render the response for the user and channel
+	text, err := renderResponse(response, user, channel)
+	if err != nil {
+		err = errors.Wrap(err, "could not render response")
+		return "", err
+	}
+
+	// create default msg options
+	options := []slack.MsgOption{
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionPostMessageParameters(
+			slack.PostMessageParameters{
+				LinkNames: 1, UnfurlMedia: true,
+			}),
+		slack.MsgOptionEnableLinkUnfurl(),
+	}
+
+	// show the response followed by the blocks, the text remains the notification fallback
+	if len(blocks) > 0 {
+		section := slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil)
+		options = append(options, slack.MsgOptionBlocks(append([]slack.Block{section}, blocks...)...))
+	}
+
+	// posting to a user id opens a direct message with them
+	destination := channel
+	if dm {
+		destination = user
+	}
+
+	// add timestamp if applicable, direct messages are not in the channel's threads
+	if ts != "none" && !dm {
+		options = append(options, slack.MsgOptionTS(ts))
+	}
+
+	_, mts, err := api.PostMessage(ctx, destination, options...)
+	if err != nil {
+		err = errors.Wrap(err, "could not post message")
+		return "", err
+	}
+
+	return mts, nil
+}
+
+// contains takes a slice and returns true if it contains s
+func contains(slice []string, s string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
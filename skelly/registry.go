@@ -0,0 +1,58 @@
+package skelly
+
+import (
+	"context"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/slack-go/slack"
+)
+
+// interactionHandler handles an interaction routed by its callback id
+type interactionHandler func(ctx context.Context, callback *slack.InteractionCallback) error
+
+// actionHandler handles a single block action routed by its action id
+type actionHandler func(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error
+
+// submissionHandler handles a modal submission routed by its callback id
+// returns the response_action for the modal, such as errors for invalid input
+type submissionHandler func(ctx context.Context, view *slack.View, team, user string) (*slack.ViewSubmissionResponse, error)
+
+var (
+	// blockActions are the handlers for buttons and menus, keyed by action_id
+	blockActions = map[string]actionHandler{
+		homeEditAction:   handleHomeAction,
+		homePauseAction:  handleHomeAction,
+		homeDeleteAction: handleHomeAction,
+		undoDeleteAction: handleUndoDelete,
+
+		faqHelpfulAction:   handleFAQFeedback,
+		faqUnhelpfulAction: handleFAQFeedback,
+	}
+
+	// shortcuts are the handlers for global shortcuts, keyed by callback_id
+	shortcuts = map[string]interactionHandler{}
+
+	// messageActions are the handlers for message shortcuts, keyed by callback_id
+	messageActions = map[string]interactionHandler{
+		createFromMessageCallback: handleCreateFromMessage,
+	}
+
+	// viewSubmissions are the handlers for modal submissions, keyed by the view's callback_id
+	viewSubmissions = map[string]submissionHandler{
+		addSubCommand:    handleAddSubmission,
+		updateSubCommand: handleUpdateSubmission,
+		deleteSubCommand: handleDeleteSubmission,
+	}
+
+	// viewClosed are the handlers for modals closed by the user, keyed by the view's callback_id
+	// slack only sends view_closed for modals opened with notify_on_close
+	viewClosed = map[string]interactionHandler{}
+)
+
+// ignoreInteraction acknowledges an interaction skelly has no handler for
+func ignoreInteraction(ctx context.Context, callback *slack.InteractionCallback) error {
+
+	logging.FromContext(ctx).Debugf("ignoring interaction type(%s) callback_id(%s)", callback.Type, callback.View.CallbackID)
+
+	return nil
+}
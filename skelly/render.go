@@ -0,0 +1,80 @@
+package skelly
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxResponseLength is the longest response slack displays without truncating
+	maxResponseLength = 4000
+)
+
+// responseData is the data available to response templates
+// ex: Welcome {{.User}}! Please read the pinned posts in {{.Channel}}.
+type responseData struct {
+	// User mentions the user being responded to
+	User string
+	// Channel mentions the channel being responded in
+	Channel string
+}
+
+// renderResponse takes a response and executes it as a template for the user and channel
+func renderResponse(response, user, channel string) (string, error) {
+
+	// responses without actions are used as is
+	if !strings.Contains(response, "{{") {
+		return response, nil
+	}
+
+	t, err := template.New("response").Option("missingkey=error").Parse(response)
+	if err != nil {
+		err = errors.Wrap(err, "could not parse response template")
+		return "", err
+	}
+
+	data := responseData{
+		Channel: fmt.Sprintf("<#%s>", channel),
+	}
+
+	// announcements added from the cli have no user to mention
+	if len(user) > 0 {
+		data.User = fmt.Sprintf("<@%s>", user)
+	}
+
+	buffer := new(bytes.Buffer)
+
+	err = t.Execute(buffer, data)
+	if err != nil {
+		err = errors.Wrap(err, "could not execute response template")
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+// validateResponse takes a response and returns an error describing why it cannot be used
+// meant for showing to the user
+func validateResponse(response string) error {
+
+	if len(strings.TrimSpace(response)) == 0 {
+		return errors.New("Please enter a response.")
+	}
+
+	if utf8.RuneCountInString(response) > maxResponseLength {
+		return fmt.Errorf("Responses are limited to %d characters.", maxResponseLength)
+	}
+
+	// render with placeholder values to catch unknown fields
+	_, err := renderResponse(response, "U00000000", "C00000000")
+	if err != nil {
+		return fmt.Errorf("The response template is invalid: %s.", errors.Cause(err))
+	}
+
+	return nil
+}
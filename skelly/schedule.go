@@ -0,0 +1,325 @@
+package skelly
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// dateLayout is the layout of schedule start and end dates
+	dateLayout = "2006-01-02"
+	// clockLayout is the layout of schedule business hours
+	clockLayout = "15:04"
+
+	// anyValue clears a schedule field, ex: --days any
+	anyValue = "any"
+)
+
+// weekdays are the abbreviated days of the week, in order
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ScheduleOptions are the raw values for limiting when a reaction is active
+// empty values are left unchanged and "any" clears the value
+// ex: --days mon-fri --hours 09:00-17:00 --tz America/Chicago
+type ScheduleOptions struct {
+	// Days are the days of the week, ex: mon-fri, weekends or mon,wed,fri
+	Days string
+	// Hours are the business hours, ex: 09:00-17:00
+	Hours string
+	// TimeZone is the location the schedule is in, ex: America/Chicago
+	TimeZone string
+	// From is the first date the reaction is active, ex: 2021-01-04
+	From string
+	// Until is the last date the reaction is active, ex: 2021-01-08
+	Until string
+	// Always removes the schedule before applying the other values
+	Always bool
+}
+
+// Empty returns true if no schedule options were provided
+func (o *ScheduleOptions) Empty() bool {
+	return *o == ScheduleOptions{}
+}
+
+// applySchedule takes the current schedule for a reaction and returns it with the options applied
+// returns nil when the reaction is always active
+func applySchedule(current *types.Schedule, o *ScheduleOptions) (*types.Schedule, error) {
+
+	s := new(types.Schedule)
+	if current != nil && !o.Always {
+		*s = *current
+	}
+
+	// days of the week
+	if len(o.Days) > 0 {
+		days, err := parseWeekdays(o.Days)
+		if err != nil {
+			return nil, err
+		}
+		s.Weekdays = days
+	}
+
+	// business hours
+	if len(o.Hours) > 0 {
+		from, to, err := parseHours(o.Hours)
+		if err != nil {
+			return nil, err
+		}
+		s.From, s.To = from, to
+	}
+
+	// time zone
+	if len(o.TimeZone) > 0 {
+		s.TimeZone = ""
+
+		if o.TimeZone != anyValue {
+			_, err := time.LoadLocation(o.TimeZone)
+			if err != nil {
+				return nil, fmt.Errorf("unknown time zone %s, ex: America/Chicago", o.TimeZone)
+			}
+			s.TimeZone = o.TimeZone
+		}
+	}
+
+	// start and end dates
+	if len(o.From) > 0 {
+		d, err := parseDate(o.From)
+		if err != nil {
+			return nil, err
+		}
+		s.Start = d
+	}
+
+	if len(o.Until) > 0 {
+		d, err := parseDate(o.Until)
+		if err != nil {
+			return nil, err
+		}
+		s.End = d
+	}
+
+	// dates use the same layout so they compare as strings
+	if len(s.Start) > 0 && len(s.End) > 0 && s.Start > s.End {
+		return nil, fmt.Errorf("the start date %s is after the end date %s", s.Start, s.End)
+	}
+
+	// a time zone alone does not limit the reaction
+	if len(s.Weekdays) == 0 && len(s.From) == 0 && len(s.Start) == 0 && len(s.End) == 0 {
+		return nil, nil
+	}
+
+	return s, nil
+}
+
+// parseWeekdays takes days such as mon-fri, weekdays, weekends or mon,wed,fri
+// and returns the abbreviated days in order
+func parseWeekdays(value string) ([]string, error) {
+
+	value = strings.ToLower(value)
+
+	switch value {
+	case anyValue:
+		return nil, nil
+	case "weekdays":
+		value = "mon-fri"
+	case "weekends":
+		value = "sat,sun"
+	}
+
+	active := map[int]bool{}
+
+	for _, part := range strings.Split(value, ",") {
+
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+
+		start := weekdayIndex(bounds[0])
+		end := start
+
+		if len(bounds) == 2 {
+			end = weekdayIndex(bounds[1])
+		}
+
+		if start < 0 || end < 0 {
+			return nil, fmt.Errorf("invalid days %s, ex: mon-fri, weekends, mon,wed,fri", value)
+		}
+
+		// ranges may wrap around the week, ex: fri-mon
+		for i := start; ; i = (i + 1) % len(weekdays) {
+			active[i] = true
+			if i == end {
+				break
+			}
+		}
+	}
+
+	days := []string{}
+	for i, day := range weekdays {
+		if active[i] {
+			days = append(days, day)
+		}
+	}
+
+	return days, nil
+}
+
+// weekdayIndex takes a day of the week and returns its index in weekdays, or -1
+func weekdayIndex(day string) int {
+
+	for i, d := range weekdays {
+		if len(day) >= 3 && strings.HasPrefix(day, d) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// parseHours takes business hours such as 09:00-17:00 and returns the start and end times
+// hours that end before they start span midnight, ex: 22:00-06:00
+func parseHours(value string) (string, string, error) {
+
+	if value == anyValue {
+		return "", "", nil
+	}
+
+	bounds := strings.SplitN(value, "-", 2)
+	if len(bounds) != 2 {
+		return "", "", fmt.Errorf("invalid hours %s, ex: 09:00-17:00", value)
+	}
+
+	from, err := time.Parse(clockLayout, strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid hours %s, ex: 09:00-17:00", value)
+	}
+
+	to, err := time.Parse(clockLayout, strings.TrimSpace(bounds[1]))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid hours %s, ex: 09:00-17:00", value)
+	}
+
+	if from.Equal(to) {
+		return "", "", fmt.Errorf("invalid hours %s, the start and end must differ", value)
+	}
+
+	return from.Format(clockLayout), to.Format(clockLayout), nil
+}
+
+// parseDate takes a date such as 2021-01-04 and returns it in the schedule layout
+func parseDate(value string) (string, error) {
+
+	if value == anyValue {
+		return "", nil
+	}
+
+	d, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %s, ex: 2021-01-04", value)
+	}
+
+	return d.Format(dateLayout), nil
+}
+
+// scheduleActive takes a schedule and returns true if a reaction following it is active at now
+func scheduleActive(s *types.Schedule, now time.Time) (bool, error) {
+
+	// reactions without a schedule are always active
+	if s == nil {
+		return true, nil
+	}
+
+	// evaluate the schedule in its time zone
+	loc := time.UTC
+	if len(s.TimeZone) > 0 {
+
+		l, err := time.LoadLocation(s.TimeZone)
+		if err != nil {
+			err = errors.Wrap(err, "could not load time zone")
+			return false, err
+		}
+
+		loc = l
+	}
+
+	now = now.In(loc)
+
+	// dates are inclusive
+	date := now.Format(dateLayout)
+
+	if len(s.Start) > 0 && date < s.Start {
+		return false, nil
+	}
+
+	if len(s.End) > 0 && date > s.End {
+		return false, nil
+	}
+
+	// days of the week
+	if len(s.Weekdays) > 0 && !contains(s.Weekdays, weekdays[now.Weekday()]) {
+		return false, nil
+	}
+
+	// business hours, the end is exclusive
+	if len(s.From) > 0 && len(s.To) > 0 {
+
+		clock := now.Format(clockLayout)
+
+		if s.From < s.To {
+			return clock >= s.From && clock < s.To, nil
+		}
+
+		// hours span midnight
+		return clock >= s.From || clock < s.To, nil
+	}
+
+	return true, nil
+}
+
+// describeSchedule takes a schedule and describes when a reaction following it is active
+// ex: mon-fri 09:00-17:00 America/Chicago, from 2021-01-04
+func describeSchedule(s *types.Schedule) string {
+
+	if s == nil {
+		return "always"
+	}
+
+	parts := []string{}
+
+	if len(s.Weekdays) > 0 {
+		parts = append(parts, strings.Join(s.Weekdays, ","))
+	}
+
+	if len(s.From) > 0 {
+		parts = append(parts, s.From+"-"+s.To)
+	}
+
+	if len(parts) > 0 && len(s.TimeZone) > 0 {
+		parts = append(parts, s.TimeZone)
+	}
+
+	description := strings.Join(parts, " ")
+
+	dates := []string{}
+
+	if len(s.Start) > 0 {
+		dates = append(dates, "from "+s.Start)
+	}
+
+	if len(s.End) > 0 {
+		dates = append(dates, "until "+s.End)
+	}
+
+	if len(dates) > 0 {
+
+		if len(description) > 0 {
+			description += ", "
+		}
+
+		description += strings.Join(dates, " ")
+	}
+
+	return description
+}
@@ -0,0 +1,96 @@
+package skelly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/davidvader/skelly/types"
+)
+
+func TestScheduleActive(t *testing.T) {
+
+	weekdaysOnly := []string{"mon", "tue", "wed", "thu", "fri"}
+
+	// 2021-01-04 is a monday
+	monday := func(hour, minute int) time.Time {
+		return time.Date(2021, 1, 4, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		schedule *types.Schedule
+		now      time.Time
+		want     bool
+		wantErr  bool
+	}{
+		{name: "no schedule", schedule: nil, now: monday(3, 0), want: true},
+		{name: "empty schedule", schedule: &types.Schedule{}, now: monday(3, 0), want: true},
+
+		{name: "before start", schedule: &types.Schedule{Start: "2021-01-05"}, now: monday(12, 0), want: false},
+		{name: "on start", schedule: &types.Schedule{Start: "2021-01-04"}, now: monday(0, 0), want: true},
+		{name: "on end", schedule: &types.Schedule{End: "2021-01-04"}, now: monday(23, 59), want: true},
+		{name: "after end", schedule: &types.Schedule{End: "2021-01-03"}, now: monday(0, 0), want: false},
+
+		{name: "weekday", schedule: &types.Schedule{Weekdays: weekdaysOnly}, now: monday(12, 0), want: true},
+		{name: "weekend", schedule: &types.Schedule{Weekdays: weekdaysOnly}, now: monday(12, 0).AddDate(0, 0, -1), want: false},
+
+		{name: "within hours", schedule: &types.Schedule{From: "09:00", To: "17:00"}, now: monday(9, 0), want: true},
+		{name: "before hours", schedule: &types.Schedule{From: "09:00", To: "17:00"}, now: monday(8, 59), want: false},
+		{name: "end of hours is exclusive", schedule: &types.Schedule{From: "09:00", To: "17:00"}, now: monday(17, 0), want: false},
+		{name: "overnight late", schedule: &types.Schedule{From: "22:00", To: "06:00"}, now: monday(23, 0), want: true},
+		{name: "overnight early", schedule: &types.Schedule{From: "22:00", To: "06:00"}, now: monday(5, 59), want: true},
+		{name: "overnight midday", schedule: &types.Schedule{From: "22:00", To: "06:00"}, now: monday(12, 0), want: false},
+
+		{
+			name:     "hours in the time zone",
+			schedule: &types.Schedule{From: "09:00", To: "17:00", TimeZone: "America/Chicago"},
+			now:      monday(15, 0),
+			want:     true,
+		},
+		{
+			name:     "hours outside the time zone",
+			schedule: &types.Schedule{From: "09:00", To: "17:00", TimeZone: "America/Chicago"},
+			now:      monday(9, 0),
+			want:     false,
+		},
+		{
+			name:     "weekday in the time zone",
+			schedule: &types.Schedule{Weekdays: weekdaysOnly, TimeZone: "America/Chicago"},
+			now:      monday(3, 0),
+			want:     false,
+		},
+		{
+			name:     "date in the time zone",
+			schedule: &types.Schedule{Start: "2021-01-04", TimeZone: "America/Chicago"},
+			now:      monday(3, 0),
+			want:     false,
+		},
+		{
+			name:     "unknown time zone",
+			schedule: &types.Schedule{TimeZone: "Mars/Olympus_Mons"},
+			now:      monday(12, 0),
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+
+		got, err := scheduleActive(test.schedule, test.now)
+
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: scheduleActive returned no error", test.name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: scheduleActive returned error: %v", test.name, err)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("%s: scheduleActive(%s) = %v, want %v", test.name, test.now, got, test.want)
+		}
+	}
+}
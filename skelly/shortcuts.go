@@ -0,0 +1,46 @@
+package skelly
+
+import (
+	"context"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// createFromMessageCallback is the callback id of the message shortcut
+	// "Create skelly reaction from this message"
+	createFromMessageCallback = "create_reaction_from_message"
+)
+
+// handleCreateFromMessage takes a message shortcut and opens the add modal
+// for the message's channel with the message's text as the response
+func handleCreateFromMessage(ctx context.Context, callback *slack.InteractionCallback) error {
+
+	team := callback.Team.ID
+	channel := callback.Channel.ID
+	user := callback.User.ID
+
+	logging.FromContext(ctx).Debugf("creating reaction from message ts(%s) in channel(%s)", callback.Message.Timestamp, channel)
+
+	// check that the user may add reactions in the channel
+	allowed, err := checkPermission(ctx, team, channel, channel, user, addSubCommand)
+	if err != nil {
+		err = errors.Wrap(err, "could not check permission")
+		return err
+	}
+
+	if !allowed {
+		return nil
+	}
+
+	// open the add modal, pre-filled with the message
+	err = showAddModal(ctx, team, channel, channel, user, callback.TriggerID, callback.Message.Text)
+	if err != nil {
+		err = errors.Wrap(err, "could not open add modal")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,417 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/types"
+	"github.com/davidvader/skelly/util"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// statsSubCommand shows how often reactions fired, ex: /skelly stats --since 7d
+	statsSubCommand = "stats"
+
+	// defaultStatsWindow is how far back stats go when --since is not given
+	defaultStatsWindow = 30 * 24 * time.Hour
+	// statsTrendDays is how many days of the trend are shown in slack
+	statsTrendDays = 14
+	// statsBarWidth is the width of the longest bar in a trend
+	statsBarWidth = 20
+
+	// skipPaused is recorded when a reaction is paused
+	skipPaused = "paused"
+	// skipSchedule is recorded when a reaction is outside of its schedule
+	skipSchedule = "schedule"
+	// skipUsers is recorded when a reaction is limited to other users
+	skipUsers = "users"
+	// skipCooldown is recorded when the user was responded to within the cooldown
+	skipCooldown = "cooldown"
+	// skipDuplicate is recorded when the message was already responded to
+	skipDuplicate = "duplicate"
+	// skipNoMatch is recorded when a message is unlike an faq reaction's questions
+	skipNoMatch = "no_match"
+	// skipOutscored is recorded when a message is more like another faq reaction's questions
+	skipOutscored = "outscored"
+
+	// defaultActivityRetention is how long activity is kept for stats
+	defaultActivityRetention = 90 * 24 * time.Hour
+)
+
+// activityRetention returns how long activity is kept for stats, from SKELLY_ACTIVITY_RETENTION
+func activityRetention() time.Duration {
+
+	d, err := parseCooldown(os.Getenv("SKELLY_ACTIVITY_RETENTION"))
+	if err != nil || d <= 0 {
+		return defaultActivityRetention
+	}
+
+	return d
+}
+
+// recordActivity takes a reaction and records that it fired, or why it was skipped when reason is set
+// failures are logged rather than returned so analytics never stop a response
+func recordActivity(ctx context.Context, r *types.Reaction, channel, user, trigger, reason string) {
+
+	now := time.Now()
+
+	activity := &types.Activity{
+		Team:     r.Team,
+		Channel:  channel,
+		Reaction: r.ID.Hex(),
+		User:     user,
+		Trigger:  trigger,
+		Fired:    len(reason) == 0,
+		Reason:   reason,
+		Created:  now.Unix(),
+		Expires:  now.Add(activityRetention()),
+	}
+
+	// reactions are skipped far more often than they fire, count skips per day instead of adding one per skip
+	if len(reason) > 0 {
+		activity.User = ""
+
+		err := db.CountActivity(ctx, activity)
+		if err != nil {
+			logging.FromContext(ctx).Errorf("could not count activity for channel(%s): %v", channel, err)
+		}
+
+		return
+	}
+
+	err := db.AddActivity(ctx, activity)
+	if err != nil {
+		logging.FromContext(ctx).Errorf("could not record activity for channel(%s): %v", channel, err)
+	}
+}
+
+// getActivityStats takes a team and channel and summarizes how its reactions fired since a time
+// summarizes every channel in the team when channel is empty
+func getActivityStats(ctx context.Context, team, channel string, since time.Time) (*types.SkellyStats, error) {
+
+	stats := &types.SkellyStats{
+		Channels: map[string]*types.ChannelStats{},
+	}
+
+	// count the reactions being summarized
+	if len(channel) == 0 {
+
+		s, err := getStats(ctx, team)
+		if err != nil {
+			err = errors.Wrap(err, "could not get stats")
+			return nil, err
+		}
+
+		stats = s
+	} else {
+
+		reactions, err := db.GetChannelReactions(ctx, team, channel)
+		if err != nil {
+			err = errors.Wrap(err, "could not get reactions from db")
+			return nil, err
+		}
+
+		stats.Channels[channel] = &types.ChannelStats{TotalRules: len(*reactions)}
+		stats.TotalChannels = 1
+	}
+
+	// retrieve what the reactions did
+	activity, err := db.GetActivity(ctx, team, channel, since.Unix())
+	if err != nil {
+		err = errors.Wrap(err, "could not get activity")
+		return nil, err
+	}
+
+	aggregateActivity(stats, activity)
+
+	return stats, nil
+}
+
+// aggregateActivity takes stats and adds the fired and skipped reactions to them
+func aggregateActivity(stats *types.SkellyStats, activity []types.Activity) {
+
+	stats.SkipReasons = map[string]int{}
+	stats.Daily = map[string]int{}
+
+	for _, c := range stats.Channels {
+		c.Reactions = map[string]int{}
+		c.SkipReasons = map[string]int{}
+		c.Daily = map[string]int{}
+	}
+
+	users := map[string]bool{}
+	channelUsers := map[string]map[string]bool{}
+
+	for _, a := range activity {
+
+		c, ok := stats.Channels[a.Channel]
+
+		// the channel's reactions were deleted since
+		if !ok {
+			c = &types.ChannelStats{
+				Reactions:   map[string]int{},
+				SkipReasons: map[string]int{},
+				Daily:       map[string]int{},
+			}
+			stats.Channels[a.Channel] = c
+		}
+
+		if !a.Fired {
+
+			// counted activity stands for several skips
+			n := a.Count
+			if n == 0 {
+				n = 1
+			}
+
+			c.Skipped += n
+			c.SkipReasons[a.Reason] += n
+			stats.Skipped += n
+			stats.SkipReasons[a.Reason] += n
+			continue
+		}
+
+		day := time.Unix(a.Created, 0).UTC().Format(dateLayout)
+
+		c.Fired++
+		c.Reactions[a.Reaction]++
+		c.Daily[day]++
+		stats.Fired++
+		stats.Daily[day]++
+
+		if channelUsers[a.Channel] == nil {
+			channelUsers[a.Channel] = map[string]bool{}
+		}
+
+		channelUsers[a.Channel][a.User] = true
+		users[a.User] = true
+	}
+
+	for channel, u := range channelUsers {
+		stats.Channels[channel].Users = len(u)
+	}
+
+	stats.Users = len(users)
+}
+
+// parseStatsArgs takes the arguments following stats and parses them
+// ex: all --since 7d
+func parseStatsArgs(args []string) (bool, time.Duration, error) {
+
+	all := false
+	window := defaultStatsWindow
+
+	for i := 0; i < len(args); i++ {
+
+		switch strings.ToLower(args[i]) {
+		case allArg:
+			all = true
+
+		case "--since":
+
+			if i+1 >= len(args) {
+				return false, 0, errors.New("--since requires a value, ex: --since 7d")
+			}
+			i++
+
+			d, err := ParseStatsWindow(args[i])
+			if err != nil {
+				return false, 0, err
+			}
+
+			window = d
+
+		default:
+			return false, 0, fmt.Errorf("unknown argument %s, ex: /skelly stats all --since 7d", args[i])
+		}
+	}
+
+	return all, window, nil
+}
+
+// ParseStatsWindow takes how far back to summarize activity, ex: 7d, and returns it as a time.Duration
+func ParseStatsWindow(s string) (time.Duration, error) {
+
+	d, err := parseCooldown(s)
+	if err != nil || d == 0 {
+		return 0, fmt.Errorf("invalid --since %s, ex: 12h, 7d, 4w", s)
+	}
+
+	return d, nil
+}
+
+// handleStats takes slash command configuration and arguments and shows how the reactions fired
+// ex: /skelly stats
+// ex: /skelly stats all --since 7d
+func handleStats(ctx context.Context, s *slack.SlashCommand, args []string) error {
+
+	team := s.TeamID
+	channel := s.ChannelID
+
+	text := ""
+
+	all, window, err := parseStatsArgs(args)
+	if err != nil {
+		text = fmt.Sprintf("Sorry, %s.", err)
+	}
+
+	if err == nil {
+
+		// stats for the whole workspace
+		if all || isDirectMessage(channel) {
+
+			// check that the user may see reactions for every channel, the same as /skelly list all
+			allowed, err := checkPermission(ctx, team, channel, channel, s.UserID, listSubCommand)
+			if err != nil {
+				err = errors.Wrap(err, "could not check permission")
+				return err
+			}
+
+			if !allowed {
+				return nil
+			}
+
+			channel = ""
+		}
+
+		since := time.Now().Add(-window)
+
+		stats, err := getActivityStats(ctx, team, channel, since)
+		if err != nil {
+			err = errors.Wrap(err, "could not get activity stats")
+			return err
+		}
+
+		text = describeStats(stats, channel, since)
+	}
+
+	// respond to the user
+	err = util.Respond(ctx, s.ResponseURL, slack.Msg{Text: text})
+	if err != nil {
+		err = errors.Wrap(err, "could not respond")
+		return err
+	}
+
+	return nil
+}
+
+// describeStats takes stats and describes them for slack
+// describes every channel when channel is empty
+func describeStats(stats *types.SkellyStats, channel string, since time.Time) string {
+
+	where := "this workspace"
+	if len(channel) > 0 {
+		where = fmt.Sprintf("<#%s>", channel)
+	}
+
+	lines := []string{
+		fmt.Sprintf("Since %s, reactions in %s fired *%d* times for *%d* users and were skipped *%d* times.",
+			describeRun(since.Unix()), where, stats.Fired, stats.Users, stats.Skipped),
+	}
+
+	if len(stats.SkipReasons) > 0 {
+
+		reasons := []string{}
+		for _, reason := range sortedCounts(stats.SkipReasons) {
+			reasons = append(reasons, fmt.Sprintf("%s %d", reason, stats.SkipReasons[reason]))
+		}
+
+		lines = append(lines, "*Skipped*: "+strings.Join(reasons, ", "))
+	}
+
+	// fires per channel, or per reaction within a channel
+	if len(channel) == 0 {
+
+		fires := map[string]int{}
+		for c, s := range stats.Channels {
+			fires[c] = s.Fired
+		}
+
+		for _, c := range sortedCounts(fires) {
+			s := stats.Channels[c]
+			lines = append(lines, fmt.Sprintf("• <#%s>: fired %d times for %d users, skipped %d times", c, s.Fired, s.Users, s.Skipped))
+		}
+	} else if s, ok := stats.Channels[channel]; ok && len(s.Reactions) > 1 {
+
+		for _, r := range sortedCounts(s.Reactions) {
+			lines = append(lines, fmt.Sprintf("• reaction `%s`: fired %d times", r, s.Reactions[r]))
+		}
+	}
+
+	// daily trend
+	days := trendDays(since, time.Now())
+	if len(days) > statsTrendDays {
+		days = days[len(days)-statsTrendDays:]
+	}
+
+	lines = append(lines, "*Trend*:")
+
+	trend := []string{}
+	for _, day := range days {
+		trend = append(trend, fmt.Sprintf("%s %s %d", day, trendBar(stats.Daily[day], stats.Daily), stats.Daily[day]))
+	}
+
+	lines = append(lines, "```"+strings.Join(trend, "\n")+"```")
+
+	return strings.Join(lines, "\n")
+}
+
+// trendDays takes a time range and returns every day in it, ex: 2021-01-04
+func trendDays(since, now time.Time) []string {
+
+	days := []string{}
+
+	for d := since.UTC().Truncate(24 * time.Hour); !d.After(now.UTC()); d = d.AddDate(0, 0, 1) {
+		days = append(days, d.Format(dateLayout))
+	}
+
+	return days
+}
+
+// trendBar takes a count and all of the counts in a trend and draws a bar scaled to the largest
+func trendBar(n int, counts map[string]int) string {
+
+	largest := 0
+	for _, c := range counts {
+		if c > largest {
+			largest = c
+		}
+	}
+
+	if largest == 0 || n == 0 {
+		return ""
+	}
+
+	width := n * statsBarWidth / largest
+	if width == 0 {
+		width = 1
+	}
+
+	return strings.Repeat("█", width)
+}
+
+// sortedCounts takes counts and returns their keys from the highest count to the lowest
+func sortedCounts(counts map[string]int) []string {
+
+	keys := []string{}
+	for k := range counts {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	return keys
+}
@@ -0,0 +1,110 @@
+package skelly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/davidvader/skelly/types"
+)
+
+func TestAggregateActivity(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		activity    []types.Activity
+		wantFired   int
+		wantSkipped int
+		wantNoMatch int
+		wantUsers   int
+	}{
+		{name: "no activity"},
+		{
+			name: "fired",
+			activity: []types.Activity{
+				{Channel: "C1", Reaction: "r1", User: "U1", Fired: true},
+				{Channel: "C1", Reaction: "r1", User: "U1", Fired: true},
+				{Channel: "C1", Reaction: "r1", User: "U2", Fired: true},
+			},
+			wantFired: 3,
+			wantUsers: 2,
+		},
+		{
+			name: "skipped once each",
+			activity: []types.Activity{
+				{Channel: "C1", Reaction: "r1", User: "U1", Reason: skipCooldown},
+				{Channel: "C1", Reaction: "r1", User: "U2", Reason: skipNoMatch},
+			},
+			wantSkipped: 2,
+			wantNoMatch: 1,
+		},
+		{
+			name: "counted skips",
+			activity: []types.Activity{
+				{Channel: "C1", Reaction: "r1", Reason: skipNoMatch, Count: 40},
+				{Channel: "C2", Reaction: "r2", Reason: skipNoMatch, Count: 2},
+				{Channel: "C1", Reaction: "r1", Reason: skipCooldown, Count: 3},
+				{Channel: "C1", Reaction: "r1", User: "U1", Fired: true},
+			},
+			wantFired:   1,
+			wantSkipped: 45,
+			wantNoMatch: 42,
+			wantUsers:   1,
+		},
+	}
+
+	for _, test := range tests {
+
+		stats := &types.SkellyStats{
+			Channels: map[string]*types.ChannelStats{"C1": {TotalRules: 1}},
+		}
+
+		aggregateActivity(stats, test.activity)
+
+		if stats.Fired != test.wantFired {
+			t.Errorf("%s: fired = %d, want %d", test.name, stats.Fired, test.wantFired)
+		}
+
+		if stats.Skipped != test.wantSkipped {
+			t.Errorf("%s: skipped = %d, want %d", test.name, stats.Skipped, test.wantSkipped)
+		}
+
+		if stats.SkipReasons[skipNoMatch] != test.wantNoMatch {
+			t.Errorf("%s: no match skips = %d, want %d", test.name, stats.SkipReasons[skipNoMatch], test.wantNoMatch)
+		}
+
+		if stats.Users != test.wantUsers {
+			t.Errorf("%s: users = %d, want %d", test.name, stats.Users, test.wantUsers)
+		}
+	}
+}
+
+func TestParseStatsWindow(t *testing.T) {
+
+	tests := []struct {
+		since   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{since: "12h", want: 12 * time.Hour},
+		{since: "7d", want: 7 * 24 * time.Hour},
+		{since: "4w", want: 4 * 7 * 24 * time.Hour},
+		{since: "0", wantErr: true},
+		{since: "0d", wantErr: true},
+		{since: "-1h", wantErr: true},
+		{since: "week", wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		got, err := ParseStatsWindow(test.since)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: err = %v, wantErr %t", test.since, err, test.wantErr)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("%s: window = %s, want %s", test.since, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/pkg/errors"
+)
+
+// botToken takes a team id and returns the bot token stored when that team installed skelly
+// falls back to SKELLY_BOT_TOKEN for single workspace deployments
+func botToken(ctx context.Context, team string) (string, error) {
+
+	// retrieve the single workspace token from the environment
+	bToken := os.Getenv("SKELLY_BOT_TOKEN")
+
+	// look up the token for the team, if provided
+	if len(team) > 0 {
+
+		t, err := db.GetTeam(ctx, team)
+		if err != nil {
+			err = errors.Wrap(err, "could not get team from db")
+			return "", err
+		}
+
+		if t != nil && len(t.BotToken) > 0 {
+			return t.BotToken, nil
+		}
+	}
+
+	if len(bToken) == 0 {
+		return "", fmt.Errorf("no bot token for team(%s), has skelly been installed?", team)
+	}
+
+	return bToken, nil
+}
@@ -0,0 +1,191 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/types"
+	"github.com/davidvader/skelly/util"
+	"github.com/pkg/errors"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// undoDeleteAction restores the reactions moved to the trash by a delete
+	undoDeleteAction = "undo_delete"
+
+	// defaultTrashRetention is how long deleted reactions can be restored
+	defaultTrashRetention = 24 * time.Hour
+)
+
+// trashRetention returns how long deleted reactions can be restored, from SKELLY_TRASH_RETENTION
+func trashRetention() time.Duration {
+
+	d, err := parseCooldown(os.Getenv("SKELLY_TRASH_RETENTION"))
+	if err != nil || d <= 0 {
+		return defaultTrashRetention
+	}
+
+	return d
+}
+
+// trashReactions takes a channel and moves its reaction with id, or every reaction when id is empty, into the trash
+// source records where the delete was made, trash older than the retention window is pruned along the way
+func trashReactions(ctx context.Context, team, channel, id, user, source string) (*types.Trash, error) {
+
+	// move the reactions into the trash
+	trash, err := db.TrashReactions(ctx, team, channel, id, user)
+	if err != nil {
+		err = errors.Wrap(err, "could not trash reactions in db")
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Infof("trashed (%v) reactions for channel(%s) trash(%s)", len(trash.Reactions), channel, trash.ID.Hex())
+
+	for i := range trash.Reactions {
+		r := &trash.Reactions[i]
+		recordAudit(ctx, team, channel, r.ID.Hex(), user, auditDelete, source, auditSnapshot(r), nil)
+	}
+
+	// remove trash that can no longer be restored
+	n, err := db.PruneTrash(ctx, time.Now().Add(-trashRetention()).Unix())
+	if err != nil {
+		err = errors.Wrap(err, "could not prune trash in db")
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debugf("pruned (%v) expired trash", n)
+
+	return trash, nil
+}
+
+// restoreReactions takes a trash entry and restores its reactions
+// actor and source record who restored them, and where
+// returns a message meant for showing to the user if the trash cannot be restored
+func restoreReactions(ctx context.Context, trash *types.Trash, actor, source string) (string, error) {
+
+	// the trash is kept until it is pruned, but only restored within the window
+	if time.Since(time.Unix(trash.Deleted, 0)) > trashRetention() {
+		return fmt.Sprintf("Sorry, the reaction for <#%s> was deleted too long ago to restore.", trash.Channel), nil
+	}
+
+	// move the reactions back
+	restored, err := db.RestoreTrash(ctx, trash)
+
+	// do not replace a reaction added since the delete
+	if errors.Cause(err) == db.ErrReactionExists {
+		return fmt.Sprintf("Sorry, a reaction for the same trigger was added to <#%s> since it was deleted. Delete it first to restore.", trash.Channel), nil
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, "could not restore trash in db")
+		return "", err
+	}
+
+	if !restored {
+		return fmt.Sprintf("Sorry, the reaction for <#%s> was already restored.", trash.Channel), nil
+	}
+
+	logging.FromContext(ctx).Infof("restored (%v) reactions for channel(%s) trash(%s)", len(trash.Reactions), trash.Channel, trash.ID.Hex())
+
+	for i := range trash.Reactions {
+		r := &trash.Reactions[i]
+		recordAudit(ctx, trash.Team, trash.Channel, r.ID.Hex(), actor, auditRestore, source, nil, auditSnapshot(r))
+	}
+
+	return "", nil
+}
+
+// postUndo takes a trash entry and posts an ephemeral message to the user
+// in the trashed channel with a button for restoring its reactions
+func postUndo(ctx context.Context, bToken string, trash *types.Trash, user string) error {
+
+	t := slack.NewTextBlockObject("mrkdwn",
+		fmt.Sprintf("I've deleted the reaction for <#%s>. You can undo this for %s.", trash.Channel, formatCooldown(trashRetention())),
+		false, false)
+
+	undo := slack.NewButtonBlockElement(undoDeleteAction, trash.ID.Hex(), slack.NewTextBlockObject("plain_text", "Undo", false, false))
+
+	// create an api client
+	api := slackapi.For(bToken)
+
+	// post the confirmation with the undo button
+	_, err := api.PostEphemeral(ctx, trash.Channel, user, slack.MsgOptionBlocks(
+		slack.NewSectionBlock(t, nil, nil),
+		slack.NewActionBlock("undo_"+trash.ID.Hex(), undo),
+	))
+	if err != nil {
+		err = errors.Wrap(err, "could not post undo")
+		return err
+	}
+
+	return nil
+}
+
+// handleUndoDelete takes a click on the undo button and restores the trashed reactions
+func handleUndoDelete(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error {
+
+	team := callback.Team.ID
+	user := callback.User.ID
+	origin := callback.Channel.ID
+
+	logging.FromContext(ctx).Debugf("handling undo for trash(%s)", action.Value)
+
+	// retrieve the trashed reactions
+	trash, err := db.GetTrash(ctx, action.Value)
+	if err != nil {
+		err = errors.Wrap(err, "could not get trash")
+		return err
+	}
+
+	text := ""
+
+	switch {
+	case trash == nil:
+		text = "Sorry, this reaction was already restored or can no longer be restored."
+
+	case trash.Team != team:
+		return fmt.Errorf("trash(%s) does not belong to team(%s)", action.Value, team)
+
+	default:
+
+		// restoring adds reactions to the channel
+		allowed, err := checkPermission(ctx, team, trash.Channel, origin, user, addSubCommand)
+		if err != nil {
+			err = errors.Wrap(err, "could not check permission")
+			return err
+		}
+
+		if !allowed {
+			return nil
+		}
+
+		text, err = restoreReactions(ctx, trash, user, sourceUndo)
+		if err != nil {
+			err = errors.Wrap(err, "could not restore reactions")
+			return err
+		}
+
+		if len(text) == 0 {
+			text = fmt.Sprintf("Okay, I've restored the reaction for <#%s>.", trash.Channel)
+		}
+	}
+
+	// replace the undo message
+	err = util.Respond(ctx, callback.ResponseURL, slack.Msg{
+		ResponseType:    slack.ResponseTypeEphemeral,
+		ReplaceOriginal: true,
+		Text:            text,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "could not respond")
+		return err
+	}
+
+	return nil
+}
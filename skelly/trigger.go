@@ -0,0 +1,187 @@
+package skelly
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// triggerMessage responds to a message posted in the channel, the default
+	triggerMessage = "message"
+	// triggerTyping is kept for the original typing reactions, slack does not send
+	// typing indicators to apps using the events api so it responds to messages as well
+	triggerTyping = "typing"
+	// triggerJoin responds to a member joining the channel
+	triggerJoin = "join"
+	// triggerMention responds to a message mentioning skelly
+	triggerMention = "mention"
+	// triggerEmoji responds to the reaction's emoji being added to a message
+	triggerEmoji = "emoji"
+)
+
+// triggers are the supported reaction triggers
+var triggers = []string{triggerMessage, triggerTyping, triggerJoin, triggerMention, triggerEmoji}
+
+// TriggerOptions are the raw values for what a reaction responds to and where
+// ex: --trigger join --dm or --trigger emoji --emoji ticket
+type TriggerOptions struct {
+	// Trigger is what the reaction responds to, ex: join
+	Trigger string
+	// Emoji is the emoji for the emoji trigger, ex: ticket or :ticket:
+	Emoji string
+	// DirectMessage responds to the user in a direct message
+	DirectMessage bool
+	// ChannelMessage responds in the channel again, undoing DirectMessage
+	ChannelMessage bool
+}
+
+// Empty returns true if no trigger options were provided
+func (o *TriggerOptions) Empty() bool {
+	return *o == TriggerOptions{}
+}
+
+// applyTrigger takes a reaction and applies the trigger options to it
+// returns an error meant for showing to the user if the options cannot be used
+func applyTrigger(r *types.Reaction, o *TriggerOptions) error {
+
+	if o.DirectMessage && o.ChannelMessage {
+		return errors.New("--dm and --no-dm cannot be used together")
+	}
+
+	// validate the trigger
+	if len(o.Trigger) > 0 {
+
+		trigger := strings.ToLower(o.Trigger)
+		if !contains(triggers, trigger) {
+			return fmt.Errorf("unknown trigger %s, options: %s", o.Trigger, strings.Join(triggers, ", "))
+		}
+
+		// messages are the default
+		if trigger == triggerMessage {
+			trigger = ""
+		}
+
+		r.Trigger = trigger
+
+		// only emoji reactions have an emoji
+		if trigger != triggerEmoji {
+			r.Emoji = ""
+		}
+	}
+
+	// an emoji implies the emoji trigger
+	if len(o.Emoji) > 0 {
+
+		emoji := strings.ToLower(strings.Trim(strings.TrimSpace(o.Emoji), ":"))
+		if len(emoji) == 0 || strings.ContainsAny(emoji, ": ") {
+			return fmt.Errorf("invalid emoji %s, ex: ticket or :ticket:", o.Emoji)
+		}
+
+		r.Trigger = triggerEmoji
+		r.Emoji = emoji
+	}
+
+	if r.Trigger == triggerEmoji && len(r.Emoji) == 0 {
+		return errors.New("the emoji trigger requires an emoji, ex: --emoji ticket")
+	}
+
+	if o.DirectMessage {
+		r.DirectMessage = true
+	}
+
+	if o.ChannelMessage {
+		r.DirectMessage = false
+	}
+
+	return nil
+}
+
+// reactionTrigger takes a reaction and returns what it responds to
+// typing reactions respond to messages, the same as message reactions
+// and emoji reactions respond to their emoji, ex: emoji:ticket
+func reactionTrigger(r *types.Reaction) string {
+
+	switch r.Trigger {
+	case "", triggerTyping:
+		return triggerMessage
+	case triggerEmoji:
+		return emojiTrigger(r.Emoji)
+	}
+
+	return r.Trigger
+}
+
+// emojiTrigger takes an emoji and returns the trigger for it being added to a message
+func emojiTrigger(emoji string) string {
+	return triggerEmoji + ":" + emoji
+}
+
+// isEmojiTrigger returns true if trigger is an emoji being added to a message
+func isEmojiTrigger(trigger string) bool {
+	return strings.HasPrefix(trigger, triggerEmoji+":")
+}
+
+// describeTrigger takes a reaction and describes what it responds to and where
+// ex: members joining, in a direct message
+func describeTrigger(r *types.Reaction) string {
+
+	description := describeEvent(r)
+
+	// faq reactions only answer messages like their questions
+	if r.Kind == kindFAQ {
+		description += " like " + describeQuestions(r)
+	}
+
+	if r.DirectMessage {
+		description += ", in a direct message"
+	}
+
+	return description
+}
+
+// describeEvent takes a reaction and describes the event it responds to
+// ex: members joining
+func describeEvent(r *types.Reaction) string {
+
+	// working hours reactions answer messages that arrive outside of them
+	if r.Kind == kindHours {
+		return fmt.Sprintf("messages outside of working hours (%s)", describeSchedule(r.Schedule))
+	}
+
+	switch reactionTrigger(r) {
+	case triggerJoin:
+		return "members joining"
+	case triggerMention:
+		return "mentions of skelly"
+	case emojiTrigger(r.Emoji):
+		return fmt.Sprintf(":%s: added to a message", r.Emoji)
+	}
+
+	return "messages"
+}
+
+// describeAction takes a reaction and describes what users do to trigger it in a channel
+// ex: join
+func describeAction(r *types.Reaction) string {
+
+	switch r.Kind {
+	case kindFAQ:
+		return "ask one of its questions in"
+	case kindHours:
+		return "type outside of working hours in"
+	}
+
+	switch reactionTrigger(r) {
+	case triggerJoin:
+		return "join"
+	case triggerMention:
+		return "mention skelly in"
+	case emojiTrigger(r.Emoji):
+		return fmt.Sprintf("add :%s: to a message in", r.Emoji)
+	}
+
+	return "type in"
+}
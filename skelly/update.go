@@ -1,64 +1,113 @@
 package skelly
 
 import (
-	"os"
-	"strings"
+	"context"
+	"fmt"
 
 	"github.com/davidvader/skelly/db"
-	"github.com/davidvader/skelly/util"
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 )
 
 // openUpdateModal takes slash command configuration and responds
 // to the triggering user with a dialog window for updating an existing
 // reaction in the skelly database
-func openUpdateModal(s *slack.SlashCommand, command string, args []string) error {
+// ex: /skelly update --id 5f1d7a3c9e1b2a0001a1b2c3
+func openUpdateModal(ctx context.Context, s *slack.SlashCommand, command string, args []string) error {
 
-	channel := s.ChannelID
-	user := s.UserID
-	triggerID := s.TriggerID
+	// parse the reaction to update, if picked
+	id, err := parseIDArg(args[1:])
+	if err != nil {
+
+		text := fmt.Sprintf("Sorry, %s.", err)
+
+		// retrieve the bot token for the workspace
+		bToken, err := botToken(ctx, s.TeamID)
+		if err != nil {
+			err = errors.Wrap(err, "could not get bot token")
+			return err
+		}
+
+		// notify user
+		return notifyUser(ctx, bToken, text, s.ChannelID, s.UserID)
+	}
 
-	// attempt to retrieve an existing reaction
-	exists, reaction, err := db.ReactionExists(channel)
+	return showUpdateModal(ctx, s.TeamID, s.ChannelID, id, s.ChannelID, s.UserID, s.TriggerID)
+}
+
+// showUpdateModal opens the modal for updating the reaction with id in channel, selected by default
+// the channel's only reaction is updated when id is empty
+// errors are sent to the user in origin, the channel or direct message the modal was opened from
+func showUpdateModal(ctx context.Context, team, channel, id, origin, user, triggerID string) error {
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
 	if err != nil {
-		err = errors.Wrap(err, "could not check for reaction")
+		err = errors.Wrap(err, "could not get bot token")
 		return err
 	}
 
-	// if reaction does not exist
-	if !exists {
+	// attempt to retrieve an existing reaction, unless opened from a direct message
+	response, locales, reactionID := "", "", ""
+	if !isDirectMessage(channel) {
 
-		logrus.Infof("reaction does not exist for channel(%s)", channel)
+		reaction, err := findReaction(ctx, team, channel, id)
+
+		// the user needs to pick one of the channel's reactions
+		if several, ok := err.(*severalReactionsError); ok {
+
+			logging.FromContext(ctx).Infof("several reactions exist for channel(%s)", channel)
+
+			// notify user
+			return notifyUser(ctx, bToken, several.Message("/skelly "+updateSubCommand), origin, user)
+		}
 
-		// notify user
-		err = util.SendError("Sorry, that reaction does not exist for this channel. Did you mean to add?", channel, user)
 		if err != nil {
-			err = errors.Wrap(err, "could not send error")
+			err = errors.Wrap(err, "could not find reaction")
 			return err
 		}
 
-		return nil
+		// if reaction does not exist
+		if reaction == nil {
+
+			logging.FromContext(ctx).Infof("reaction(%s) does not exist for channel(%s)", id, channel)
+
+			// notify user
+			return notifyUser(ctx, bToken, missingReaction(channel, id), origin, user)
+		}
+
+		response = reaction.Response
+		locales = formatLocales(reaction.Locales)
+		reactionID = reaction.ID.Hex()
 	}
 
 	// build default modal
-	// uses channel and slash command as metadata
-	metadata := strings.Join([]string{updateSubCommand, channel}, " ")
+	// uses signed subcommand, team, channel and user as metadata
+	metadata, err := encodeViewMetadata(&viewMetadata{
+		SubCommand: updateSubCommand,
+		Team:       team,
+		Channel:    channel,
+		Reaction:   reactionID,
+		User:       user,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "could not encode metadata")
+		return err
+	}
 
 	modal := modal(updateSubCommand,
-		"Update a reaction in this channel.",
-		metadata, reaction.Response)
+		"Update a reaction in a channel.",
+		metadata, channel, response, locales)
 
-	logrus.Infof("opening update modal for channel(%s) trigger_id(%s)", channel, triggerID)
+	logging.FromContext(ctx).Debugf("opening update modal for channel(%s) trigger_id(%s)", channel, triggerID)
 
 	// create an api client
-	bToken := os.Getenv("SKELLY_BOT_TOKEN")
-
-	api := slack.New(bToken)
+	api := slackapi.For(bToken)
 
 	// open modal view
-	_, err = api.OpenView(triggerID, modal)
+	_, err = api.OpenView(ctx, triggerID, modal)
 	if err != nil {
 		err = errors.Wrap(err, "could not open view")
 		return err
@@ -67,73 +116,334 @@ func openUpdateModal(s *slack.SlashCommand, command string, args []string) error
 }
 
 // handleUpdateSubmission takes slack view, extracts args, and attempts to update a reaction in the database
-func handleUpdateSubmission(view *slack.View, user, responseURL string) error {
+// invalid input is returned as errors to show in the modal, otherwise a confirmation replaces the modal
+func handleUpdateSubmission(ctx context.Context, view *slack.View, team, user string) (*slack.ViewSubmissionResponse, error) {
 
 	// parse submission value
 	response, err := parseViewResponse(view)
 	if err != nil {
 		err = errors.Wrap(err, "could not parse response")
-		return err
+		return nil, err
 	}
 
-	// parse out args from private metadata
-	// ex: META:CHANNEL_ID
-	channel, err := parseViewMetadata(view)
+	// parse and verify the private metadata
+	metadata, err := parseViewMetadata(view, team, user)
 	if err != nil {
 		err = errors.Wrap(err, "could not parse metadata")
+		return nil, err
+	}
+
+	// parse the selected channel, defaulting to the channel the modal was opened for
+	channel, err := parseViewChannel(view, metadata.Channel)
+	if err != nil {
+		err = errors.Wrap(err, "could not parse channel")
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Debugf("parsed metadata channel(%s) selected channel(%s)", metadata.Channel, channel)
+
+	// validate input, keeping the modal open with the errors
+	invalid := map[string]string{}
+
+	// reactions belong to channels, not direct messages
+	if isDirectMessage(channel) {
+		invalid["Channel"] = "Reactions can only be managed for channels. Please select a channel."
+	}
+
+	err = validateResponse(response)
+	if err != nil {
+		invalid["Response"] = err.Error()
+	}
+
+	locales, err := parseViewLocales(view)
+	if err != nil {
+		invalid["Locales"] = err.Error()
+	}
+
+	if len(invalid) > 0 {
+		logging.FromContext(ctx).Debugf("invalid update submission for channel(%s): %v", channel, invalid)
+		return slack.NewErrorsViewSubmissionResponse(invalid), nil
+	}
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
+		return nil, err
+	}
+
+	// check that the user may still manage reactions in the channel
+	allowed, err := authorize(ctx, bToken, team, channel, user, updateSubCommand)
+	if err != nil {
+		err = errors.Wrap(err, "could not authorize user")
+		return nil, err
+	}
+
+	if !allowed {
+
+		logging.FromContext(ctx).Infof("user(%s) is not allowed to update reactions in channel(%s)", user, channel)
+
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			"Channel": "You are not allowed to update reactions in this channel. Ask a workspace admin or a skelly admin for help.",
+		}), nil
+	}
+
+	// update the reaction shown in the modal, unless another channel was selected
+	id := ""
+	if channel == metadata.Channel {
+		id = metadata.Reaction
+	}
+
+	// retrieve the reaction from the database
+	reaction, err := findReaction(ctx, team, channel, id)
+
+	// the user needs to pick one of the channel's reactions
+	if _, ok := err.(*severalReactionsError); ok {
+
+		logging.FromContext(ctx).Infof("several reactions exist for channel(%s)", channel)
+
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			"Channel": "This channel has several reactions. Use `/skelly update --id <id>` in it to pick one.",
+		}), nil
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, "could not find reaction in db")
+		return nil, err
+	}
+
+	if reaction == nil {
+
+		logging.FromContext(ctx).Infof("reaction(%s) does not exist for channel(%s)", id, channel)
+
+		// the reaction shown in the modal was deleted after it was opened
+		if len(id) > 0 {
+			return slack.NewErrorsViewSubmissionResponse(map[string]string{
+				"Channel": "This reaction was deleted since the modal was opened. Please close it and try again.",
+			}), nil
+		}
+
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			"Channel": "A reaction does not exist for this channel. Did you mean to add?",
+		}), nil
+	}
+
+	// check that the user may change the reaction
+	allowed, err = canChange(ctx, bToken, user, reaction)
+	if err != nil {
+		err = errors.Wrap(err, "could not authorize user")
+		return nil, err
+	}
+
+	if !allowed {
+
+		logging.FromContext(ctx).Infof("user(%s) is not allowed to update reaction(%s)", user, reaction.ID.Hex())
+
+		return slack.NewErrorsViewSubmissionResponse(map[string]string{
+			"Channel": "Only the creator of this reaction or an admin may update it.",
+		}), nil
+	}
+
+	before := auditSnapshot(reaction)
+
+	// only the response and localized responses are managed by the modal
+	reaction.Response = response
+	reaction.Locales = locales
+
+	// update reaction in the database
+	err = db.UpdateReaction(ctx, reaction)
+	if err != nil {
+		err = errors.Wrap(err, "could not update reaction in db")
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Infof("reaction updated for channel(%s)", channel)
+
+	recordAudit(ctx, team, channel, reaction.ID.Hex(), user, auditUpdate, sourceModal, before, auditSnapshot(reaction))
+
+	// show the confirmation
+	return slack.NewUpdateViewSubmissionResponse(confirmationModal(updateSubCommand,
+		fmt.Sprintf("I've updated the reaction! I will respond to %s.", describeReaction(reaction)))), nil
+}
+
+// updateInline takes slash command configuration and arguments and updates
+// an existing reaction in the skelly database without opening a modal
+// only the provided fields are changed, of the reaction picked with --id when the channel has several
+// ex: /skelly update --cooldown 12h
+func updateInline(ctx context.Context, s *slack.SlashCommand, args []string) error {
+
+	team := s.TeamID
+	channel := s.ChannelID
+	user := s.UserID
+
+	// retrieve the bot token for the workspace
+	bToken, err := botToken(ctx, team)
+	if err != nil {
+		err = errors.Wrap(err, "could not get bot token")
 		return err
 	}
 
-	logrus.Infof("parsed metadata channel(%s)", channel)
+	// inline reactions apply to the current channel
+	if isDirectMessage(channel) {
 
-	// check for reaction in the database
-	exists, _, err := db.ReactionExists(channel)
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, run this in the channel the reaction is for, or use `%s %s` to pick a channel.", s.Command, updateSubCommand), channel, user)
+	}
+
+	// parse and validate input
+	parsed, err := parseReactionArgs(args)
 	if err != nil {
-		err = errors.Wrap(err, "could not check for reaction in db")
+
+		logging.FromContext(ctx).Infof("invalid update arguments for channel(%s): %v", channel, err)
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
+	}
+
+	// validate the response, if provided
+	if len(parsed.Response) > 0 {
+
+		err = validateResponse(parsed.Response)
+		if err != nil {
+
+			logging.FromContext(ctx).Infof("invalid response for channel(%s): %v", channel, err)
+
+			// notify user
+			return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, that response cannot be used. %s", err), channel, user)
+		}
+	}
+
+	// retrieve the picked reaction from the database
+	reaction, err := findReaction(ctx, team, channel, parsed.ID)
+
+	// the user needs to pick one of the channel's reactions
+	if several, ok := err.(*severalReactionsError); ok {
+
+		logging.FromContext(ctx).Infof("several reactions exist for channel(%s)", channel)
+
+		// notify user
+		return notifyUser(ctx, bToken, several.Message(s.Command+" "+updateSubCommand), channel, user)
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, "could not find reaction in db")
 		return err
 	}
 
-	if !exists {
+	if reaction == nil {
 
-		logrus.Infof("reaction does not exist for channel(%s)", channel)
+		logging.FromContext(ctx).Infof("reaction(%s) does not exist for channel(%s)", parsed.ID, channel)
 
 		// notify user
-		err = util.SendError("Sorry, that reaction does not exist for this channel. Did you mean to add?", channel, user)
+		return notifyUser(ctx, bToken, missingReaction(channel, parsed.ID), channel, user)
+	}
+
+	// check that the user may change the reaction
+	allowed, err := canChange(ctx, bToken, user, reaction)
+	if err != nil {
+		err = errors.Wrap(err, "could not authorize user")
+		return err
+	}
+
+	if !allowed {
+
+		logging.FromContext(ctx).Infof("user(%s) is not allowed to update reaction(%s)", user, reaction.ID.Hex())
+
+		// notify user
+		return notifyUser(ctx, bToken, "Sorry, only the creator of this reaction or an admin may update it.", channel, user)
+	}
+
+	before := auditSnapshot(reaction)
+
+	// apply the provided fields
+	if len(parsed.Response) > 0 {
+		reaction.Response = parsed.Response
+	}
+
+	if parsed.Cooldown != nil {
+		reaction.Cooldown = *parsed.Cooldown
+	}
+
+	if parsed.Users != nil {
+		reaction.Users = parsed.Users
+	}
+
+	if !parsed.Schedule.Empty() {
+
+		schedule, err := applySchedule(reaction.Schedule, &parsed.Schedule)
 		if err != nil {
-			err = errors.Wrap(err, "could not send error")
-			return err
+
+			logging.FromContext(ctx).Infof("invalid schedule for channel(%s): %v", channel, err)
+
+			// notify user
+			return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
 		}
 
-		return nil
+		reaction.Schedule = schedule
 	}
 
-	// update reaction in the database
-	err = db.UpdateReaction(channel, response)
+	if !parsed.Variants.Empty() {
+
+		err = applyVariants(reaction, &parsed.Variants)
+		if err != nil {
+
+			logging.FromContext(ctx).Infof("invalid variants for channel(%s): %v", channel, err)
+
+			// notify user
+			return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
+		}
+	}
+
+	// set what the reaction responds to, if provided
+	err = applyTrigger(reaction, &parsed.Trigger)
 	if err != nil {
-		err = errors.Wrap(err, "could not update reaction in db")
+
+		logging.FromContext(ctx).Infof("invalid trigger for channel(%s): %v", channel, err)
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
+	}
+
+	// set the questions the reaction answers, if provided
+	err = applyFAQ(reaction, &parsed.FAQ)
+	if err != nil {
+
+		logging.FromContext(ctx).Infof("invalid faq for channel(%s): %v", channel, err)
+
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, %s.", err), channel, user)
+	}
+
+	// do not take the trigger of another reaction in the channel
+	exists, other, err := db.ReactionExists(ctx, team, channel, reaction.Key())
+	if err != nil {
+		err = errors.Wrap(err, "could not check for reaction in db")
 		return err
 	}
 
-	// build response
-	text := slack.NewTextBlockObject("mrkdwn", "I've updated the reaction for this channel!", false, false)
+	if exists && other.ID != reaction.ID {
 
-	section := slack.NewSectionBlock(text, nil, nil)
+		logging.FromContext(ctx).Infof("reaction already exists for channel(%s) trigger(%s)", channel, reaction.Key())
 
-	// create default msg options
-	options := []slack.MsgOption{
-		slack.MsgOptionBlocks(section),
+		// notify user
+		return notifyUser(ctx, bToken, fmt.Sprintf("Sorry, a reaction to %s already exists for this channel.", describeEvent(reaction)), channel, user)
 	}
 
-	// create an api client
-	bToken := os.Getenv("SKELLY_BOT_TOKEN")
+	// update reaction in the database
+	err = db.UpdateReaction(ctx, reaction)
+	if err != nil {
+		err = errors.Wrap(err, "could not update reaction in db")
+		return err
+	}
 
-	api := slack.New(bToken)
+	recordAudit(ctx, team, channel, reaction.ID.Hex(), user, auditUpdate, sourceSlash, before, auditSnapshot(reaction))
 
 	// post the confirmation
-	_, err = api.PostEphemeral(channel, user, options...)
+	err = postConfirmation(ctx, bToken, fmt.Sprintf("I've updated the reaction for this channel! I will respond to %s.", describeReaction(reaction)), channel, user)
 	if err != nil {
-		err = errors.Wrap(err, "could not post response")
+		err = errors.Wrap(err, "could not post confirmation")
 		return err
 	}
+
 	return nil
 }
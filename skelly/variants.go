@@ -0,0 +1,241 @@
+package skelly
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/davidvader/skelly/db"
+	"github.com/davidvader/skelly/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// strategyRandom picks any response with equal chance, the default
+	strategyRandom = "random"
+	// strategyWeighted picks a response with a chance proportional to its weight
+	strategyWeighted = "weighted"
+	// strategyRoundRobin cycles through the responses for everyone in the channel
+	strategyRoundRobin = "round-robin"
+	// strategySequential cycles through the responses separately for each user
+	strategySequential = "sequential"
+)
+
+// strategies are the supported response selection strategies
+var strategies = []string{strategyRandom, strategyWeighted, strategyRoundRobin, strategySequential}
+
+// VariantOptions are the raw values for responding with one of several responses
+// ex: --variant "Hello!" --variant "Hey!" --weights 3,1,1 --strategy weighted
+type VariantOptions struct {
+	// Variants are the responses used in addition to the main response
+	Variants []string
+	// Weights are the comma separated weights of the main response followed by the variants
+	Weights string
+	// Strategy is how a response is selected, ex: round-robin
+	Strategy string
+	// Locales are responses for users with a specific locale, ex: es=¡Hola!
+	Locales []string
+	// Clear removes the existing variants, weights and locales before applying the other values
+	Clear bool
+}
+
+// Empty returns true if no variant options were provided
+func (o *VariantOptions) Empty() bool {
+	return len(o.Variants) == 0 && len(o.Weights) == 0 && len(o.Strategy) == 0 && len(o.Locales) == 0 && !o.Clear
+}
+
+// applyVariants takes a reaction and applies the variant options to it
+// returns an error meant for showing to the user if the options cannot be used
+func applyVariants(r *types.Reaction, o *VariantOptions) error {
+
+	if o.Clear {
+		r.Variants = nil
+		r.Weights = nil
+		r.Strategy = ""
+		r.Locales = nil
+	}
+
+	// add the localized responses
+	err := applyLocales(r, o.Locales)
+	if err != nil {
+		return err
+	}
+
+	// replace the variants
+	if len(o.Variants) > 0 {
+
+		for _, v := range o.Variants {
+
+			err := validateResponse(v)
+			if err != nil {
+				return fmt.Errorf("the variant %q cannot be used. %s", v, err)
+			}
+		}
+
+		r.Variants = o.Variants
+
+		// weights no longer line up with the responses
+		r.Weights = nil
+	}
+
+	// parse the weights
+	if len(o.Weights) > 0 {
+
+		weights := []int{}
+
+		for _, w := range strings.Split(o.Weights, ",") {
+
+			n, err := strconv.Atoi(strings.TrimSpace(w))
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid weights %s, ex: 3,1,1", o.Weights)
+			}
+
+			weights = append(weights, n)
+		}
+
+		r.Weights = weights
+	}
+
+	// validate the strategy
+	if len(o.Strategy) > 0 {
+
+		strategy := strings.ToLower(o.Strategy)
+		if !contains(strategies, strategy) {
+			return fmt.Errorf("unknown strategy %s, options: %s", o.Strategy, strings.Join(strategies, ", "))
+		}
+
+		r.Strategy = strategy
+	}
+
+	// weights require one per response
+	if len(r.Weights) > 0 {
+
+		if len(r.Weights) != len(responses(r)) {
+			return fmt.Errorf("%d weights were given for %d responses, give one weight per response starting with the main response", len(r.Weights), len(responses(r)))
+		}
+
+		total := 0
+		for _, w := range r.Weights {
+			total += w
+		}
+
+		if total == 0 {
+			return errors.New("at least one weight must be greater than 0")
+		}
+
+		// weights imply weighted selection unless another strategy was chosen
+		if len(r.Strategy) == 0 {
+			r.Strategy = strategyWeighted
+		}
+	}
+
+	return nil
+}
+
+// responses takes a reaction and returns its main response followed by its variants
+func responses(r *types.Reaction) []string {
+	return append([]string{r.Response}, r.Variants...)
+}
+
+// selectResponse takes a reaction and picks the response for the user following its strategy
+// rotation state is kept in the db so it survives restarts
+func selectResponse(ctx context.Context, r *types.Reaction, user string) (string, error) {
+
+	all := responses(r)
+
+	// nothing to select from
+	if len(all) == 1 {
+		return r.Response, nil
+	}
+
+	switch r.Strategy {
+	case strategyWeighted:
+
+		// without weights every response has the same chance
+		if len(r.Weights) == len(all) {
+			return all[weightedIndex(r.Weights, rand.Intn)], nil
+		}
+
+	case strategyRoundRobin, strategySequential:
+
+		// sequential rotates separately for each user
+		key := ""
+		if r.Strategy == strategySequential {
+			key = user
+		}
+
+		n, err := db.NextRotation(ctx, r.ID.Hex(), key)
+		if err != nil {
+			err = errors.Wrap(err, "could not rotate responses")
+			return "", err
+		}
+
+		return all[n%len(all)], nil
+	}
+
+	return all[rand.Intn(len(all))], nil
+}
+
+// weightedIndex takes weights and a random source and picks an index with a chance proportional to its weight
+func weightedIndex(weights []int, intn func(int) int) int {
+
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	n := intn(total)
+
+	for i, w := range weights {
+
+		if n < w {
+			return i
+		}
+
+		n -= w
+	}
+
+	return len(weights) - 1
+}
+
+// describeResponses takes a reaction and describes every response it may respond with
+func describeResponses(r *types.Reaction) string {
+
+	if len(r.Variants) == 0 && len(r.Locales) == 0 {
+		return r.Response
+	}
+
+	lines := []string{}
+
+	if len(r.Variants) == 0 {
+		lines = append(lines, "• "+r.Response)
+	} else {
+
+		strategy := r.Strategy
+		if len(strategy) == 0 {
+			strategy = strategyRandom
+		}
+
+		lines = append(lines, fmt.Sprintf("_%s_", strategy))
+
+		for i, response := range responses(r) {
+
+			line := "• " + response
+
+			if len(r.Weights) > i {
+				line += fmt.Sprintf(" _(weight %d)_", r.Weights[i])
+			}
+
+			lines = append(lines, line)
+		}
+	}
+
+	// localized responses
+	for _, code := range sortedLocales(r.Locales) {
+		lines = append(lines, fmt.Sprintf("• _%s_: %s", code, r.Locales[code]))
+	}
+
+	return strings.Join(lines, "\n")
+}
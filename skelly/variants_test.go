@@ -0,0 +1,118 @@
+package skelly
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davidvader/skelly/types"
+)
+
+func TestWeightedIndex(t *testing.T) {
+
+	tests := []struct {
+		weights []int
+		n       int
+		want    int
+	}{
+		{weights: []int{1}, n: 0, want: 0},
+		{weights: []int{3, 1, 1}, n: 0, want: 0},
+		{weights: []int{3, 1, 1}, n: 2, want: 0},
+		{weights: []int{3, 1, 1}, n: 3, want: 1},
+		{weights: []int{3, 1, 1}, n: 4, want: 2},
+		{weights: []int{0, 2, 0}, n: 0, want: 1},
+		{weights: []int{0, 2, 0}, n: 1, want: 1},
+		{weights: []int{1, 0, 1}, n: 1, want: 2},
+	}
+
+	for _, test := range tests {
+
+		total := 0
+
+		got := weightedIndex(test.weights, func(n int) int {
+			total = n
+			return test.n
+		})
+
+		if got != test.want {
+			t.Errorf("weightedIndex(%v) with %d = %d, want %d", test.weights, test.n, got, test.want)
+		}
+
+		if total != totalWeight(test.weights) {
+			t.Errorf("weightedIndex(%v) drew from %d, want the total weight %d", test.weights, total, totalWeight(test.weights))
+		}
+	}
+}
+
+func TestWeightedIndexDistribution(t *testing.T) {
+
+	weights := []int{3, 1, 0, 2}
+	counts := make([]int, len(weights))
+
+	// every draw of the random source lands on exactly its weight's share
+	for n := 0; n < totalWeight(weights); n++ {
+		counts[weightedIndex(weights, func(int) int { return n })]++
+	}
+
+	for i, w := range weights {
+		if counts[i] != w {
+			t.Errorf("weightedIndex picked index %d %d times, want %d", i, counts[i], w)
+		}
+	}
+}
+
+// selectResponse is only tested for the strategies that do not rotate, rotating requires the db
+func TestSelectResponse(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		reaction *types.Reaction
+		want     []string
+	}{
+		{
+			name:     "single response",
+			reaction: &types.Reaction{Response: "Hello!", Strategy: strategyRoundRobin},
+			want:     []string{"Hello!"},
+		},
+		{
+			name:     "random",
+			reaction: &types.Reaction{Response: "Hello!", Variants: []string{"Hey!", "Hi!"}},
+			want:     []string{"Hello!", "Hey!", "Hi!"},
+		},
+		{
+			name:     "weighted",
+			reaction: &types.Reaction{Response: "Hello!", Variants: []string{"Hey!", "Hi!"}, Strategy: strategyWeighted, Weights: []int{0, 1, 0}},
+			want:     []string{"Hey!"},
+		},
+		{
+			name:     "weighted without weights is random",
+			reaction: &types.Reaction{Response: "Hello!", Variants: []string{"Hey!"}, Strategy: strategyWeighted},
+			want:     []string{"Hello!", "Hey!"},
+		},
+	}
+
+	for _, test := range tests {
+
+		for i := 0; i < 20; i++ {
+
+			got, err := selectResponse(context.Background(), test.reaction, "U123")
+			if err != nil {
+				t.Fatalf("%s: selectResponse returned error: %v", test.name, err)
+			}
+
+			if !contains(test.want, got) {
+				t.Errorf("%s: selectResponse = %q, want one of %q", test.name, got, test.want)
+			}
+		}
+	}
+}
+
+// totalWeight returns the total of the weights
+func totalWeight(weights []int) int {
+
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	return total
+}
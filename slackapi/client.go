@@ -0,0 +1,202 @@
+package slackapi
+
+import (
+	"context"
+	"sync"
+
+	"github.com/davidvader/skelly/tracing"
+	"github.com/slack-go/slack"
+)
+
+// Client is the subset of the slack api used by skelly
+// every call honors rate limits and retries transient failures
+type Client interface {
+	// AuthTest verifies the token used by the client
+	AuthTest(ctx context.Context) (*slack.AuthTestResponse, error)
+	// PostMessage posts a message to a channel, returns the channel and message timestamp
+	PostMessage(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error)
+	// PostEphemeral posts a message to a channel visible only to user, returns the message timestamp
+	PostEphemeral(ctx context.Context, channel, user string, options ...slack.MsgOption) (string, error)
+	// OpenView opens a modal for the interaction that produced triggerID
+	OpenView(ctx context.Context, triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error)
+	// PublishView publishes the home tab view for a user
+	PublishView(ctx context.Context, user string, view slack.HomeTabViewRequest) (*slack.ViewResponse, error)
+	// GetConversationReplies retrieves the messages of a thread
+	GetConversationReplies(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, error)
+	// GetUserInfo retrieves a user's profile and workspace role
+	GetUserInfo(ctx context.Context, user string) (*slack.User, error)
+	// GetUserGroupMembers retrieves the user ids of a user group's members
+	GetUserGroupMembers(ctx context.Context, group string) ([]string, error)
+}
+
+// Factory creates a client for a bot token
+type Factory func(token string) Client
+
+// maxClients is how many clients are cached, the oldest is evicted beyond it
+// so tokens of uninstalled teams do not accumulate
+const maxClients = 500
+
+var (
+	// mu guards factory, clients and tokens
+	mu sync.Mutex
+	// factory creates new clients, replaced by SetFactory
+	factory Factory = func(token string) Client { return New(token) }
+	// clients caches clients by token
+	clients = map[string]Client{}
+	// tokens are the cached tokens, oldest first
+	tokens = []string{}
+)
+
+// For returns the client for a bot token, creating and caching it on first use
+func For(token string) Client {
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	c, ok := clients[token]
+	if !ok {
+		c = factory(token)
+		clients[token] = c
+		tokens = append(tokens, token)
+
+		// evict the oldest client
+		if len(tokens) > maxClients {
+			delete(clients, tokens[0])
+			tokens = tokens[1:]
+		}
+	}
+
+	return c
+}
+
+// SetFactory replaces how clients are created and clears cached clients
+// meant for pointing skelly at a fake slack api
+func SetFactory(f Factory) {
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	factory = f
+	clients = map[string]Client{}
+	tokens = []string{}
+}
+
+// client is the default Client backed by the slack api
+type client struct {
+	api *slack.Client
+}
+
+// New returns a Client backed by the slack api for a bot token
+// calls are traced and options are passed to the underlying slack client
+func New(token string, options ...slack.Option) Client {
+
+	options = append([]slack.Option{slack.OptionHTTPClient(tracing.HTTPClient())}, options...)
+
+	return &client{
+		api: slack.New(token, options...),
+	}
+}
+
+// AuthTest verifies the token used by the client
+func (c *client) AuthTest(ctx context.Context) (*slack.AuthTestResponse, error) {
+
+	var resp *slack.AuthTestResponse
+
+	err := retry(ctx, "auth.test", func() (err error) {
+		resp, err = c.api.AuthTestContext(ctx)
+		return err
+	})
+
+	return resp, err
+}
+
+// PostMessage posts a message to a channel, returns the channel and message timestamp
+func (c *client) PostMessage(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error) {
+
+	var ch, ts string
+
+	err := retry(ctx, "chat.postMessage", func() (err error) {
+		ch, ts, err = c.api.PostMessageContext(ctx, channel, options...)
+		return err
+	})
+
+	return ch, ts, err
+}
+
+// PostEphemeral posts a message to a channel visible only to user, returns the message timestamp
+func (c *client) PostEphemeral(ctx context.Context, channel, user string, options ...slack.MsgOption) (string, error) {
+
+	var ts string
+
+	err := retry(ctx, "chat.postEphemeral", func() (err error) {
+		ts, err = c.api.PostEphemeralContext(ctx, channel, user, options...)
+		return err
+	})
+
+	return ts, err
+}
+
+// OpenView opens a modal for the interaction that produced triggerID
+func (c *client) OpenView(ctx context.Context, triggerID string, view slack.ModalViewRequest) (*slack.ViewResponse, error) {
+
+	var resp *slack.ViewResponse
+
+	err := retry(ctx, "views.open", func() (err error) {
+		resp, err = c.api.OpenViewContext(ctx, triggerID, view)
+		return err
+	})
+
+	return resp, err
+}
+
+// PublishView publishes the home tab view for a user
+func (c *client) PublishView(ctx context.Context, user string, view slack.HomeTabViewRequest) (*slack.ViewResponse, error) {
+
+	var resp *slack.ViewResponse
+
+	err := retry(ctx, "views.publish", func() (err error) {
+		resp, err = c.api.PublishViewContext(ctx, user, view, "")
+		return err
+	})
+
+	return resp, err
+}
+
+// GetConversationReplies retrieves the messages of a thread
+func (c *client) GetConversationReplies(ctx context.Context, params *slack.GetConversationRepliesParameters) ([]slack.Message, error) {
+
+	var msgs []slack.Message
+
+	err := retry(ctx, "conversations.replies", func() (err error) {
+		msgs, _, _, err = c.api.GetConversationRepliesContext(ctx, params)
+		return err
+	})
+
+	return msgs, err
+}
+
+// GetUserInfo retrieves a user's profile and workspace role
+func (c *client) GetUserInfo(ctx context.Context, user string) (*slack.User, error) {
+
+	var u *slack.User
+
+	err := retry(ctx, "users.info", func() (err error) {
+		u, err = c.api.GetUserInfoContext(ctx, user)
+		return err
+	})
+
+	return u, err
+}
+
+// GetUserGroupMembers retrieves the user ids of a user group's members
+func (c *client) GetUserGroupMembers(ctx context.Context, group string) ([]string, error) {
+
+	var members []string
+
+	err := retry(ctx, "usergroups.users.list", func() (err error) {
+		members, err = c.api.GetUserGroupMembersContext(ctx, group)
+		return err
+	})
+
+	return members, err
+}
@@ -0,0 +1,48 @@
+package slackapi
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestForEvictsOldest(t *testing.T) {
+
+	created := map[string]int{}
+
+	SetFactory(func(token string) Client {
+		created[token]++
+		return New(token)
+	})
+	defer SetFactory(func(token string) Client { return New(token) })
+
+	first := For("xoxb-0")
+
+	if For("xoxb-0") != first {
+		t.Errorf("For() returned a new client for a cached token")
+	}
+
+	// fill the cache past its limit, evicting the first token
+	for i := 1; i <= maxClients; i++ {
+		For(fmt.Sprintf("xoxb-%d", i))
+	}
+
+	if len(clients) != maxClients || len(tokens) != maxClients {
+		t.Errorf("cached %d clients and %d tokens, want %d", len(clients), len(tokens), maxClients)
+	}
+
+	if For("xoxb-0") == first || created["xoxb-0"] != 2 {
+		t.Errorf("For() returned the evicted client, created %d times", created["xoxb-0"])
+	}
+
+	// the second token is now the oldest and was evicted in turn
+	if _, ok := clients["xoxb-1"]; ok {
+		t.Errorf("For() kept the oldest client beyond %d clients", maxClients)
+	}
+
+	newest := fmt.Sprintf("xoxb-%d", maxClients)
+	For(newest)
+
+	if created[newest] != 1 {
+		t.Errorf("For() recreated the newest client")
+	}
+}
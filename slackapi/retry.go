@@ -0,0 +1,102 @@
+package slackapi
+
+import (
+	"context"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/davidvader/skelly/logging"
+	"github.com/slack-go/slack"
+)
+
+const (
+	// maxAttempts is the maximum number of times a slack api call is attempted
+	maxAttempts = 4
+	// baseBackoff is the wait before the first retry of a transient failure, doubled for each retry
+	baseBackoff = 500 * time.Millisecond
+	// maxRetryAfter caps how long a rate limited call waits before retrying
+	maxRetryAfter = 30 * time.Second
+)
+
+// idempotent are the slack methods that are safe to retry after a timeout
+// a timed out post may have been accepted by slack, retrying it would post twice
+var idempotent = map[string]bool{
+	"auth.test":             true,
+	"conversations.replies": true,
+	"users.info":            true,
+	"usergroups.users.list": true,
+}
+
+// retryable is implemented by slack api errors that may succeed when retried
+type retryable interface {
+	Retryable() bool
+}
+
+// retry executes call until it succeeds, fails with a permanent error,
+// ctx is done or maxAttempts is reached
+// rate limited calls wait for the duration given by slack in Retry-After,
+// other transient failures back off exponentially with jitter
+func retry(ctx context.Context, method string, call func() error) error {
+
+	var err error
+
+	for attempt := 1; ; attempt++ {
+
+		err = call()
+		if err == nil || attempt == maxAttempts {
+			return err
+		}
+
+		wait, ok := backoff(method, err, attempt)
+		if !ok {
+			return err
+		}
+
+		logging.FromContext(ctx).Debugf("retrying slack method(%s) attempt(%d) in %s: %v", method, attempt, wait, err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff takes an error from a slack api call and returns how long to
+// wait before the next attempt, or false if the call should not be retried
+func backoff(method string, err error, attempt int) (time.Duration, bool) {
+
+	// slack asked us to slow down
+	if rl, ok := err.(*slack.RateLimitedError); ok {
+
+		wait := rl.RetryAfter
+		if wait > maxRetryAfter {
+			wait = maxRetryAfter
+		}
+
+		return wait, true
+	}
+
+	transient := false
+
+	// 5xx responses from slack
+	if r, ok := err.(retryable); ok && r.Retryable() {
+		transient = true
+	}
+
+	// timeouts of calls that are safe to repeat
+	if ne, ok := err.(net.Error); ok && ne.Timeout() && idempotent[method] {
+		transient = true
+	}
+
+	if !transient {
+		return 0, false
+	}
+
+	// exponential backoff with up to 50% jitter
+	wait := baseBackoff << uint(attempt-1)
+	wait += time.Duration(rand.Int63n(int64(wait) / 2))
+
+	return wait, true
+}
@@ -0,0 +1,101 @@
+package slackapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// statusError is a slack api http error, 5xx responses are retryable
+type statusError struct {
+	code int
+}
+
+func (e statusError) Error() string   { return "slack server error" }
+func (e statusError) Retryable() bool { return e.code >= 500 }
+
+// timeoutError is a net.Error for a call that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestBackoff(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		method  string
+		err     error
+		attempt int
+		min     time.Duration
+		max     time.Duration
+		wantOK  bool
+	}{
+		{name: "rate limited", method: "chat.postMessage", err: &slack.RateLimitedError{RetryAfter: 2 * time.Second}, attempt: 1, min: 2 * time.Second, max: 2 * time.Second, wantOK: true},
+		{name: "rate limited without retry-after", method: "chat.postMessage", err: &slack.RateLimitedError{}, attempt: 1, wantOK: true},
+		{name: "rate limited too long", method: "chat.postMessage", err: &slack.RateLimitedError{RetryAfter: 5 * time.Minute}, attempt: 1, min: maxRetryAfter, max: maxRetryAfter, wantOK: true},
+		{name: "5xx", method: "chat.postMessage", err: statusError{code: 503}, attempt: 1, min: baseBackoff, max: baseBackoff * 3 / 2, wantOK: true},
+		{name: "5xx third attempt", method: "chat.postMessage", err: statusError{code: 500}, attempt: 3, min: 4 * baseBackoff, max: 6 * baseBackoff, wantOK: true},
+		{name: "4xx", method: "chat.postMessage", err: statusError{code: 404}, attempt: 1},
+		{name: "timeout idempotent", method: "users.info", err: timeoutError{}, attempt: 1, min: baseBackoff, max: baseBackoff * 3 / 2, wantOK: true},
+		{name: "timeout not idempotent", method: "chat.postMessage", err: timeoutError{}, attempt: 1},
+		{name: "permanent", method: "users.info", err: errors.New("user_not_found"), attempt: 1},
+	}
+
+	for _, test := range tests {
+
+		wait, ok := backoff(test.method, test.err, test.attempt)
+
+		if ok != test.wantOK {
+			t.Errorf("%s: backoff() ok = %t, want %t", test.name, ok, test.wantOK)
+			continue
+		}
+
+		if wait < test.min || wait > test.max {
+			t.Errorf("%s: backoff() wait = %s, want between %s and %s", test.name, wait, test.min, test.max)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		errs         []error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "succeeds", ctx: context.Background(), errs: []error{nil}, wantAttempts: 1},
+		{name: "succeeds after rate limit", ctx: context.Background(), errs: []error{&slack.RateLimitedError{}, nil}, wantAttempts: 2},
+		{name: "permanent", ctx: context.Background(), errs: []error{errors.New("channel_not_found")}, wantAttempts: 1, wantErr: true},
+		{name: "max attempts", ctx: context.Background(), errs: []error{&slack.RateLimitedError{}, &slack.RateLimitedError{}, &slack.RateLimitedError{}, &slack.RateLimitedError{}, nil}, wantAttempts: maxAttempts, wantErr: true},
+		{name: "canceled", ctx: canceled, errs: []error{statusError{code: 503}, nil}, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, test := range tests {
+
+		attempts := 0
+
+		err := retry(test.ctx, "chat.postMessage", func() error {
+			err := test.errs[attempts]
+			attempts++
+			return err
+		})
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: retry() err = %v, wantErr %t", test.name, err, test.wantErr)
+		}
+
+		if attempts != test.wantAttempts {
+			t.Errorf("%s: retry() attempts = %d, want %d", test.name, attempts, test.wantAttempts)
+		}
+	}
+}
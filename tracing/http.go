@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// transport is an http.RoundTripper that creates a client span for every outgoing request
+type transport struct {
+	base http.RoundTripper
+}
+
+// HTTPClient returns an http client that traces outgoing requests,
+// meant for use with slack.OptionHTTPClient so each slack api
+// call is recorded as a child span named after the api method
+func HTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &transport{base: http.DefaultTransport},
+	}
+}
+
+// RoundTrip executes a single http request inside a client span
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+
+	// name the span after the slack api method, ex: slack chat.postMessage
+	ctx, span := otel.Tracer(tracerName).Start(r.Context(),
+		fmt.Sprintf("slack %s", path.Base(r.URL.Path)),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("server.address", r.URL.Host),
+		))
+	defer span.End()
+
+	// propagate the trace to the receiving server
+	r = r.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(r.Header))
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		return nil, Error(span, err)
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// Middleware returns a gin middleware that creates a server span for every incoming request
+// and continues any trace propagated by the caller
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		// continue an incoming trace, if one exists
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(),
+			propagation.HeaderCarrier(c.Request.Header))
+
+		// name the span after the matched route, ex: POST /commands
+		ctx, span := otel.Tracer(tracerName).Start(ctx,
+			fmt.Sprintf("%s %s", c.Request.Method, c.FullPath()),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("url.path", c.Request.URL.Path),
+			))
+		defer span.End()
+
+		// pass the span to the handlers
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
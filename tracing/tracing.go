@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name used for all skelly spans
+const tracerName = "github.com/davidvader/skelly"
+
+// Setup uses environment to configure the global tracer provider
+// spans are exported with OTLP over http when OTEL_EXPORTER_OTLP_ENDPOINT
+// or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set, otherwise tracing is a no-op
+// returns a function for flushing and stopping the tracer provider
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+
+	// propagate w3c trace context and baggage headers
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	// retrieve the exporter endpoint from the environment
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if len(endpoint) == 0 {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+
+	// leave the default no-op tracer provider in place
+	if len(endpoint) == 0 {
+		logrus.Debug("no otlp endpoint configured, tracing disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	logrus.Infof("exporting traces to otlp endpoint(%s)", endpoint)
+
+	// the exporter reads the remaining OTEL_EXPORTER_OTLP_* variables
+	// such as headers, timeout and insecure from the environment
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create otlp exporter")
+	}
+
+	// describe this service, OTEL_SERVICE_NAME and
+	// OTEL_RESOURCE_ATTRIBUTES take precedence
+	res, err := resource.Merge(
+		resource.NewSchemaless(semconv.ServiceName("skelly")),
+		resource.Environment(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create tracing resource")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start creates a span with the given name and attributes as a child of any span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Error records err on the span and marks the span as failed
+// returns err to allow wrapping return statements
+func Error(span trace.Span, err error) error {
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// Detach returns a context that keeps the values of ctx, including the
+// current span, but is not cancelled when ctx is
+// meant for work that continues after an http request is acknowledged
+func Detach(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
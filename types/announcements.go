@@ -0,0 +1,16 @@
+package types
+
+import "gopkg.in/mgo.v2/bson"
+
+// Announcement is the struct representation for a message skelly posts to a channel on a schedule
+type Announcement struct {
+	ID        bson.ObjectId `json:"id" bson:"_id,omitempty" yaml:"-"`
+	Team      string        `json:"team"`
+	Channel   string        `json:"channel"`
+	CreatedBy string        `json:"user"`
+	Response  string        `json:"response"`
+	Cron      string        `json:"cron"`
+	TimeZone  string        `json:"timezone,omitempty" bson:"timezone,omitempty" yaml:"timezone,omitempty"`
+	NextRun   int64         `json:"next_run" yaml:"next_run"`
+	LastRun   int64         `json:"last_run" yaml:"last_run"`
+}
@@ -0,0 +1,29 @@
+package types
+
+import "gopkg.in/mgo.v2/bson"
+
+// Audit is the struct representation for a change made to a channel's reaction
+// audit events are only ever added, never updated or removed
+type Audit struct {
+	ID       bson.ObjectId `json:"id" bson:"_id,omitempty" yaml:"-"`
+	Team     string        `json:"team"`
+	Channel  string        `json:"channel"`
+	Reaction string        `json:"reaction"`
+	// Actor is who made the change, a slack user id or the local user for the cli
+	Actor string `json:"actor"`
+	// Action is what was done to the reaction, ex: add, update or delete
+	Action string `json:"action"`
+	// Source is where the change was made, ex: slash, modal or cli
+	Source string `json:"source"`
+	// Changes are the fields of the reaction that changed
+	Changes []AuditChange `json:"changes,omitempty" bson:",omitempty" yaml:",omitempty"`
+	Created int64         `json:"created"`
+}
+
+// AuditChange is the struct representation for a field of a reaction that changed
+// Before is empty for added fields and After is empty for removed fields
+type AuditChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before,omitempty" bson:",omitempty" yaml:",omitempty"`
+	After  string `json:"after,omitempty" bson:",omitempty" yaml:",omitempty"`
+}
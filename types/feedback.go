@@ -0,0 +1,21 @@
+package types
+
+import "gopkg.in/mgo.v2/bson"
+
+// Feedback is the struct representation for an faq answer and whether it answered the question
+// Helpful is nil until the user that asked rates the answer
+type Feedback struct {
+	ID       bson.ObjectId `json:"id" bson:"_id,omitempty" yaml:"-"`
+	Team     string        `json:"team"`
+	Channel  string        `json:"channel"`
+	Reaction string        `json:"reaction"`
+	User     string        `json:"user"`
+	// Question is the message that was answered
+	Question string `json:"question"`
+	// Score is how similar the message was to the closest question, from 0 to 1
+	Score     float64 `json:"score"`
+	Timestamp string  `json:"timestamp"`
+	Helpful   *bool   `json:"helpful,omitempty" bson:",omitempty" yaml:",omitempty"`
+	Created   int64   `json:"created"`
+	Rated     int64   `json:"rated,omitempty" bson:",omitempty" yaml:",omitempty"`
+}
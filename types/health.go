@@ -0,0 +1,15 @@
+package types
+
+// Health is the struct representation for the readiness of skelly and its dependencies.
+type Health struct {
+	Status string                  `json:"status"`
+	Checks map[string]*HealthCheck `json:"checks"`
+}
+
+// HealthCheck is the struct representation for the status of a single dependency.
+type HealthCheck struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+	Cached  bool   `json:"cached,omitempty"`
+}
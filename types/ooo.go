@@ -0,0 +1,15 @@
+package types
+
+// OutOfOffice is the struct representation for a user's out of office settings
+// skelly answers messages mentioning the user while they are away
+type OutOfOffice struct {
+	Team string `json:"team"`
+	User string `json:"user"`
+	// Message is the reply to messages mentioning the user, ex: Back Monday
+	Message string `json:"message"`
+	// Away is true while the user turned out of office on
+	Away bool `json:"away"`
+	// Hours are the user's working hours, they are also away outside of them
+	Hours   *Schedule `json:"hours,omitempty" bson:",omitempty" yaml:",omitempty"`
+	Updated int64     `json:"updated"`
+}
@@ -1,10 +1,63 @@
 package types
 
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
 // Reaction is the struct representation for skelly reactions
 type Reaction struct {
-	Channel   string `json:"channel"`
-	CreatedBy string `json:"user"`
-	Response  string `json:"response"`
+	ID        bson.ObjectId     `json:"id,omitempty" bson:"_id,omitempty" yaml:"-"`
+	Team      string            `json:"team"`
+	Channel   string            `json:"channel"`
+	CreatedBy string            `json:"user"`
+	Response  string            `json:"response"`
+	Cooldown  time.Duration     `json:"cooldown"`
+	Users     []string          `json:"users"`
+	Paused    bool              `json:"paused"`
+	Schedule  *Schedule         `json:"schedule,omitempty" bson:",omitempty" yaml:",omitempty"`
+	Variants  []string          `json:"variants,omitempty" bson:",omitempty" yaml:",omitempty"`
+	Weights   []int             `json:"weights,omitempty" bson:",omitempty" yaml:",omitempty"`
+	Strategy  string            `json:"strategy,omitempty" bson:",omitempty" yaml:",omitempty"`
+	Locales   map[string]string `json:"locales,omitempty" bson:",omitempty" yaml:",omitempty"`
+	// Trigger is what the reaction responds to, empty for messages, ex: join
+	Trigger string `json:"trigger,omitempty" bson:",omitempty" yaml:",omitempty"`
+	// Emoji is the emoji that triggers the reaction when added to a message, ex: ticket
+	Emoji string `json:"emoji,omitempty" bson:",omitempty" yaml:",omitempty"`
+	// Kind is the kind of reaction, empty for typing reactions, ex: faq or hours
+	Kind string `json:"kind,omitempty" bson:",omitempty" yaml:",omitempty"`
+	// Questions are the phrasings of the question an faq reaction answers
+	Questions []string `json:"questions,omitempty" bson:",omitempty" yaml:",omitempty"`
+	// Threshold is how similar a message must be to a question to be answered, from 0 to 1
+	Threshold float64 `json:"threshold,omitempty" bson:",omitempty" yaml:",omitempty"`
+	// DirectMessage responds to the user in a direct message instead of the channel
+	DirectMessage bool `json:"direct_message,omitempty" bson:"directmessage,omitempty" yaml:"direct_message,omitempty"`
+}
+
+// Schedule is the struct representation for when a reaction is active
+// empty fields do not limit the reaction
+type Schedule struct {
+	// Start is the first date the reaction is active, ex: 2021-01-04
+	Start string `json:"start,omitempty" yaml:",omitempty"`
+	// End is the last date the reaction is active, ex: 2021-01-08
+	End string `json:"end,omitempty" yaml:",omitempty"`
+	// Weekdays are the days the reaction is active, ex: [mon tue wed]
+	Weekdays []string `json:"weekdays,omitempty" yaml:",omitempty"`
+	// From is the time of day the reaction becomes active, ex: 09:00
+	From string `json:"from,omitempty" yaml:",omitempty"`
+	// To is the time of day the reaction stops being active, ex: 17:00
+	To string `json:"to,omitempty" yaml:",omitempty"`
+	// TimeZone is the location the schedule is in, defaults to UTC, ex: America/Chicago
+	TimeZone string `json:"timezone,omitempty" bson:"timezone,omitempty" yaml:"timezone,omitempty"`
+}
+
+// Rotation is the struct representation for how many times a reaction's responses were rotated
+// User is empty when the reaction rotates for the whole channel
+type Rotation struct {
+	Reaction string `json:"reaction"`
+	User     string `json:"user"`
+	Count    int    `json:"count"`
 }
 
 // Response is the struct represtation for a stored response
@@ -12,4 +65,29 @@ type Response struct {
 	Channel   string `json:"channel"`
 	User      string `json:"user"`
 	Timestamp string `json:"timestamp"`
+	Created   int64  `json:"created"`
+	// Reaction is the id of the reaction that responded, or the key of another responder, ex: ooo:U123
+	Reaction string `json:"reaction,omitempty" bson:",omitempty"`
+}
+
+// Key returns what the reaction is unique by within its channel, a channel has one reaction
+// for each trigger, ex: message or emoji:ticket, and one for its working hours,
+// faq reactions are not unique and return empty
+func (r *Reaction) Key() string {
+
+	switch r.Kind {
+	case "faq":
+		return ""
+	case "hours":
+		return "hours"
+	}
+
+	switch r.Trigger {
+	case "", "typing", "message":
+		return "message"
+	case "emoji":
+		return "emoji:" + r.Emoji
+	}
+
+	return r.Trigger
 }
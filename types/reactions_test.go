@@ -0,0 +1,33 @@
+package types
+
+import "testing"
+
+func TestReactionKey(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		reaction *Reaction
+		want     string
+	}{
+		{name: "default", reaction: &Reaction{}, want: "message"},
+		{name: "typing", reaction: &Reaction{Trigger: "typing"}, want: "message"},
+		{name: "message", reaction: &Reaction{Trigger: "message"}, want: "message"},
+		{name: "join", reaction: &Reaction{Trigger: "join"}, want: "join"},
+		{name: "mention", reaction: &Reaction{Trigger: "mention"}, want: "mention"},
+		{name: "emoji", reaction: &Reaction{Trigger: "emoji", Emoji: "ticket"}, want: "emoji:ticket"},
+		{name: "other emoji", reaction: &Reaction{Trigger: "emoji", Emoji: "eyes"}, want: "emoji:eyes"},
+		{name: "direct message", reaction: &Reaction{DirectMessage: true}, want: "message"},
+		{name: "faq", reaction: &Reaction{Kind: "faq"}, want: ""},
+		{name: "faq for mentions", reaction: &Reaction{Kind: "faq", Trigger: "mention"}, want: ""},
+		{name: "working hours", reaction: &Reaction{Kind: "hours"}, want: "hours"},
+	}
+
+	for _, test := range tests {
+
+		got := test.reaction.Key()
+
+		if got != test.want {
+			t.Errorf("%s: Key() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
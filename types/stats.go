@@ -1,14 +1,52 @@
 package types
 
-import "github.com/slack-go/slack"
+import "time"
 
 // ChannelStats is the struct representation for skelly channel statistics.
 type ChannelStats struct {
 	TotalRules int
+	// Fired is how many times the channel's reactions responded
+	Fired int
+	// Skipped is how many times the channel's reactions did not respond
+	Skipped int
+	// Users is how many unique users the channel's reactions responded to
+	Users int
+	// Reactions are the responses per reaction id
+	Reactions map[string]int
+	// SkipReasons are the skips per reason, ex: cooldown
+	SkipReasons map[string]int
+	// Daily are the responses per day, ex: 2021-01-04
+	Daily map[string]int
 }
 
 // SkellyStats is the struct representation for skelly statistics.
 type SkellyStats struct {
 	TotalChannels int
-	Channels      map[string]*slack.Channel
+	Channels      map[string]*ChannelStats
+	// Fired is how many times reactions responded across channels
+	Fired int
+	// Skipped is how many times reactions did not respond across channels
+	Skipped int
+	// Users is how many unique users reactions responded to across channels
+	Users int
+	// SkipReasons are the skips per reason across channels
+	SkipReasons map[string]int
+	// Daily are the responses per day across channels
+	Daily map[string]int
+}
+
+// Activity is the struct representation for a reaction responding, or not, to a user
+// Reason is empty when the reaction fired, skips are counted per day in Count without a User
+type Activity struct {
+	Team     string `json:"team"`
+	Channel  string `json:"channel"`
+	Reaction string `json:"reaction"`
+	User     string `json:"user"`
+	Trigger  string `json:"trigger"`
+	Fired    bool   `json:"fired"`
+	Reason   string `json:"reason,omitempty" bson:",omitempty" yaml:",omitempty"`
+	Count    int    `json:"count,omitempty" bson:",omitempty" yaml:",omitempty"`
+	Created  int64  `json:"created"`
+	// Expires is when the db removes the activity
+	Expires time.Time `json:"-" yaml:"-"`
 }
@@ -0,0 +1,12 @@
+package types
+
+// Team is the struct representation for a slack workspace that has installed skelly
+type Team struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	BotToken    string `json:"bot_token" yaml:"-"`
+	BotUserID   string `json:"bot_user_id"`
+	Scope       string `json:"scope"`
+	InstalledBy string `json:"installed_by"`
+	InstalledAt int64  `json:"installed_at"`
+}
@@ -0,0 +1,14 @@
+package types
+
+import "gopkg.in/mgo.v2/bson"
+
+// Trash is the struct representation for reactions deleted from a channel
+// kept for a retention window so the delete can be undone
+type Trash struct {
+	ID        bson.ObjectId `json:"id" bson:"_id,omitempty" yaml:"-"`
+	Team      string        `json:"team"`
+	Channel   string        `json:"channel"`
+	DeletedBy string        `json:"deleted_by" bson:"deletedby"`
+	Deleted   int64         `json:"deleted"`
+	Reactions []Reaction    `json:"reactions"`
+}
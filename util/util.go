@@ -2,17 +2,19 @@ package util
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 
+	"github.com/davidvader/skelly/logging"
+	"github.com/davidvader/skelly/slackapi"
+	"github.com/davidvader/skelly/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
 	"github.com/slack-go/slack"
 )
 
@@ -47,9 +49,9 @@ func ParsePayload(b []byte) (string, error) {
 
 // GetThreadTimestamp uses conversations.history to retrieve the timestamp for either the message
 // or the thread parent message, if one exists
-func GetThreadTimestamp(bToken, channel, ts string) (string, error) {
+func GetThreadTimestamp(ctx context.Context, bToken, channel, ts string) (string, error) {
 
-	logrus.Infof("getting parent timestamp for ts(%s)", ts)
+	logging.FromContext(ctx).Debugf("getting parent timestamp for ts(%s)", ts)
 
 	// configure conversation.history fetch parameters
 	params := &slack.GetConversationRepliesParameters{
@@ -58,10 +60,10 @@ func GetThreadTimestamp(bToken, channel, ts string) (string, error) {
 	}
 
 	// create a new slack api client
-	api := slack.New(bToken)
+	api := slackapi.For(bToken)
 
 	// fetch the thread parent, if it exists
-	replies, _, _, err := api.GetConversationReplies(params)
+	replies, err := api.GetConversationReplies(ctx, params)
 	if err != nil {
 		err = errors.Wrap(err, "could not fetch conversation history")
 		return "", err
@@ -77,15 +79,15 @@ func GetThreadTimestamp(bToken, channel, ts string) (string, error) {
 		}
 	}
 
-	logrus.Infof("using parent timestamp ts(%s)", ts)
+	logging.FromContext(ctx).Debugf("using parent timestamp ts(%s)", ts)
 
 	return ts, nil
 }
 
 // SendError responds to a user interaction with an error
-func SendError(e, channel, user string) error {
+func SendError(ctx context.Context, bToken, e, channel, user string) error {
 
-	logrus.Infof("responding with error(%s)", e)
+	logging.FromContext(ctx).Debugf("responding with error(%s)", e)
 
 	// build ephemeral response
 	text := slack.MsgOptionText(e, false)
@@ -96,12 +98,10 @@ func SendError(e, channel, user string) error {
 	}
 
 	// create an api client
-	bToken := os.Getenv("SKELLY_BOT_TOKEN")
-
-	api := slack.New(bToken)
+	api := slackapi.For(bToken)
 
 	// post message
-	_, err := api.PostEphemeral(channel, user, options...)
+	_, err := api.PostEphemeral(ctx, channel, user, options...)
 	if err != nil {
 		err = errors.Wrap(err, "could not post response")
 		return err
@@ -110,16 +110,25 @@ func SendError(e, channel, user string) error {
 }
 
 // Respond takes response text and posts it to the response url
-func Respond(responseURL string, response interface{}) error {
+func Respond(ctx context.Context, responseURL string, response interface{}) error {
 
 	// encode the response
 	buffer := new(bytes.Buffer)
 	json.NewEncoder(buffer).Encode(response)
 
+	// build the request to the response url
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, buffer)
+	if err != nil {
+		err = errors.Wrap(err, "could not create response request")
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
 	// respond to the user via the response url
-	resp, err := http.Post(responseURL, "application/json; charset=utf-8", buffer)
+	resp, err := tracing.HTTPClient().Do(req)
 	if err != nil {
 		err = errors.Wrap(err, "could not post response")
+		return err
 	}
 
 	defer resp.Body.Close()